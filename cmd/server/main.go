@@ -8,10 +8,12 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"strings"
 	"syscall"
 
 	"github.com/astral/kg-server-web-gui/internal/api"
+	"github.com/astral/kg-server-web-gui/internal/node"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/filesystem"
@@ -25,11 +27,24 @@ var frontendFS embed.FS
 
 func main() {
 	// Parse flags
-	port := flag.String("port", "3000", "Port to run the server on")
+	port := flag.String("port", "", "Port to run the server on (default 3000, 3100 in agent mode)")
 	dev := flag.Bool("dev", false, "Run in development mode (no embedded frontend)")
+	mode := flag.String("mode", "panel", "panel: web UI and API, agent: headless agent managed by a panel on another host")
+	token := flag.String("token", "", "Agent mode: token the panel authenticates with (default $KG_AGENT_TOKEN or data/agent_token)")
 	flag.Parse()
 
-	log.Printf("Arma Reforger Manager %s starting...", Version)
+	agentMode := *mode == "agent"
+	if !agentMode && *mode != "panel" {
+		log.Fatalf("Unknown mode %q (panel or agent)", *mode)
+	}
+	if *port == "" {
+		*port = "3000"
+		if agentMode {
+			*port = node.DefaultPort
+		}
+	}
+
+	log.Printf("Arma Reforger Manager %s starting (%s mode)...", Version, *mode)
 
 	// Initialize Fiber
 	app := fiber.New(fiber.Config{
@@ -48,11 +63,34 @@ func main() {
 		AllowCredentials: true,
 	}))
 
-	// API Routes
-	api.SetupRoutes(app)
+	if agentMode {
+		agentToken := *token
+		if agentToken == "" {
+			agentToken = os.Getenv("KG_AGENT_TOKEN")
+		}
+		if agentToken == "" {
+			workDir, _ := os.Getwd()
+			generated, created, err := node.LoadOrCreateToken(filepath.Join(workDir, "data"))
+			if err != nil {
+				log.Fatal("Failed to load agent token:", err)
+			}
+			agentToken = generated
+			if created {
+				log.Printf("Generated agent token (saved to data/agent_token): %s", agentToken)
+			} else {
+				log.Println("Using agent token from data/agent_token")
+			}
+		}
+		api.SetupAgentRoutes(app, Version, agentToken)
+	} else {
+		// API Routes
+		api.SetupRoutes(app)
+	}
 
 	// Static Frontend Serving
-	if !*dev {
+	if agentMode {
+		log.Println("Running in agent mode. Register this host as a node in the panel.")
+	} else if !*dev {
 		// Get the embedded filesystem with the correct subpath
 		subFS, err := fs.Sub(frontendFS, "frontend_build")
 		if err != nil {
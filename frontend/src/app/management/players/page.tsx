@@ -69,7 +69,7 @@ export default function PlayersPage() {
         const endpoint = actionType === "kick" ? "kick" : "ban"
         const payload = {
             index: targetPlayer.index,
-            identifier: targetPlayer.beguid, // For ban
+            identifier: targetPlayer.beguid || String(targetPlayer.index), // For ban
             reason: reason || (actionType === "kick" ? "Kicked by admin" : "Banned by admin")
         }
 
@@ -140,7 +140,8 @@ export default function PlayersPage() {
                                         <TableHead className="w-[50px]">#</TableHead>
                                         <TableHead>이름</TableHead>
                                         <TableHead>IP</TableHead>
-                                        <TableHead>UID / BEGUID</TableHead>
+                                        <TableHead>핑</TableHead>
+                                        <TableHead>BE GUID</TableHead>
                                         <TableHead className="text-right">작업</TableHead>
                                     </TableRow>
                                 </TableHeader>
@@ -150,9 +151,10 @@ export default function PlayersPage() {
                                             <TableCell className="font-mono text-zinc-500">{p.index}</TableCell>
                                             <TableCell className="font-medium">{p.name}</TableCell>
                                             <TableCell className="text-zinc-400 font-mono text-sm">{p.ip}</TableCell>
+                                            <TableCell className="text-zinc-400 font-mono text-sm">{p.ping >= 0 ? `${p.ping}ms` : "-"}</TableCell>
                                             <TableCell className="flex flex-col text-xs font-mono text-zinc-500">
-                                                <span>UID: {p.uid}</span>
-                                                <span>BE: {p.beguid}</span>
+                                                <span>{p.beguid || "-"}</span>
+                                                <span>{p.verified ? "인증됨" : "미인증"}{p.lobby ? " · 로비" : ""}</span>
                                             </TableCell>
                                             <TableCell className="text-right space-x-2">
                                                 <Button size="sm" variant="destructive" className="h-8 bg-red-900/30 hover:bg-red-900/50 text-red-400" onClick={() => openActionDialog(p, "kick")}>
@@ -4,6 +4,7 @@ import { useState, useEffect } from "react"
 import { Card, CardContent, CardHeader, CardTitle, CardDescription } from "@/components/ui/card"
 import { Button } from "@/components/ui/button"
 import { ScrollArea } from "@/components/ui/scroll-area"
+import { Select, SelectContent, SelectItem, SelectTrigger, SelectValue } from "@/components/ui/select"
 import { HardDrive, Archive, RotateCcw, Trash2, RefreshCw, Clock, Download, Loader2 } from "lucide-react"
 import { apiGet, apiPost, apiDelete } from "@/lib/api"
 
@@ -51,6 +52,8 @@ interface WorkshopAddon {
 }
 
 export default function SavesPage() {
+    const [servers, setServers] = useState<any[]>([])
+    const [selectedServer, setSelectedServer] = useState("default")
     const [saves, setSaves] = useState<SaveFile[]>([])
     const [backups, setBackups] = useState<SaveFile[]>([])
     const [loading, setLoading] = useState(false)
@@ -59,14 +62,27 @@ export default function SavesPage() {
 
     useEffect(() => {
         setMounted(true)
-        fetchSaves()
-        fetchBackups()
+        fetchServers()
     }, [])
 
+    useEffect(() => {
+        if (selectedServer) {
+            fetchSaves()
+            fetchBackups()
+        }
+    }, [selectedServer])
+
+    const fetchServers = async () => {
+        try {
+            const list = await apiGet<any[]>("/api/servers")
+            setServers(list || [])
+        } catch (e) { }
+    }
+
     const fetchSaves = async () => {
         setLoading(true)
         try {
-            const data = await apiGet<SaveFile[]>("/api/saves")
+            const data = await apiGet<SaveFile[]>(`/api/servers/${selectedServer}/saves`)
             setSaves(data || [])
         } catch (e) { console.error('Saves fetch error:', e) }
         setLoading(false)
@@ -74,7 +90,7 @@ export default function SavesPage() {
 
     const fetchBackups = async () => {
         try {
-            const data = await apiGet<SaveFile[]>("/api/saves/backups")
+            const data = await apiGet<SaveFile[]>(`/api/servers/${selectedServer}/saves/backups`)
             setBackups(data || [])
         } catch (e) { console.error('Backups fetch error:', e) }
     }
@@ -91,7 +107,7 @@ export default function SavesPage() {
     const createBackup = async (saveName: string) => {
         setProcessing(saveName)
         try {
-            await apiPost("/api/saves/backup", { saveName })
+            await apiPost(`/api/servers/${selectedServer}/saves/backup`, { saveName })
             fetchBackups()
         } catch (e) { console.error('Backup create error:', e) }
         setProcessing(null)
@@ -101,7 +117,7 @@ export default function SavesPage() {
         if (!confirm("백업을 복원하시겠습니까? 현재 세이브 파일이 덮어씁워질 수 있습니다.")) return
         setProcessing(backupName)
         try {
-            await apiPost("/api/saves/restore", { backupName })
+            await apiPost(`/api/servers/${selectedServer}/saves/restore`, { backupName })
             fetchSaves()
         } catch (e) { console.error('Backup restore error:', e) }
         setProcessing(null)
@@ -110,7 +126,7 @@ export default function SavesPage() {
     const deleteSave = async (name: string, isBackup: boolean) => {
         if (!confirm(`정말 ${name} 파일을 삭제하시겠습니까?`)) return
         try {
-            await apiDelete(`/api/saves/${name}`)
+            await apiDelete(`/api/servers/${selectedServer}/saves/${encodeURIComponent(name)}${isBackup ? "?backup=true" : ""}`)
             if (isBackup) fetchBackups()
             else fetchSaves()
         } catch (e) { console.error('Delete error:', e) }
@@ -167,9 +183,21 @@ export default function SavesPage() {
                         </h1>
                         <p className="text-zinc-400 mt-1">서버 저장 데이터 및 백업 관리</p>
                     </div>
-                    <Button variant="outline" onClick={() => { fetchSaves(); fetchBackups(); }} className="gap-2">
-                        <RefreshCw className={`w-4 h-4 ${loading ? "animate-spin" : ""}`} /> 새로고침
-                    </Button>
+                    <div className="flex items-center gap-4">
+                        <Select value={selectedServer} onValueChange={setSelectedServer}>
+                            <SelectTrigger className="w-[200px] bg-zinc-800 border-zinc-700">
+                                <SelectValue placeholder="서버 선택" />
+                            </SelectTrigger>
+                            <SelectContent className="bg-zinc-800 border-zinc-700 text-white">
+                                {servers.map(s => (
+                                    <SelectItem key={s.id} value={s.id}>{s.name} ({s.id})</SelectItem>
+                                ))}
+                            </SelectContent>
+                        </Select>
+                        <Button variant="outline" onClick={() => { fetchSaves(); fetchBackups(); }} className="gap-2">
+                            <RefreshCw className={`w-4 h-4 ${loading ? "animate-spin" : ""}`} /> 새로고침
+                        </Button>
+                    </div>
                 </div>
 
                 <div className="grid gap-6 md:grid-cols-2">
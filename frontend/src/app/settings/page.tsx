@@ -6,7 +6,7 @@ import { Button } from "@/components/ui/button"
 import { Input } from "@/components/ui/input"
 import { Label } from "@/components/ui/label"
 import { Tabs, TabsContent, TabsList, TabsTrigger } from "@/components/ui/tabs"
-import { Cog, FolderOpen, Save, Server, Package, User, Loader2, CheckCircle, Download, Upload } from "lucide-react"
+import { Cog, FolderOpen, Save, Server, Package, Loader2, CheckCircle, Download, Upload } from "lucide-react"
 import { toast } from "sonner"
 
 import { apiGet, apiPost, apiFetch } from "@/lib/api"
@@ -14,7 +14,6 @@ import { apiGet, apiPost, apiFetch } from "@/lib/api"
 interface AppSettings {
     serverPath: string
     addonsPath: string
-    profilesPath: string
     steamcmdPath: string
     defaultServerName: string
     discordWebhookUrl: string
@@ -29,7 +28,6 @@ export default function SettingsPage() {
     const [settings, setSettings] = useState<AppSettings>({
         serverPath: "",
         addonsPath: "",
-        profilesPath: "",
         steamcmdPath: "",
         defaultServerName: "default",
         discordWebhookUrl: "",
@@ -173,7 +171,6 @@ export default function SettingsPage() {
                                                     ...prev,
                                                     serverPath: ".\\server",
                                                     addonsPath: ".\\addons",
-                                                    profilesPath: ".\\profiles",
                                                     steamcmdPath: ".\\steamcmd"
                                                 }))
                                             } else if (val === "default") {
@@ -181,7 +178,6 @@ export default function SettingsPage() {
                                                     ...prev,
                                                     serverPath: "C:\\Program Files (x86)\\Steam\\steamapps\\common\\Arma Reforger Server",
                                                     addonsPath: "C:\\Users\\Administrator\\Documents\\My Games\\ArmaReforger\\addons",
-                                                    profilesPath: "C:\\Users\\Administrator\\Documents\\My Games\\ArmaReforger\\profile",
                                                     steamcmdPath: "C:\\SteamCMD"
                                                 }))
                                             }
@@ -220,19 +216,6 @@ export default function SettingsPage() {
                                     <p className="text-xs text-zinc-500">모드가 저장되는 폴더</p>
                                 </div>
 
-                                <div className="space-y-2">
-                                    <Label className="flex items-center gap-2">
-                                        <User className="w-4 h-4 text-cyan-400" /> 프로필 경로
-                                    </Label>
-                                    <Input
-                                        placeholder="예: C:\ArmaReforger\Profiles"
-                                        className="bg-zinc-900 border-zinc-700"
-                                        value={settings.profilesPath}
-                                        onChange={e => updateSetting("profilesPath", e.target.value)}
-                                    />
-                                    <p className="text-xs text-zinc-500">서버 프로필이 저장되는 폴더</p>
-                                </div>
-
                                 <div className="space-y-2">
                                     <Label className="flex items-center gap-2">
                                         <Cog className="w-4 h-4 text-purple-400" /> SteamCMD 경로
@@ -0,0 +1,168 @@
+package a2s
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// Steam server query (A2S) packet headers
+const (
+	headerSimple   = 0xFFFFFFFF
+	headerSplit    = 0xFFFFFFFE
+	typeInfo       = 'T'
+	typeInfoReply  = 'I'
+	typeChallenge  = 'A'
+	infoPayload    = "Source Engine Query\x00"
+	maxPacketSize  = 1400
+	defaultTimeout = 3 * time.Second
+)
+
+// Info is the A2S_INFO reply of a server
+type Info struct {
+	Protocol   byte   `json:"protocol"`
+	Name       string `json:"name"`
+	Map        string `json:"map"`
+	Folder     string `json:"folder"`
+	Game       string `json:"game"`
+	AppID      uint16 `json:"appId"`
+	Players    int    `json:"players"`
+	MaxPlayers int    `json:"maxPlayers"`
+	Bots       int    `json:"bots"`
+	Version    string `json:"version"`
+}
+
+// QueryInfo sends A2S_INFO to addr ("host:port") and parses the reply
+func QueryInfo(addr string, timeout time.Duration) (*Info, error) {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	conn, err := net.DialTimeout("udp", addr, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	request := infoRequest(nil)
+	for attempt := 0; attempt < 3; attempt++ {
+		if _, err := conn.Write(request); err != nil {
+			return nil, err
+		}
+
+		buf := make([]byte, maxPacketSize)
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+		packet := buf[:n]
+
+		if len(packet) < 5 {
+			return nil, errors.New("a2s: short packet")
+		}
+		if binary.LittleEndian.Uint32(packet) == headerSplit {
+			return nil, errors.New("a2s: split replies are not supported")
+		}
+		if binary.LittleEndian.Uint32(packet) != headerSimple {
+			return nil, errors.New("a2s: invalid packet header")
+		}
+
+		switch packet[4] {
+		case typeChallenge:
+			// Newer servers require the challenge to be echoed back
+			if len(packet) < 9 {
+				return nil, errors.New("a2s: short challenge")
+			}
+			request = infoRequest(packet[5:9])
+		case typeInfoReply:
+			return parseInfo(packet[5:])
+		default:
+			return nil, fmt.Errorf("a2s: unexpected reply type 0x%02x", packet[4])
+		}
+	}
+
+	return nil, errors.New("a2s: no info reply after challenge")
+}
+
+func infoRequest(challenge []byte) []byte {
+	buf := make([]byte, 0, 29)
+	buf = binary.LittleEndian.AppendUint32(buf, headerSimple)
+	buf = append(buf, typeInfo)
+	buf = append(buf, infoPayload...)
+	return append(buf, challenge...)
+}
+
+func parseInfo(data []byte) (*Info, error) {
+	r := &reader{data: data}
+	info := &Info{}
+	info.Protocol = r.byte()
+	info.Name = r.string()
+	info.Map = r.string()
+	info.Folder = r.string()
+	info.Game = r.string()
+	info.AppID = r.uint16()
+	info.Players = int(r.byte())
+	info.MaxPlayers = int(r.byte())
+	info.Bots = int(r.byte())
+	r.byte() // Server type
+	r.byte() // Environment
+	r.byte() // Visibility
+	r.byte() // VAC
+	info.Version = r.string()
+
+	if r.err != nil {
+		return nil, r.err
+	}
+	return info, nil
+}
+
+// reader walks an A2S payload, remembering the first out-of-bounds read
+type reader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (r *reader) byte() byte {
+	if r.err != nil || r.pos >= len(r.data) {
+		r.fail()
+		return 0
+	}
+	b := r.data[r.pos]
+	r.pos++
+	return b
+}
+
+func (r *reader) uint16() uint16 {
+	if r.err != nil || r.pos+2 > len(r.data) {
+		r.fail()
+		return 0
+	}
+	v := binary.LittleEndian.Uint16(r.data[r.pos:])
+	r.pos += 2
+	return v
+}
+
+func (r *reader) string() string {
+	if r.err != nil {
+		return ""
+	}
+	end := bytes.IndexByte(r.data[r.pos:], 0)
+	if end < 0 {
+		r.fail()
+		return ""
+	}
+	s := string(r.data[r.pos : r.pos+end])
+	r.pos += end + 1
+	return s
+}
+
+func (r *reader) fail() {
+	if r.err == nil {
+		r.err = errors.New("a2s: truncated info reply")
+	}
+}
@@ -0,0 +1,186 @@
+package agent
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/astral/kg-server-web-gui/internal/logs"
+)
+
+// LogRotation limits the size and age of an instance's console log files
+type LogRotation struct {
+	MaxSizeMB  int `json:"maxSizeMb"`  // Rotate console.log when it grows past this size
+	MaxAgeDays int `json:"maxAgeDays"` // Delete rotated files older than this
+	MaxFiles   int `json:"maxFiles"`   // Keep at most this many rotated files
+}
+
+// DefaultLogRotation returns the rotation limits used when none are configured
+func DefaultLogRotation() LogRotation {
+	return LogRotation{
+		MaxSizeMB:  50,
+		MaxAgeDays: 14,
+		MaxFiles:   20,
+	}
+}
+
+// LogFile describes a console log file of an instance
+type LogFile struct {
+	Name     string    `json:"name"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+	Current  bool      `json:"current"` // The file the running server writes to
+}
+
+// SetLogRotation updates the rotation limits of the console log
+func (p *ProcessMonitor) SetLogRotation(r LogRotation) {
+	def := DefaultLogRotation()
+	if r.MaxSizeMB <= 0 {
+		r.MaxSizeMB = def.MaxSizeMB
+	}
+	if r.MaxAgeDays <= 0 {
+		r.MaxAgeDays = def.MaxAgeDays
+	}
+	if r.MaxFiles <= 0 {
+		r.MaxFiles = def.MaxFiles
+	}
+
+	p.stateLock.Lock()
+	p.rotation = r
+	p.stateLock.Unlock()
+}
+
+// rotateLogs rotates the console log when it is too large and prunes old rotated files.
+// While the server is running the file is copied and truncated in place, because the server
+// keeps its own handle to console.log (a few lines written during the copy may be lost).
+func (p *ProcessMonitor) rotateLogs(force bool) {
+	p.stateLock.RLock()
+	r := p.rotation
+	p.stateLock.RUnlock()
+
+	current := p.ConsoleLogPath()
+	size := fileSize(current)
+	if size > 0 && (force || size > int64(r.MaxSizeMB)*1024*1024) {
+		rotated := p.rotatedLogPath(time.Now())
+		if err := copyFile(current, rotated); err != nil {
+			logs.GlobalLogs.Warn(fmt.Sprintf("[%s] 콘솔 로그 회전 실패: %v", p.Label, err))
+		} else if err := os.Truncate(current, 0); err != nil {
+			logs.GlobalLogs.Warn(fmt.Sprintf("[%s] 콘솔 로그 비우기 실패: %v", p.Label, err))
+		}
+	}
+
+	p.pruneLogs(r)
+}
+
+// rotatedLogPath returns a free name for a rotated log; rotations within the same second get a -N suffix
+func (p *ProcessMonitor) rotatedLogPath(now time.Time) string {
+	stamp := now.Format("20060102-150405")
+	path := filepath.Join(p.LogDir(), fmt.Sprintf("console-%s.log", stamp))
+	for i := 2; ; i++ {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return path
+		}
+		path = filepath.Join(p.LogDir(), fmt.Sprintf("console-%s-%d.log", stamp, i))
+	}
+}
+
+// pruneLogs removes rotated files beyond the age and count limits
+func (p *ProcessMonitor) pruneLogs(r LogRotation) {
+	files, err := p.ListLogFiles()
+	if err != nil {
+		return
+	}
+
+	cutoff := time.Now().AddDate(0, 0, -r.MaxAgeDays)
+	kept := 0
+	for _, f := range files { // Newest first
+		if f.Current {
+			continue
+		}
+		if f.Modified.Before(cutoff) || kept >= r.MaxFiles {
+			os.Remove(filepath.Join(p.LogDir(), f.Name))
+			continue
+		}
+		kept++
+	}
+}
+
+// ListLogFiles returns the instance's console log files, newest first
+func (p *ProcessMonitor) ListLogFiles() ([]LogFile, error) {
+	entries, err := os.ReadDir(p.LogDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []LogFile{}, nil
+		}
+		return nil, err
+	}
+
+	files := make([]LogFile, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".log") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, LogFile{
+			Name:     entry.Name(),
+			Size:     info.Size(),
+			Modified: info.ModTime(),
+			Current:  entry.Name() == ConsoleLogName,
+		})
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Modified.After(files[j].Modified)
+	})
+	return files, nil
+}
+
+// LogFilePath resolves a console log file name inside the log directory
+func (p *ProcessMonitor) LogFilePath(name string) (string, error) {
+	if name == "" {
+		name = ConsoleLogName
+	}
+	// Reject anything that is not a plain file name (path traversal)
+	if name != filepath.Base(name) || !strings.HasSuffix(name, ".log") {
+		return "", fmt.Errorf("invalid log file name: %s", name)
+	}
+	path := filepath.Join(p.LogDir(), name)
+	if _, err := os.Stat(path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// TailLogFile returns the last n lines of a console log file
+func (p *ProcessMonitor) TailLogFile(name string, n int) ([]string, error) {
+	path, err := p.LogFilePath(name)
+	if err != nil {
+		return nil, err
+	}
+	return tailLines(path, n)
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
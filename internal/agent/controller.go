@@ -0,0 +1,48 @@
+package agent
+
+import (
+	"io"
+	"os"
+	"time"
+)
+
+// ProcessController controls the server process of one instance, either on this host
+// (ProcessMonitor) or on a remote agent node
+type ProcessController interface {
+	Start(path string, args []string) error
+	IsRunning() (bool, int, error)
+	Terminate() error
+	Kill() error
+	WaitExit(timeout time.Duration) bool
+	Adopt() (*ProcessRecord, error)
+	GetRecord() *ProcessRecord
+	LastExit() *ExitInfo
+	GetResourceHistory() []ResourceData
+	SetExitHandler(fn func(rec *ProcessRecord, err error))
+	SubscribeLines(fn func(line string)) func()
+	SetLogRotation(r LogRotation)
+	ListLogFiles() ([]LogFile, error)
+	TailLogFile(name string, n int) ([]string, error)
+	OpenLogFile(name string) (io.ReadCloser, error)
+	LastOutput() (time.Time, error)
+}
+
+var _ ProcessController = (*ProcessMonitor)(nil)
+
+// OpenLogFile opens a console log file for reading (current file by default)
+func (p *ProcessMonitor) OpenLogFile(name string) (io.ReadCloser, error) {
+	path, err := p.LogFilePath(name)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(path)
+}
+
+// LastOutput returns when the server last wrote to its console log
+func (p *ProcessMonitor) LastOutput() (time.Time, error) {
+	info, err := os.Stat(p.ConsoleLogPath())
+	if err != nil {
+		return time.Time{}, err
+	}
+	return info.ModTime(), nil
+}
@@ -0,0 +1,127 @@
+package agent
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/astral/kg-server-web-gui/internal/logs"
+)
+
+// LivenessCheck is a health probe run periodically against a running instance.
+// A process that exists but fails its checks is considered hung.
+type LivenessCheck struct {
+	Name      string
+	Threshold int          // Consecutive failures before the instance counts as hung
+	Check     func() error // Returns nil when the server responds normally
+
+	failures int // Only touched by the watchdog loop
+}
+
+// SetLivenessChecks installs the liveness checks of an instance, run every interval while it is active
+func (w *Watchdog) SetLivenessChecks(id string, interval time.Duration, checks []*LivenessCheck) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	inst, ok := w.instances[id]
+	if !ok {
+		return
+	}
+	for _, c := range checks {
+		if c.Threshold <= 0 {
+			c.Threshold = 1
+		}
+	}
+	inst.Liveness = checks
+	inst.LivenessInterval = interval
+	inst.lastLiveness = time.Now() // First round after one interval
+}
+
+// ClearLivenessChecks removes the liveness checks of an instance (e.g. when it stops being ready)
+func (w *Watchdog) ClearLivenessChecks(id string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if inst, ok := w.instances[id]; ok {
+		inst.Liveness = nil
+	}
+}
+
+// SetHangHandler registers a callback asked about a hung instance before it is killed;
+// an error from it (e.g. maintenance) leaves the instance alone
+func (w *Watchdog) SetHangHandler(fn func(id, reason string) error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.onHang = fn
+}
+
+// dueLiveness reports whether an instance's liveness checks should run now (caller holds the lock)
+func (w *Watchdog) dueLiveness(inst *WatchedInstance, now time.Time) bool {
+	if !inst.Active || len(inst.Liveness) == 0 || now.Sub(inst.lastLiveness) < inst.LivenessInterval {
+		return false
+	}
+	running, _, _ := inst.Process.IsRunning()
+	if !running {
+		return false
+	}
+	inst.lastLiveness = now
+	return true
+}
+
+// runLiveness runs the checks of an instance outside the lock and returns the reason it is hung, if it is
+func (w *Watchdog) runLiveness(id string, checks []*LivenessCheck) string {
+	for _, c := range checks {
+		err := c.Check()
+		if err == nil {
+			c.failures = 0
+			continue
+		}
+
+		c.failures++
+		logs.GlobalLogs.Warn(fmt.Sprintf("Watchdog liveness check %s failed for %s (%d/%d): %v", c.Name, id, c.failures, c.Threshold, err))
+		if c.failures >= c.Threshold {
+			return fmt.Sprintf("%s: %v (%d consecutive failures)", c.Name, err, c.failures)
+		}
+	}
+	return ""
+}
+
+// handleHang records a hung instance as a crash, kills it and restarts it through the restart policy,
+// unless the hang handler holds it back
+func (w *Watchdog) handleHang(inst *WatchedInstance, reason string) {
+	logs.GlobalLogs.Error(fmt.Sprintf("Watchdog detected hung server %s: %s", inst.ID, reason))
+
+	w.mu.Lock()
+	handler := w.onHang
+	w.mu.Unlock()
+	if handler != nil {
+		if err := handler(inst.ID, reason); err != nil {
+			logs.GlobalLogs.Warn(fmt.Sprintf("Watchdog left hung server %s running: %v", inst.ID, err))
+			w.mu.Lock()
+			for _, c := range inst.Liveness {
+				c.failures = 0
+			}
+			w.mu.Unlock()
+			return
+		}
+	}
+
+	excerpt := crashExcerpt(inst.Process) // May ask a node, so read before taking the lock
+	w.mu.Lock()
+	inst.Liveness = nil
+	w.recordCrashLocked(inst, "hung: "+reason, nil)
+	action := actNone
+	if inst.Active && w.enabled {
+		action = w.scheduleRestart(inst, "🧊 Server Hang Detected", "stopped responding ("+reason+")", excerpt, time.Now())
+	}
+	w.mu.Unlock()
+
+	err := inst.Process.Kill()
+	w.captureCrashes()
+	if err != nil {
+		logs.GlobalLogs.Error(fmt.Sprintf("Watchdog failed to kill hung server %s: %v", inst.ID, err))
+		return
+	}
+	// Otherwise the next check restarts it once the backoff delay has passed
+	if action != actNone {
+		w.restartInstance(inst, action)
+	}
+}
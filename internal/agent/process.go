@@ -1,9 +1,10 @@
 package agent
 
 import (
-	"bufio"
-	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
@@ -21,30 +22,99 @@ type ResourceData struct {
 	MemoryMB float64   `json:"memoryMb"`
 }
 
-// ProcessMonitor handles server process control
+// PIDFileName is the name of the process record kept in each instance's data directory
+const PIDFileName = "server.pid.json"
+
+// ConsoleLogName is the file receiving the server's stdout/stderr inside the instance's logs directory
+const ConsoleLogName = "console.log"
+
+// startTimeTolerance absorbs boot-time jitter when comparing process creation times
+// (on Linux the creation time is derived from btime, which can shift after clock adjustments)
+const startTimeTolerance = 2000 // ms
+
+// A creation time lookup is tried startTimeAttempts times before it counts as failed
+const (
+	startTimeAttempts   = 3
+	startTimeRetryDelay = 200 * time.Millisecond
+)
+
+// ProcessRecord identifies a launched server process. It is persisted as the PID file.
+type ProcessRecord struct {
+	PID       int      `json:"pid"`
+	StartTime int64    `json:"startTime"` // Process creation time (ms since epoch)
+	Exe       string   `json:"exe"`
+	Args      []string `json:"args"`
+}
+
+// ExitInfo describes how the last tracked process ended
+type ExitInfo struct {
+	Record   *ProcessRecord `json:"record"`
+	Time     time.Time      `json:"time"`
+	ExitCode *int           `json:"exitCode,omitempty"` // Unknown for adopted processes
+	Error    string         `json:"error,omitempty"`
+}
+
+// newExitInfo records the end of a process; waited is false when it vanished without a Wait() owner
+func newExitInfo(rec *ProcessRecord, err error, waited bool) *ExitInfo {
+	info := &ExitInfo{Record: rec, Time: time.Now()}
+	var exitErr *exec.ExitError
+	switch {
+	case !waited:
+	case err == nil:
+		code := 0
+		info.ExitCode = &code
+	case errors.As(err, &exitErr):
+		code := exitErr.ExitCode() // -1 when killed by a signal
+		info.ExitCode = &code
+		info.Error = err.Error()
+	default:
+		info.Error = err.Error()
+	}
+	return info
+}
+
+// ProcessMonitor handles server process control.
+// Each monitor owns the process it launched and identifies it by PID plus creation time,
+// so several instances of the same executable can run side by side.
 type ProcessMonitor struct {
 	Executable string
 	ServerPath string // Full path to server executable
+	Label      string // Prefix for console lines mirrored into the panel log (instance ID)
+	dataDir    string // Instance data directory (PID file, console logs)
 
 	// Monitoring
 	history     []ResourceData
 	historyLock sync.RWMutex
 	stopMonitor chan struct{}
-
-	// Process Cache
+	rotation    LogRotation
+
+	// Tracked process
+	cmd       *exec.Cmd
+	record    *ProcessRecord
+	exited    chan struct{} // Closed when the tracked process exits
+	tail      *fileTail     // Follows the console log while the process is tracked
+	lastExit  *ExitInfo
 	isRunning bool
 	cachedPID int
-	lastCheck time.Time
 	stateLock sync.RWMutex
+
+	// Hooks
+	hookLock  sync.Mutex
+	onExit    func(rec *ProcessRecord, err error)
+	lineSubs  map[int]func(line string)
+	nextSubID int
 }
 
-func NewProcessMonitor(exeName string) *ProcessMonitor {
+func NewProcessMonitor(exeName, dataDir string) *ProcessMonitor {
 	if exeName == "" {
-		exeName = "ArmaReforgerServer.exe"
+		exeName = ServerBinaryName
 	}
 	pm := &ProcessMonitor{
 		Executable: exeName,
+		Label:      exeName,
+		dataDir:    dataDir,
 		history:    make([]ResourceData, 0),
+		rotation:   DefaultLogRotation(),
 	}
 	// Note: We don't auto-start monitoring here in constructor if we want explicit control?
 	// But exiting code did. Let's keep it.
@@ -62,6 +132,8 @@ func (p *ProcessMonitor) StartMonitoring() {
 	go func() {
 		ticker := time.NewTicker(2 * time.Second)
 		defer ticker.Stop()
+		rotateTicker := time.NewTicker(time.Minute)
+		defer rotateTicker.Stop()
 
 		for {
 			select {
@@ -70,17 +142,80 @@ func (p *ProcessMonitor) StartMonitoring() {
 			case <-ticker.C:
 				p.updateState() // Periodic actual check
 				p.collectMetrics()
+			case <-rotateTicker.C:
+				p.rotateLogs(false)
 			}
 		}
 	}()
 }
 
 func (p *ProcessMonitor) updateState() {
-	running, pid, _ := p.checkProcessReal()
+	running, _, err := p.checkProcessReal()
+	if err != nil || running {
+		return
+	}
+
 	p.stateLock.Lock()
-	p.isRunning = running
-	p.cachedPID = pid
+	rec := p.record
+	vanished := rec != nil && p.cmd == nil
+	if vanished {
+		// Process vanished without a Wait() owner to report it
+		p.lastExit = newExitInfo(rec, nil, false)
+		p.clearTrackingLocked()
+	}
+	p.isRunning = false
 	p.stateLock.Unlock()
+
+	if vanished {
+		p.notifyExit(rec, nil)
+	}
+}
+
+// SetExitHandler registers a callback invoked whenever the tracked process exits
+func (p *ProcessMonitor) SetExitHandler(fn func(rec *ProcessRecord, err error)) {
+	p.hookLock.Lock()
+	p.onExit = fn
+	p.hookLock.Unlock()
+}
+
+// SubscribeLines registers a callback receiving every console line; call the returned func to unsubscribe
+func (p *ProcessMonitor) SubscribeLines(fn func(line string)) func() {
+	p.hookLock.Lock()
+	defer p.hookLock.Unlock()
+	if p.lineSubs == nil {
+		p.lineSubs = make(map[int]func(string))
+	}
+	id := p.nextSubID
+	p.nextSubID++
+	p.lineSubs[id] = fn
+
+	return func() {
+		p.hookLock.Lock()
+		delete(p.lineSubs, id)
+		p.hookLock.Unlock()
+	}
+}
+
+func (p *ProcessMonitor) notifyExit(rec *ProcessRecord, err error) {
+	p.hookLock.Lock()
+	fn := p.onExit
+	p.hookLock.Unlock()
+	if fn != nil {
+		fn(rec, err)
+	}
+}
+
+func (p *ProcessMonitor) publishLine(line string) {
+	p.hookLock.Lock()
+	subs := make([]func(string), 0, len(p.lineSubs))
+	for _, fn := range p.lineSubs {
+		subs = append(subs, fn)
+	}
+	p.hookLock.Unlock()
+
+	for _, fn := range subs {
+		fn(line)
+	}
 }
 
 func (p *ProcessMonitor) collectMetrics() {
@@ -155,33 +290,272 @@ func (p *ProcessMonitor) IsRunning() (bool, int, error) {
 	return p.isRunning, p.cachedPID, nil
 }
 
-// checkProcessReal performs the actual system check
+// checkProcessReal verifies that the tracked process still exists and is the same process
+// (PID plus creation time, so a recycled PID is not mistaken for our server)
 func (p *ProcessMonitor) checkProcessReal() (bool, int, error) {
-	// Safe read of params
 	p.stateLock.RLock()
-	exe := p.Executable
+	rec := p.record
 	p.stateLock.RUnlock()
 
-	cmd := exec.Command("tasklist", "/FI", fmt.Sprintf("IMAGENAME eq %s", exe), "/FO", "CSV", "/NH")
-	var out bytes.Buffer
-	cmd.Stdout = &out
-	if err := cmd.Run(); err != nil {
+	if rec == nil {
+		return false, 0, nil
+	}
+
+	alive, err := processMatches(rec.PID, rec.StartTime)
+	if err != nil || !alive {
 		return false, 0, err
 	}
+	if rec.StartTime == 0 {
+		p.completeRecord(rec)
+	}
+	return true, rec.PID, nil
+}
 
-	output := out.String()
-	if strings.Contains(output, "No tasks are running") || strings.Contains(output, "정보:") {
-		return false, 0, nil
+// completeRecord fills in the creation time Start could not read, so a later reattach can verify the PID
+func (p *ProcessMonitor) completeRecord(rec *ProcessRecord) {
+	created, err := processStartTime(rec.PID)
+	if err != nil {
+		return
 	}
+	p.stateLock.Lock()
+	if p.record != rec {
+		p.stateLock.Unlock()
+		return
+	}
+	rec.StartTime = created
+	p.stateLock.Unlock()
+	p.writePIDFile(rec)
+}
 
-	parts := strings.Split(output, ",")
-	if len(parts) >= 2 {
-		var pid int
-		fmt.Sscanf(strings.Trim(parts[1], "\""), "%d", &pid)
-		return true, pid, nil
+// processMatches reports whether pid exists and was created at startTime (0 = unknown, the PID alone counts).
+// A failed lookup is an error: the process may well be alive, so it must not be reported as exited.
+func processMatches(pid int, startTime int64) (bool, error) {
+	exists, err := process.PidExists(int32(pid))
+	if err != nil {
+		return false, fmt.Errorf("프로세스 확인 실패 (PID %d): %w", pid, err)
+	}
+	if !exists {
+		return false, nil
+	}
+	if startTime == 0 {
+		return true, nil
+	}
+	created, err := processStartTime(pid)
+	if err != nil {
+		return false, err
 	}
+	diff := created - startTime
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff <= startTimeTolerance, nil
+}
 
-	return false, 0, nil
+// processStartTime returns the creation time of pid in ms since epoch, retrying transient lookup failures
+// (e.g. access denied on Windows)
+func processStartTime(pid int) (int64, error) {
+	proc, err := process.NewProcess(int32(pid))
+	if err != nil {
+		return 0, err
+	}
+	var created int64
+	for i := 0; i < startTimeAttempts; i++ {
+		if i > 0 {
+			time.Sleep(startTimeRetryDelay)
+		}
+		if created, err = proc.CreateTime(); err == nil {
+			return created, nil
+		}
+	}
+	return 0, fmt.Errorf("프로세스 시작 시각 확인 실패 (PID %d): %w", pid, err)
+}
+
+// LastExit returns how the last tracked process ended (nil if none has exited yet)
+func (p *ProcessMonitor) LastExit() *ExitInfo {
+	p.stateLock.RLock()
+	defer p.stateLock.RUnlock()
+	return p.lastExit
+}
+
+// GetRecord returns the record of the tracked process, or nil if nothing is tracked
+func (p *ProcessMonitor) GetRecord() *ProcessRecord {
+	p.stateLock.RLock()
+	defer p.stateLock.RUnlock()
+	if p.record == nil {
+		return nil
+	}
+	rec := *p.record
+	return &rec
+}
+
+// clearTrackingLocked forgets the tracked process and removes the PID file (caller must hold stateLock)
+func (p *ProcessMonitor) clearTrackingLocked() {
+	if p.tail != nil {
+		// Give the tail a moment to pick up the last lines written before exit
+		time.AfterFunc(time.Second, p.tail.Stop)
+		p.tail = nil
+	}
+	p.cmd = nil
+	p.record = nil
+	p.isRunning = false
+	p.cachedPID = 0
+	p.removePIDFile()
+}
+
+func (p *ProcessMonitor) pidFilePath() string {
+	if p.dataDir == "" {
+		return ""
+	}
+	return filepath.Join(p.dataDir, PIDFileName)
+}
+
+// LogDir returns the directory holding the instance's console logs
+func (p *ProcessMonitor) LogDir() string {
+	return filepath.Join(p.dataDir, "logs")
+}
+
+// ConsoleLogPath returns the file the server's stdout/stderr is written to
+func (p *ProcessMonitor) ConsoleLogPath() string {
+	return filepath.Join(p.LogDir(), ConsoleLogName)
+}
+
+// openConsoleLog opens the console log for appending.
+// The server writes to it directly, so its output does not depend on the panel staying alive.
+func (p *ProcessMonitor) openConsoleLog() (*os.File, error) {
+	if err := os.MkdirAll(p.LogDir(), 0755); err != nil {
+		return nil, err
+	}
+	return os.OpenFile(p.ConsoleLogPath(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+}
+
+// startTailLocked mirrors console lines written after offset into the panel log (caller must hold stateLock)
+func (p *ProcessMonitor) startTailLocked(offset int64) {
+	if p.tail != nil {
+		p.tail.Stop()
+	}
+	label := p.Label
+	p.tail = followFile(p.ConsoleLogPath(), offset, func(line string) {
+		logs.GlobalLogs.Info(fmt.Sprintf("[%s] %s", label, line))
+		p.publishLine(line)
+	})
+}
+
+// readPIDFile loads the persisted process record, if any
+func (p *ProcessMonitor) readPIDFile() (*ProcessRecord, error) {
+	path := p.pidFilePath()
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var rec ProcessRecord
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+// Adopt reattaches to a server that was launched before the panel restarted.
+// The persisted PID record must still match a live process with the same creation time,
+// executable and arguments; otherwise the stale PID file is discarded.
+func (p *ProcessMonitor) Adopt() (*ProcessRecord, error) {
+	rec, err := p.readPIDFile()
+	if err != nil || rec == nil {
+		return nil, err
+	}
+
+	// A failed lookup keeps the record: the server may still be running and must not be forgotten
+	if _, err := processMatches(rec.PID, rec.StartTime); err != nil {
+		return nil, err
+	}
+	if ok, reason := verifyProcess(rec); !ok {
+		logs.GlobalLogs.Info(fmt.Sprintf("[%s] 이전 프로세스 기록 무시 (PID %d): %s", p.Executable, rec.PID, reason))
+		p.removePIDFile()
+		return nil, nil
+	}
+
+	p.stateLock.Lock()
+	p.cmd = nil // Not our child: exit is detected by polling
+	p.record = rec
+	p.exited = nil
+	p.isRunning = true
+	p.cachedPID = rec.PID
+	p.startTailLocked(fileSize(p.ConsoleLogPath()))
+	p.stateLock.Unlock()
+
+	return rec, nil
+}
+
+// verifyProcess checks that rec still describes a live server process
+func verifyProcess(rec *ProcessRecord) (bool, string) {
+	if alive, err := processMatches(rec.PID, rec.StartTime); err != nil {
+		return false, err.Error()
+	} else if !alive {
+		return false, "process is gone or PID was reused"
+	}
+
+	proc, err := process.NewProcess(int32(rec.PID))
+	if err != nil {
+		return false, err.Error()
+	}
+
+	if exe, err := proc.Exe(); err == nil {
+		// Linux appends " (deleted)" when the binary was replaced by an update
+		exe = strings.TrimSuffix(exe, " (deleted)")
+		if !samePath(exe, rec.Exe) {
+			return false, fmt.Sprintf("executable mismatch: %s", exe)
+		}
+	}
+
+	// Argument splitting differs per platform, so check containment on the raw command line
+	cmdline, err := proc.Cmdline()
+	if err != nil {
+		return false, err.Error()
+	}
+	for _, arg := range rec.Args {
+		if !strings.Contains(cmdline, arg) {
+			return false, fmt.Sprintf("argument mismatch: %s", arg)
+		}
+	}
+
+	return true, ""
+}
+
+// samePath compares two executable paths after resolving symlinks
+func samePath(a, b string) bool {
+	if resolved, err := filepath.EvalSymlinks(a); err == nil {
+		a = resolved
+	}
+	if resolved, err := filepath.EvalSymlinks(b); err == nil {
+		b = resolved
+	}
+	return strings.EqualFold(filepath.Clean(a), filepath.Clean(b))
+}
+
+func (p *ProcessMonitor) writePIDFile(rec *ProcessRecord) {
+	path := p.pidFilePath()
+	if path == "" {
+		return
+	}
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return
+	}
+	os.MkdirAll(p.dataDir, 0755)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		logs.GlobalLogs.Warn(fmt.Sprintf("[%s] PID 파일 저장 실패: %v", p.Executable, err))
+	}
+}
+
+func (p *ProcessMonitor) removePIDFile() {
+	if path := p.pidFilePath(); path != "" {
+		os.Remove(path)
+	}
 }
 
 // Stop gracefully stops the server
@@ -194,23 +568,68 @@ func (p *ProcessMonitor) Stop() error {
 		return nil
 	}
 
-	err = exec.Command("taskkill", "/PID", fmt.Sprintf("%d", pid), "/T").Run()
-	if err != nil {
+	if err := terminateProcess(pid); err != nil {
 		return p.ForceKill(pid)
 	}
 
-	for i := 0; i < 10; i++ {
-		if r, _, _ := p.IsRunning(); !r {
-			return nil
-		}
-		time.Sleep(500 * time.Millisecond)
+	if p.WaitExit(5 * time.Second) {
+		return nil
 	}
 
 	return p.ForceKill(pid)
 }
 
+// Terminate asks the server to exit (SIGTERM / taskkill without /F) without waiting for it
+func (p *ProcessMonitor) Terminate() error {
+	running, pid, err := p.IsRunning()
+	if err != nil || !running {
+		return err
+	}
+	return terminateProcess(pid)
+}
+
+// Kill force kills the tracked server and its child processes
+func (p *ProcessMonitor) Kill() error {
+	running, pid, err := p.IsRunning()
+	if err != nil || !running {
+		return err
+	}
+	if err := p.ForceKill(pid); err != nil {
+		return err
+	}
+	p.WaitExit(5 * time.Second)
+	return nil
+}
+
+// WaitExit waits until the tracked process is gone or the timeout elapses
+func (p *ProcessMonitor) WaitExit(timeout time.Duration) bool {
+	p.stateLock.RLock()
+	exited := p.exited
+	p.stateLock.RUnlock()
+
+	if exited != nil {
+		select {
+		case <-exited:
+			return true
+		case <-time.After(timeout):
+			return false
+		}
+	}
+
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if r, _, err := p.checkProcessReal(); err == nil && !r {
+			p.updateState()
+			return true
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+	return false
+}
+
+// ForceKill kills the server and its child processes
 func (p *ProcessMonitor) ForceKill(pid int) error {
-	return exec.Command("taskkill", "/F", "/PID", fmt.Sprintf("%d", pid), "/T").Run()
+	return killProcess(pid)
 }
 
 // Start launches the server with arguments
@@ -243,36 +662,66 @@ func (p *ProcessMonitor) Start(path string, args []string) error {
 
 	cmd := exec.Command(exePath, args...)
 	cmd.Dir = filepath.Dir(exePath)
+	prepareCommand(cmd)
 
-	// Capture stdout and stderr
-	stdout, _ := cmd.StdoutPipe()
-	stderr, _ := cmd.StderrPipe()
+	// Each run starts with a fresh console.log; the previous run is kept as a rotated file
+	p.rotateLogs(true)
+
+	// Server output goes to the console log; the panel follows the file instead of holding pipes
+	console, err := p.openConsoleLog()
+	if err != nil {
+		return fmt.Errorf("콘솔 로그 파일 생성 실패: %w", err)
+	}
+	defer console.Close() // The child keeps its own handle
+	cmd.Stdout = console
+	cmd.Stderr = console
+	tailFrom := fileSize(p.ConsoleLogPath())
 
 	if err := cmd.Start(); err != nil {
 		return fmt.Errorf("서버 시작 실패: %w", err)
 	}
 
-	// Stream logs in goroutines
-	go func() {
-		scanner := bufio.NewScanner(stdout)
-		for scanner.Scan() {
-			logs.GlobalLogs.Info(scanner.Text())
-		}
-	}()
+	rec := &ProcessRecord{
+		PID:  cmd.Process.Pid,
+		Exe:  exePath,
+		Args: args,
+	}
+	if rec.StartTime, err = processStartTime(rec.PID); err != nil {
+		// Wait() tracks this run; the monitor completes the record on a later check
+		logs.GlobalLogs.Warn(fmt.Sprintf("[%s] %v", p.Label, err))
+	}
+	exited := make(chan struct{})
 
-	go func() {
-		scanner := bufio.NewScanner(stderr)
-		for scanner.Scan() {
-			logs.GlobalLogs.Error(scanner.Text())
-		}
-	}()
+	p.stateLock.Lock()
+	p.cmd = cmd
+	p.record = rec
+	p.exited = exited
+	p.isRunning = true
+	p.cachedPID = rec.PID
+	p.startTailLocked(tailFrom)
+	p.stateLock.Unlock()
+	p.writePIDFile(rec)
 
 	// Monitor process exit in background to prevent zombies
 	go func() {
-		if err := cmd.Wait(); err != nil {
-			logs.GlobalLogs.Error(fmt.Sprintf("[%s] 서버 비정상 종료: %v", p.Executable, err))
+		err := cmd.Wait()
+		if err != nil {
+			logs.GlobalLogs.Error(fmt.Sprintf("[%s] 서버 비정상 종료: %v", p.Label, err))
 		} else {
-			logs.GlobalLogs.Info(fmt.Sprintf("[%s] 서버가 종료되었습니다.", p.Executable))
+			logs.GlobalLogs.Info(fmt.Sprintf("[%s] 서버가 종료되었습니다.", p.Label))
+		}
+
+		p.stateLock.Lock()
+		tracked := p.cmd == cmd
+		if tracked {
+			p.lastExit = newExitInfo(rec, err, true)
+			p.clearTrackingLocked()
+		}
+		p.stateLock.Unlock()
+		close(exited)
+
+		if tracked {
+			p.notifyExit(rec, err)
 		}
 	}()
 
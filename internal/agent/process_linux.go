@@ -0,0 +1,37 @@
+//go:build linux
+
+package agent
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// ServerBinaryName is the dedicated server executable shipped for this platform
+const ServerBinaryName = "ArmaReforgerServer"
+
+// terminateProcess sends SIGTERM to the process group led by pid
+func terminateProcess(pid int) error {
+	return signalGroup(pid, syscall.SIGTERM)
+}
+
+// killProcess sends SIGKILL to the process group led by pid so child processes die with it
+func killProcess(pid int) error {
+	return signalGroup(pid, syscall.SIGKILL)
+}
+
+// signalGroup signals the whole process group, falling back to the single process
+// when pid does not lead a group (e.g. a server that was not launched by us).
+func signalGroup(pid int, sig syscall.Signal) error {
+	err := syscall.Kill(-pid, sig)
+	if err == syscall.ESRCH {
+		err = syscall.Kill(pid, sig)
+	}
+	return err
+}
+
+// prepareCommand applies platform specific attributes before the server is launched.
+// The server gets its own process group so that Stop/ForceKill reach its children as well.
+func prepareCommand(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
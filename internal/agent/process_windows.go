@@ -0,0 +1,24 @@
+//go:build windows
+
+package agent
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// ServerBinaryName is the dedicated server executable shipped for this platform
+const ServerBinaryName = "ArmaReforgerServer.exe"
+
+// terminateProcess asks the process tree rooted at pid to exit
+func terminateProcess(pid int) error {
+	return exec.Command("taskkill", "/PID", fmt.Sprintf("%d", pid), "/T").Run()
+}
+
+// killProcess forcibly kills the process tree rooted at pid
+func killProcess(pid int) error {
+	return exec.Command("taskkill", "/F", "/PID", fmt.Sprintf("%d", pid), "/T").Run()
+}
+
+// prepareCommand applies platform specific attributes before the server is launched
+func prepareCommand(cmd *exec.Cmd) {}
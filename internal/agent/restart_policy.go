@@ -0,0 +1,318 @@
+package agent
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/astral/kg-server-web-gui/internal/logs"
+)
+
+// Final actions once an instance used up its restart attempts
+const (
+	FinalActionAlert      = "alert"       // Alert and stay down
+	FinalActionSafeConfig = "safe_config" // Start once more with a known-safe server config
+	FinalActionRollback   = "rollback"    // Restore the last-known-good config and start once more
+)
+
+// RestartPolicy controls how the watchdog restarts a crashed instance
+type RestartPolicy struct {
+	MaxAttempts  int     `json:"maxAttempts"`          // Restarts allowed within Window (default 3)
+	Window       int     `json:"window"`               // Seconds over which attempts are counted (default 300)
+	InitialDelay int     `json:"initialDelay"`         // Seconds before the first restart (default 10)
+	MaxDelay     int     `json:"maxDelay"`             // Backoff ceiling in seconds (default 300)
+	Multiplier   float64 `json:"multiplier"`           // Backoff growth per attempt (default 2)
+	Jitter       float64 `json:"jitter"`               // Random +/- fraction of each delay (default 0.2, negative disables)
+	Cooldown     int     `json:"cooldown"`             // Seconds after giving up before trying again (0 = stay down until started manually)
+	FinalAction  string  `json:"finalAction"`          // alert or safe_config
+	SafeConfig   string  `json:"safeConfig,omitempty"` // server.json started by the safe_config action
+	HealthyAfter int     `json:"healthyAfter"`         // Seconds of healthy running before the config is kept as last-known-good (default 600)
+}
+
+// RestartStatus is the current restart policy state of a watched instance
+type RestartStatus struct {
+	InstanceID  string        `json:"instanceId"`
+	Active      bool          `json:"active"`
+	Policy      RestartPolicy `json:"policy"`
+	Attempts    int           `json:"attempts"`
+	MaxAttempts int           `json:"maxAttempts"`
+	WindowStart *time.Time    `json:"windowStart,omitempty"`
+	LastAttempt *time.Time    `json:"lastAttempt,omitempty"`
+	NextRetry   *time.Time    `json:"nextRetry,omitempty"`  // Restart waiting for its backoff delay
+	GaveUp      bool          `json:"gaveUp"`               // Attempts used up, instance stays down
+	RetryAfter  *time.Time    `json:"retryAfter,omitempty"` // End of the cooldown after giving up
+	SafeConfig  bool          `json:"safeConfig"`           // Restarted with the fallback config
+	RolledBack  bool          `json:"rolledBack"`           // Restarted after restoring the last-known-good config
+}
+
+// restartAction is what the watchdog does for an instance after a check
+type restartAction int
+
+const (
+	actNone restartAction = iota
+	actRestart
+	actFallback
+	actRollback
+)
+
+// DefaultRestartPolicy returns the policy of instances without one configured
+func DefaultRestartPolicy() RestartPolicy {
+	return RestartPolicy{
+		MaxAttempts:  3,
+		Window:       300,
+		InitialDelay: 10,
+		MaxDelay:     300,
+		Multiplier:   2,
+		Jitter:       0.2,
+		FinalAction:  FinalActionAlert,
+		HealthyAfter: 600,
+	}
+}
+
+// WithDefaults fills unset fields from the default policy
+func (p *RestartPolicy) WithDefaults() RestartPolicy {
+	def := DefaultRestartPolicy()
+	if p == nil {
+		return def
+	}
+	policy := *p
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = def.MaxAttempts
+	}
+	if policy.Window <= 0 {
+		policy.Window = def.Window
+	}
+	if policy.InitialDelay <= 0 {
+		policy.InitialDelay = def.InitialDelay
+	}
+	if policy.MaxDelay <= 0 {
+		policy.MaxDelay = def.MaxDelay
+	}
+	if policy.Multiplier < 1 {
+		policy.Multiplier = def.Multiplier
+	}
+	if policy.Jitter == 0 {
+		policy.Jitter = def.Jitter
+	}
+	if policy.Jitter < 0 {
+		policy.Jitter = 0
+	}
+	if policy.FinalAction == "" {
+		policy.FinalAction = def.FinalAction
+	}
+	if policy.HealthyAfter <= 0 {
+		policy.HealthyAfter = def.HealthyAfter
+	}
+	return policy
+}
+
+// Validate checks the final action and value ranges
+func (p *RestartPolicy) Validate() error {
+	switch p.FinalAction {
+	case "", FinalActionAlert, FinalActionRollback:
+	case FinalActionSafeConfig:
+		if p.SafeConfig == "" {
+			return fmt.Errorf("안전 설정 파일 경로가 필요합니다")
+		}
+	default:
+		return fmt.Errorf("알 수 없는 최종 조치입니다: %s", p.FinalAction)
+	}
+	if p.MaxAttempts < 0 || p.Window < 0 || p.InitialDelay < 0 || p.MaxDelay < 0 || p.Cooldown < 0 || p.HealthyAfter < 0 {
+		return fmt.Errorf("재시작 정책 값은 음수일 수 없습니다")
+	}
+	if p.Jitter > 1 {
+		return fmt.Errorf("지터는 0에서 1 사이여야 합니다")
+	}
+	return nil
+}
+
+// delay returns the backoff before restart number attempt+1
+func (p RestartPolicy) delay(attempt int) time.Duration {
+	d := float64(p.InitialDelay) * math.Pow(p.Multiplier, float64(attempt))
+	d = math.Min(d, float64(p.MaxDelay))
+	if p.Jitter > 0 {
+		d *= 1 + p.Jitter*(2*rand.Float64()-1)
+	}
+	return time.Duration(d * float64(time.Second))
+}
+
+// SetRestartPolicy sets the restart policy of a watched instance (nil = defaults)
+func (w *Watchdog) SetRestartPolicy(id string, policy *RestartPolicy) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if inst, ok := w.instances[id]; ok {
+		inst.Policy = policy.WithDefaults()
+	}
+}
+
+// SetFallbackHandler registers how an instance is started with its safe config (safe_config final action)
+func (w *Watchdog) SetFallbackHandler(fn func(id, config string) error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.onFallback = fn
+}
+
+// SetRollbackHandler registers how an instance's last-known-good config is restored (rollback final action).
+// The handler returns a summary of what it rolled back.
+func (w *Watchdog) SetRollbackHandler(fn func(id string) (string, error)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.onRollback = fn
+}
+
+// SetGiveUpHandler registers a callback told when the watchdog stops restarting an instance
+func (w *Watchdog) SetGiveUpHandler(fn func(id, reason string)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.onGiveUp = fn
+}
+
+// GetRestartStatus returns the restart policy state of an instance, or nil if it is not watched
+func (w *Watchdog) GetRestartStatus(id string) *RestartStatus {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	inst, ok := w.instances[id]
+	if !ok {
+		return nil
+	}
+	status := &RestartStatus{
+		InstanceID:  id,
+		Active:      inst.Active,
+		Policy:      inst.Policy,
+		Attempts:    inst.attempts,
+		MaxAttempts: inst.Policy.MaxAttempts,
+		WindowStart: timePtr(inst.windowStart),
+		LastAttempt: timePtr(inst.lastAttempt),
+		NextRetry:   timePtr(inst.nextRetry),
+		GaveUp:      !inst.gaveUpAt.IsZero(),
+		SafeConfig:  inst.safeConfig,
+		RolledBack:  inst.rolledBack,
+	}
+	if status.GaveUp && inst.Policy.Cooldown > 0 {
+		status.RetryAfter = timePtr(inst.gaveUpAt.Add(time.Duration(inst.Policy.Cooldown) * time.Second))
+	}
+	return status
+}
+
+// ResetRestarts clears the attempts and give-up state of an instance
+func (w *Watchdog) ResetRestarts(id string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if inst, ok := w.instances[id]; ok {
+		inst.resetRestarts()
+		logs.GlobalLogs.Info(fmt.Sprintf("Watchdog restart state reset for instance: %s", id))
+	}
+}
+
+func (inst *WatchedInstance) resetRestarts() {
+	inst.attempts = 0
+	inst.windowStart = time.Time{}
+	inst.nextRetry = time.Time{}
+	inst.gaveUpAt = time.Time{}
+	inst.safeConfig = false
+	inst.rolledBack = false
+}
+
+// pendingRestart handles an instance found down while a restart is scheduled or it was given up (caller holds the lock).
+// handled is false when the crash is new.
+func (w *Watchdog) pendingRestart(inst *WatchedInstance, now time.Time) (action restartAction, handled bool) {
+	if !inst.nextRetry.IsZero() {
+		if now.Before(inst.nextRetry) {
+			return actNone, true
+		}
+		inst.nextRetry = time.Time{}
+		inst.attempts++
+		inst.lastAttempt = now
+		if inst.safeConfig {
+			return actFallback, true
+		}
+		return actRestart, true
+	}
+
+	if !inst.gaveUpAt.IsZero() {
+		cooldown := time.Duration(inst.Policy.Cooldown) * time.Second
+		if cooldown <= 0 || now.Sub(inst.gaveUpAt) < cooldown {
+			return actNone, true // Alerted once when giving up
+		}
+		logs.GlobalLogs.Info(fmt.Sprintf("Watchdog cooldown for %s is over, trying again", inst.ID))
+		inst.resetRestarts()
+		return w.scheduleRestart(inst, "⏳ Watchdog Retrying", "cooldown is over", "", now), true
+	}
+	return actNone, false
+}
+
+// scheduleRestart counts a crash against the policy and schedules the next restart or gives up (caller holds the lock).
+// excerpt (console tail) is appended to the Discord alert.
+func (w *Watchdog) scheduleRestart(inst *WatchedInstance, title, reason, excerpt string, now time.Time) restartAction {
+	p := inst.Policy
+	if inst.windowStart.IsZero() || now.Sub(inst.windowStart) > time.Duration(p.Window)*time.Second {
+		inst.attempts = 0
+		inst.windowStart = now
+	}
+
+	if inst.attempts >= p.MaxAttempts {
+		return w.giveUp(inst, reason, excerpt, now)
+	}
+
+	delay := p.delay(inst.attempts)
+	inst.nextRetry = now.Add(delay)
+	logs.GlobalLogs.Warn(fmt.Sprintf("Watchdog detected server %s down (%s). Restart %d/%d in %s", inst.ID, reason, inst.attempts+1, p.MaxAttempts, delay.Round(time.Second)))
+	w.discord.SendMessage(title, fmt.Sprintf("Server **%s** is down: %s\nRestart attempt %d/%d at %s (in %s).%s",
+		inst.ID, reason, inst.attempts+1, p.MaxAttempts, inst.nextRetry.Format("15:04:05"), delay.Round(time.Second), excerpt), ColorYellow)
+	return actNone
+}
+
+// giveUp applies the final action once the attempts are used up (caller holds the lock)
+func (w *Watchdog) giveUp(inst *WatchedInstance, reason, excerpt string, now time.Time) restartAction {
+	p := inst.Policy
+	inst.nextRetry = time.Time{}
+
+	if p.FinalAction == FinalActionSafeConfig && p.SafeConfig != "" && !inst.safeConfig && w.onFallback != nil {
+		inst.safeConfig = true
+		inst.lastAttempt = now
+		logs.GlobalLogs.Warn(fmt.Sprintf("Watchdog used up %d restarts of %s, starting it with the safe config %s", p.MaxAttempts, inst.ID, p.SafeConfig))
+		w.discord.SendMessage("🛟 Safe Config Fallback", fmt.Sprintf("Server **%s** crashed %d times within %s (%s).\nStarting it with the safe config `%s`.%s",
+			inst.ID, inst.attempts, time.Duration(p.Window)*time.Second, reason, p.SafeConfig, excerpt), ColorYellow)
+		return actFallback
+	}
+
+	if p.FinalAction == FinalActionRollback && !inst.rolledBack && w.onRollback != nil {
+		inst.rolledBack = true
+		inst.lastAttempt = now
+		logs.GlobalLogs.Warn(fmt.Sprintf("Watchdog used up %d restarts of %s, rolling back to the last-known-good config", p.MaxAttempts, inst.ID))
+		return actRollback
+	}
+
+	msg := fmt.Sprintf("Server **%s** crashed %d times within %s (%s) and stays down.", inst.ID, inst.attempts, time.Duration(p.Window)*time.Second, reason)
+	w.stayDown(inst, reason, msg, excerpt, now)
+	return actNone
+}
+
+// stayDown stops restarting an instance until the cooldown ends or it is started manually (caller holds the lock)
+func (w *Watchdog) stayDown(inst *WatchedInstance, reason, msg, excerpt string, now time.Time) {
+	p := inst.Policy
+	inst.gaveUpAt = now
+	inst.nextRetry = time.Time{}
+	if p.Cooldown > 0 {
+		msg += fmt.Sprintf("\nNext try after the cooldown at %s.", now.Add(time.Duration(p.Cooldown)*time.Second).Format("15:04:05"))
+	} else {
+		msg += "\nStart it manually to resume monitoring."
+	}
+	msg += excerpt
+	logs.GlobalLogs.Error(fmt.Sprintf("Watchdog gave up on %s after %d restarts", inst.ID, inst.attempts))
+	w.discord.SendMessage("🛑 Watchdog Gave Up", msg, ColorRed)
+
+	if w.onGiveUp != nil {
+		// Run outside the lock; the handler publishes events through the owner
+		go w.onGiveUp(inst.ID, reason)
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,147 @@
+package agent
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxPartialLine bounds how much of an unterminated line is buffered before it is emitted anyway
+const maxPartialLine = 64 * 1024
+
+// fileTail follows a growing log file by polling, like `tail -F`.
+// It survives truncation (copy-truncate rotation) by starting over from the beginning.
+type fileTail struct {
+	path     string
+	onLine   func(string)
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// followFile starts tailing path from offset (use fileSize to skip existing content)
+func followFile(path string, offset int64, onLine func(string)) *fileTail {
+	t := &fileTail{
+		path:   path,
+		onLine: onLine,
+		stop:   make(chan struct{}),
+	}
+	go t.run(offset)
+	return t
+}
+
+// fileSize returns the size of path, or 0 if it does not exist
+func fileSize(path string) int64 {
+	if info, err := os.Stat(path); err == nil {
+		return info.Size()
+	}
+	return 0
+}
+
+// Stop ends the tail loop
+func (t *fileTail) Stop() {
+	t.stopOnce.Do(func() { close(t.stop) })
+}
+
+func (t *fileTail) run(offset int64) {
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+
+	var partial []byte
+	buf := make([]byte, 32*1024)
+
+	for {
+		select {
+		case <-t.stop:
+			return
+		case <-ticker.C:
+		}
+
+		f, err := os.Open(t.path)
+		if err != nil {
+			continue
+		}
+
+		if info, err := f.Stat(); err == nil && info.Size() < offset {
+			// File was truncated (rotated), start over
+			offset = 0
+			partial = nil
+		}
+
+		if _, err := f.Seek(offset, io.SeekStart); err == nil {
+			for {
+				n, err := f.Read(buf)
+				if n > 0 {
+					offset += int64(n)
+					partial = t.emitLines(append(partial, buf[:n]...))
+				}
+				if err != nil {
+					break
+				}
+			}
+		}
+		f.Close()
+	}
+}
+
+// emitLines emits every complete line in data and returns the unterminated remainder
+func (t *fileTail) emitLines(data []byte) []byte {
+	for {
+		idx := bytes.IndexByte(data, '\n')
+		if idx < 0 {
+			break
+		}
+		t.onLine(strings.TrimRight(string(data[:idx]), "\r"))
+		data = data[idx+1:]
+	}
+
+	if len(data) > maxPartialLine {
+		t.onLine(string(data))
+		return nil
+	}
+	return append([]byte(nil), data...)
+}
+
+// tailLines returns the last n lines of path, reading backwards in blocks
+func tailLines(path string, n int) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	const block = 64 * 1024
+	var data []byte
+	pos := info.Size()
+	for pos > 0 && bytes.Count(data, []byte{'\n'}) <= n {
+		size := int64(block)
+		if pos < size {
+			size = pos
+		}
+		pos -= size
+		chunk := make([]byte, size)
+		if _, err := f.ReadAt(chunk, pos); err != nil && err != io.EOF {
+			return nil, err
+		}
+		data = append(chunk, data...)
+	}
+
+	lines := strings.Split(strings.TrimRight(string(data), "\r\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	for i := range lines {
+		lines[i] = strings.TrimRight(lines[i], "\r")
+	}
+	if len(lines) == 1 && lines[0] == "" {
+		return []string{}, nil
+	}
+	return lines, nil
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
@@ -23,22 +24,56 @@ type Watchdog struct {
 	instances map[string]*WatchedInstance
 	crashes   []CrashEvent
 	dataPath  string
+
+	// onRestart restarts an instance through its owner (readiness tracking, events)
+	onRestart func(id string) error
+	// onHang asks the owner before an instance is killed because it hung; an error vetoes the kill
+	onHang func(id, reason string) error
+	// onFallback starts an instance with its safe config; onGiveUp is told when restarts stop
+	onFallback func(id, config string) error
+	onRollback func(id string) (string, error)
+	onGiveUp   func(id, reason string)
+	// onCrash captures a forensics bundle of a recorded crash and returns its name
+	onCrash  func(event CrashEvent, exit *ExitInfo) string
+	captures []crashCapture // Recorded crashes waiting for their bundle
 }
 
 type CrashEvent struct {
+	ID         string    `json:"id"`
 	Timestamp  time.Time `json:"timestamp"`
 	InstanceID string    `json:"instanceId"`
 	Reason     string    `json:"reason"`
+	ExitCode   *int      `json:"exitCode,omitempty"`
+	Bundle     string    `json:"bundle,omitempty"` // Forensics bundle under data/crashes/<instanceId>/
+}
+
+// crashCapture is a recorded crash whose bundle has not been captured yet
+type crashCapture struct {
+	event CrashEvent
+	exit  *ExitInfo
 }
 
 type WatchedInstance struct {
-	ID           string
-	ServerPath   string // Path to server executable
-	Args         []string
-	Process      *ProcessMonitor
-	RestartCount int
-	LastRestart  time.Time
-	Active       bool // If false, we expect the server to be stopped
+	ID         string
+	ServerPath string // Path to server executable
+	Args       []string
+	Process    ProcessController
+	Policy     RestartPolicy
+	Active     bool // If false, we expect the server to be stopped
+
+	// Restart policy state
+	attempts    int
+	windowStart time.Time
+	lastAttempt time.Time
+	nextRetry   time.Time // Scheduled restart (zero = none)
+	gaveUpAt    time.Time // Attempts used up (zero = still restarting)
+	safeConfig  bool      // Restarted with the fallback config
+	rolledBack  bool      // Restarted after restoring the last-known-good config
+
+	// Liveness checks (hang detection), installed while the server is ready
+	Liveness         []*LivenessCheck
+	LivenessInterval time.Duration
+	lastLiveness     time.Time
 }
 
 func NewWatchdog(discord *DiscordClient, dataPath string) *Watchdog {
@@ -93,25 +128,49 @@ func (w *Watchdog) IsEnabled() bool {
 }
 
 // RegisterInstance registers the arguments and monitor needed to restart the server
-func (w *Watchdog) RegisterInstance(id string, serverPath string, args []string, proc *ProcessMonitor) {
+func (w *Watchdog) RegisterInstance(id string, serverPath string, args []string, proc ProcessController) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
+	// Keep restart counters when an instance is registered again (e.g. on a watchdog restart)
+	if inst, ok := w.instances[id]; ok {
+		inst.ServerPath = serverPath
+		inst.Args = args
+		inst.Process = proc
+		return
+	}
+
 	w.instances[id] = &WatchedInstance{
 		ID:         id,
 		ServerPath: serverPath,
 		Args:       args,
 		Process:    proc,
+		Policy:     DefaultRestartPolicy(),
 		Active:     true,
 	}
 }
 
+// UnregisterInstance stops watching a deleted instance
+func (w *Watchdog) UnregisterInstance(id string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.instances, id)
+}
+
+// SetRestartHandler makes the watchdog restart crashed instances through fn instead of the bare process monitor
+func (w *Watchdog) SetRestartHandler(fn func(id string) error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.onRestart = fn
+}
+
 // PauseMonitoring temporarily disables restart for a specific instance (e.g. manual stop)
 func (w *Watchdog) PauseMonitoring(id string) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 	if inst, ok := w.instances[id]; ok {
 		inst.Active = false
+		inst.nextRetry = time.Time{} // Drop a restart waiting for its backoff
 		logs.GlobalLogs.Info(fmt.Sprintf("Watchdog paused for instance: %s", id))
 	}
 }
@@ -122,11 +181,19 @@ func (w *Watchdog) ResumeMonitoring(id string) {
 	defer w.mu.Unlock()
 	if inst, ok := w.instances[id]; ok {
 		inst.Active = true
-		inst.RestartCount = 0 // Reset count on manual start
+		inst.resetRestarts() // A manual start gives the instance a fresh budget
 		logs.GlobalLogs.Info(fmt.Sprintf("Watchdog resumed for instance: %s", id))
 	}
 }
 
+// IsActive reports whether the watchdog currently restarts the given instance
+func (w *Watchdog) IsActive(id string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	inst, ok := w.instances[id]
+	return ok && inst.Active
+}
+
 func (w *Watchdog) StartMonitoring() {
 	if w.check != nil {
 		return
@@ -156,71 +223,209 @@ func (w *Watchdog) StopMonitoring() {
 
 func (w *Watchdog) checkProcess() {
 	w.mu.Lock()
-	defer w.mu.Unlock()
+	if !w.enabled {
+		w.mu.Unlock()
+		return
+	}
+	watched := make(map[*WatchedInstance]bool, len(w.instances))
+	for _, inst := range w.instances {
+		// Only a new crash needs its exit and console tail
+		watched[inst] = inst.Active && inst.nextRetry.IsZero() && inst.gaveUpAt.IsZero()
+	}
+	w.mu.Unlock()
+
+	// A remote process may ask its node, so nothing is read from the processes under the lock
+	states := make(map[*WatchedInstance]processState, len(watched))
+	for inst, newCrash := range watched {
+		states[inst] = observeProcess(inst.Process, newCrash)
+	}
 
+	w.mu.Lock()
 	if !w.enabled {
+		w.mu.Unlock()
 		return
 	}
+	var probes []*WatchedInstance
+	restarts := make(map[*WatchedInstance]restartAction)
+	probeChecks := make(map[string][]*LivenessCheck)
+	now := time.Now()
+	for inst, st := range states {
+		if w.instances[inst.ID] != inst {
+			continue // Unregistered meanwhile
+		}
+		if action := w.checkInstance(inst, st, now); action != actNone {
+			restarts[inst] = action
+		} else if w.dueLiveness(inst, now) {
+			probes = append(probes, inst)
+			probeChecks[inst.ID] = inst.Liveness
+		}
+	}
+	w.mu.Unlock()
 
-	for _, inst := range w.instances {
-		w.checkInstance(inst)
+	w.captureCrashes()
+
+	// Restart outside the lock: the restart handler calls back into RegisterInstance/ResumeMonitoring
+	for inst, action := range restarts {
+		w.restartInstance(inst, action)
+	}
+
+	// Liveness checks do network round trips, so they run outside the lock too
+	for _, inst := range probes {
+		if reason := w.runLiveness(inst.ID, probeChecks[inst.ID]); reason != "" {
+			w.handleHang(inst, reason)
+		}
 	}
 }
 
-func (w *Watchdog) checkInstance(inst *WatchedInstance) {
-	running, _, err := inst.Process.IsRunning()
-	if err != nil {
-		logs.GlobalLogs.Error(fmt.Sprintf("Watchdog check failed for %s: %v", inst.ID, err))
-		return
+// processState is what a check read from a process before taking the lock
+type processState struct {
+	running bool
+	err     error
+	exit    *ExitInfo
+	excerpt string // Console tail for the alert, only read for a new crash
+}
+
+// observeProcess reads the state of a process; with newCrash it also reads the exit and console tail of a stopped one
+func observeProcess(p ProcessController, newCrash bool) processState {
+	var st processState
+	st.running, _, st.err = p.IsRunning()
+	if st.err == nil && !st.running && newCrash {
+		st.exit = p.LastExit()
+		st.excerpt = crashExcerpt(p)
+	}
+	return st
+}
+
+// checkInstance records a crash and reports what to do about it under the restart policy (caller holds the lock)
+func (w *Watchdog) checkInstance(inst *WatchedInstance, st processState, now time.Time) restartAction {
+	if st.err != nil {
+		logs.GlobalLogs.Error(fmt.Sprintf("Watchdog check failed for %s: %v", inst.ID, st.err))
+		return actNone
 	}
 
 	// If manual stop, don't restart
-	if !inst.Active {
-		return
+	if !inst.Active || st.running {
+		return actNone
 	}
 
-	if !running {
-		// Detect Crash
-		now := time.Now()
+	if action, handled := w.pendingRestart(inst, now); handled {
+		return action
+	}
 
-		// Reset restart count if last restart was > 5 mins ago
-		if now.Sub(inst.LastRestart) > 5*time.Minute {
-			inst.RestartCount = 0
-		}
+	// Detect Crash
+	reason := "process not running"
+	if st.exit != nil && st.exit.ExitCode != nil {
+		reason = fmt.Sprintf("process exited with code %d", *st.exit.ExitCode)
+	}
+	w.recordCrashLocked(inst, reason, st.exit)
+	return w.scheduleRestart(inst, "⚠️ Server Crash Detected", reason, st.excerpt, now)
+}
 
-		if inst.RestartCount >= 3 {
-			logs.GlobalLogs.Error(fmt.Sprintf("Watchdog gave up on %s: Too many restarts.", inst.ID))
-			// Don't disable global watchdog, just maybe log error strictly?
-			// Or maybe we need per-instance enabled flag?
-			// For now, just spamming log is bad. Let's just return.
-			// Ideally we should alert once and stop trying for this instance.
-			return
-		}
+// recordCrashLocked appends to the crash history and queues its bundle capture (caller holds the lock)
+func (w *Watchdog) recordCrashLocked(inst *WatchedInstance, reason string, exit *ExitInfo) {
+	now := time.Now()
+	event := CrashEvent{
+		ID:         now.Format("20060102-150405"),
+		Timestamp:  now,
+		InstanceID: inst.ID,
+		Reason:     reason,
+	}
+	if exit != nil {
+		event.ExitCode = exit.ExitCode
+	}
+	w.crashes = append(w.crashes, event)
+	if len(w.crashes) > 50 {
+		w.crashes = w.crashes[1:]
+	}
+	w.saveCrashes()
+	w.captures = append(w.captures, crashCapture{event: event, exit: exit})
+}
+
+// crashExcerpt formats the last console lines of an instance for a Discord alert
+func crashExcerpt(p ProcessController) string {
+	lines, err := p.TailLogFile("", 8)
+	if err != nil || len(lines) == 0 {
+		return ""
+	}
+	text := strings.Join(lines, "\n")
+	if len(text) > 900 {
+		text = strings.ToValidUTF8(text[len(text)-900:], "")
+	}
+	return "\n```\n" + strings.ReplaceAll(text, "`", "'") + "\n```"
+}
+
+// SetCrashHandler registers the capture of a forensics bundle for every recorded crash
+func (w *Watchdog) SetCrashHandler(fn func(event CrashEvent, exit *ExitInfo) string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.onCrash = fn
+}
 
-		logs.GlobalLogs.Warn(fmt.Sprintf("Watchdog detected server %s down! Restarting... (%d/3)", inst.ID, inst.RestartCount+1))
-		w.discord.SendMessage("⚠️ Server Crash Detected", fmt.Sprintf("Server **%s** is down. Restarting... (Attempt %d/3)", inst.ID, inst.RestartCount+1), ColorYellow)
+// captureCrashes runs the crash handler for queued crashes outside the lock, before any restart overwrites the logs
+func (w *Watchdog) captureCrashes() {
+	w.mu.Lock()
+	pending := w.captures
+	w.captures = nil
+	handler := w.onCrash
+	w.mu.Unlock()
+	if handler == nil {
+		return
+	}
 
-		// Record Crash
-		event := CrashEvent{
-			Timestamp:  now,
-			InstanceID: inst.ID,
-			Reason:     "Process not running",
+	for _, c := range pending {
+		bundle := handler(c.event, c.exit)
+		if bundle == "" {
+			continue
 		}
-		w.crashes = append(w.crashes, event)
-		if len(w.crashes) > 50 {
-			w.crashes = w.crashes[1:]
+		w.mu.Lock()
+		for i := range w.crashes {
+			if w.crashes[i].ID == c.event.ID && w.crashes[i].InstanceID == c.event.InstanceID {
+				w.crashes[i].Bundle = bundle
+			}
 		}
 		w.saveCrashes()
+		w.mu.Unlock()
+	}
+}
 
-		// Restart - use saved ServerPath
-		if err := inst.Process.Start(inst.ServerPath, inst.Args); err != nil {
-			logs.GlobalLogs.Error(fmt.Sprintf("Watchdog restart failed for %s: %v", inst.ID, err))
-			w.discord.SendMessage("❌ Restart Failed", fmt.Sprintf("Failed to restart server %s: %v", inst.ID, err), ColorRed)
-		} else {
-			logs.GlobalLogs.Info(fmt.Sprintf("Watchdog restarted server %s successfully.", inst.ID))
-			w.discord.SendMessage("✅ Server Restored", fmt.Sprintf("Watchdog successfully restarted **%s**.", inst.ID), ColorGreen)
-			inst.LastRestart = now
-			inst.RestartCount++
+// restartInstance starts a crashed instance again, through the restart or fallback handler when set
+func (w *Watchdog) restartInstance(inst *WatchedInstance, action restartAction) {
+	w.mu.RLock()
+	handler, fallback, rollback := w.onRestart, w.onFallback, w.onRollback
+	safeConfig := inst.Policy.SafeConfig
+	attempt := fmt.Sprintf("%d/%d", inst.attempts, inst.Policy.MaxAttempts)
+	w.mu.RUnlock()
+
+	if action == actRollback && rollback != nil {
+		summary, err := rollback(inst.ID)
+		if err != nil {
+			logs.GlobalLogs.Error(fmt.Sprintf("Watchdog rollback failed for %s: %v", inst.ID, err))
+			w.mu.Lock()
+			w.stayDown(inst, "rollback failed: "+err.Error(), fmt.Sprintf("Server **%s** used up its restarts and could not be rolled back: %v", inst.ID, err), "", time.Now())
+			w.mu.Unlock()
+			return
 		}
+		w.discord.SendMessage("⏪ Config Rolled Back", fmt.Sprintf("Server **%s** kept crashing. Restored the last-known-good config and restarting once more:\n%s", inst.ID, summary), ColorYellow)
+		attempt = "after rollback"
+	}
+
+	var err error
+	switch {
+	case action == actFallback && fallback != nil:
+		err = fallback(inst.ID, safeConfig)
+		attempt = "safe config"
+	case handler != nil:
+		err = handler(inst.ID)
+	default:
+		// Restart - use saved ServerPath
+		err = inst.Process.Start(inst.ServerPath, inst.Args)
+	}
+
+	if err != nil {
+		logs.GlobalLogs.Error(fmt.Sprintf("Watchdog restart failed for %s (%s): %v", inst.ID, attempt, err))
+		w.discord.SendMessage("❌ Restart Failed", fmt.Sprintf("Failed to restart server %s (attempt %s): %v", inst.ID, attempt, err), ColorRed)
+		return
 	}
+	logs.GlobalLogs.Info(fmt.Sprintf("Watchdog restarted server %s successfully (%s).", inst.ID, attempt))
+	w.discord.SendMessage("✅ Server Restored", fmt.Sprintf("Watchdog successfully restarted **%s** (attempt %s).", inst.ID, attempt), ColorGreen)
 }
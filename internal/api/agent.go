@@ -0,0 +1,21 @@
+package api
+
+import (
+	"os"
+
+	"github.com/astral/kg-server-web-gui/internal/api/response"
+	"github.com/astral/kg-server-web-gui/internal/node"
+	"github.com/gofiber/fiber/v2"
+)
+
+// SetupAgentRoutes registers the API of agent mode: a panel on another host manages this host's servers through it
+func SetupAgentRoutes(app *fiber.App, version, token string) {
+	workDir, _ := os.Getwd()
+
+	app.Get("/agent/health", func(c *fiber.Ctx) error {
+		return c.JSON(response.Success(fiber.Map{"status": "ok", "version": version}))
+	})
+
+	agentApi := app.Group("/agent", node.TokenAuth(token))
+	node.NewAgent(version, workDir).Register(agentApi)
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/astral/kg-server-web-gui/internal/api/response"
+	"github.com/astral/kg-server-web-gui/internal/config"
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetAdvancedSettings returns the engine launch parameters of a server (null if never set)
+func (h *ApiHandlers) GetAdvancedSettings(c *fiber.Ctx) error {
+	adv, err := h.Manager.AdvancedSettings(c.Params("id"))
+	if err != nil {
+		return c.Status(404).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(response.Success(adv))
+}
+
+// SetAdvancedSettings replaces the engine launch parameters of a server, applied on its next start, and returns the new command line
+func (h *ApiHandlers) SetAdvancedSettings(c *fiber.Ctx) error {
+	var adv config.AdvancedSettings
+	if err := c.BodyParser(&adv); err != nil {
+		return c.Status(400).JSON(response.Error(err.Error()))
+	}
+	// The port override is checked like a server.json bindPort; ?force=true saves anyway
+	if !c.QueryBool("force") {
+		if conflicts := h.Manager.CheckAdvancedPorts(c.Params("id"), &adv); len(conflicts) > 0 {
+			return c.Status(409).JSON(portConflictResponse(conflicts))
+		}
+	}
+	if err := h.Manager.SetAdvancedSettings(c.Params("id"), &adv); err != nil {
+		return c.Status(400).JSON(response.Error(err.Error()))
+	}
+	cmd, err := h.Manager.CommandLine(c.Params("id"), nil)
+	if err != nil {
+		// Saved; the preview only fails while no server path is set
+		return c.JSON(response.Success(fiber.Map{"advanced": adv}))
+	}
+	return c.JSON(response.Success(fiber.Map{"advanced": adv, "commandLine": cmd}))
+}
+
+// GetCommandLine previews the command line Start runs (?args= adds space-separated extra arguments)
+func (h *ApiHandlers) GetCommandLine(c *fiber.Ctx) error {
+	cmd, err := h.Manager.CommandLine(c.Params("id"), strings.Fields(c.Query("args")))
+	if err != nil {
+		return c.Status(400).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(response.Success(cmd))
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"github.com/astral/kg-server-web-gui/internal/api/response"
+	"github.com/astral/kg-server-web-gui/internal/server"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ListServerBans returns the BattlEye ban list of a server with the panel's records
+func (h *ApiHandlers) ListServerBans(c *fiber.Ctx) error {
+	bans, err := h.Manager.ServerBans(c.Params("id"))
+	if err != nil {
+		return c.Status(502).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(response.Success(bans))
+}
+
+// AddServerBan bans a player number, BE GUID or IP address for {minutes} (0 = permanent)
+func (h *ApiHandlers) AddServerBan(c *fiber.Ctx) error {
+	var req server.BanRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(response.Error(err.Error()))
+	}
+	if req.Target == "" {
+		return c.Status(400).JSON(response.Error("차단 대상(플레이어 번호, BE GUID 또는 IP)이 필요합니다"))
+	}
+	record, err := h.Manager.AddBan(c.Params("id"), req, RequestTrigger(c))
+	if err != nil {
+		return c.Status(400).JSON(response.Error(err.Error()))
+	}
+	return c.Status(201).JSON(response.Success(record))
+}
+
+// RemoveServerBan lifts the ban on the BE GUID or IP address in the URL
+func (h *ApiHandlers) RemoveServerBan(c *fiber.Ctx) error {
+	if err := h.Manager.RemoveBan(c.Params("id"), c.Params("target"), RequestTrigger(c)); err != nil {
+		return c.Status(400).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(response.Success(fiber.Map{"status": "removed"}))
+}
+
+// ReloadServerBans makes the server read its bans.txt again (loadBans)
+func (h *ApiHandlers) ReloadServerBans(c *fiber.Ctx) error {
+	if err := h.Manager.ReloadBans(c.Params("id")); err != nil {
+		return c.Status(502).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(response.Success(fiber.Map{"status": "loaded"}))
+}
+
+// WriteServerBans makes the server save its ban list to bans.txt (writeBans)
+func (h *ApiHandlers) WriteServerBans(c *fiber.Ctx) error {
+	if err := h.Manager.WriteBans(c.Params("id")); err != nil {
+		return c.Status(502).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(response.Success(fiber.Map{"status": "written"}))
+}
+
+// SetSharedBans lets a server take part in the global ban list ({"shared": true}) or leave it
+func (h *ApiHandlers) SetSharedBans(c *fiber.Ctx) error {
+	var req struct {
+		Shared bool `json:"shared"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(response.Error(err.Error()))
+	}
+	if err := h.Manager.SetSharedBans(c.Params("id"), req.Shared); err != nil {
+		return c.Status(400).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(response.Success(fiber.Map{"shared": req.Shared}))
+}
+
+// AddGlobalBan bans a BE GUID or IP address on every server that shares bans
+func (h *ApiHandlers) AddGlobalBan(c *fiber.Ctx) error {
+	var req server.BanRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(response.Error(err.Error()))
+	}
+	record, err := h.Manager.AddGlobalBan(req, RequestTrigger(c))
+	if err != nil {
+		return c.Status(400).JSON(response.Error(err.Error()))
+	}
+	return c.Status(201).JSON(response.Success(record))
+}
+
+// RemoveGlobalBan lifts the global ban on the BE GUID or IP address in the URL
+func (h *ApiHandlers) RemoveGlobalBan(c *fiber.Ctx) error {
+	if err := h.Manager.RemoveGlobalBan(c.Params("target"), RequestTrigger(c)); err != nil {
+		return c.Status(404).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(response.Success(fiber.Map{"status": "removed"}))
+}
+
+// ExportBans downloads the active global bans as a BattlEye bans.txt
+func (h *ApiHandlers) ExportBans(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderContentType, fiber.MIMETextPlainCharsetUTF8)
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="bans.txt"`)
+	return c.SendString(h.Manager.ExportBans())
+}
+
+// ImportBans adds the bans of a BattlEye bans.txt (request body) to the global list
+func (h *ApiHandlers) ImportBans(c *fiber.Ctx) error {
+	if len(c.Body()) == 0 {
+		return c.Status(400).JSON(response.Error("bans.txt 내용이 필요합니다"))
+	}
+	return c.JSON(response.Success(h.Manager.ImportBans(string(c.Body()), RequestTrigger(c))))
+}
+
+// SearchBans searches the ban records of all servers (?q=&server=&status=&kind=&global=&limit=)
+func (h *ApiHandlers) SearchBans(c *fiber.Ctx) error {
+	return c.JSON(response.Success(h.Manager.SearchBans(server.BanQuery{
+		InstanceID: c.Query("server"),
+		Text:       c.Query("q"),
+		Status:     c.Query("status"),
+		Kind:       c.Query("kind"),
+		Global:     c.QueryBool("global"),
+		Limit:      c.QueryInt("limit", 100),
+	})))
+}
@@ -2,8 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
-	"os"
-	"path/filepath"
+	"errors"
 
 	"github.com/astral/kg-server-web-gui/internal/agent"
 	"github.com/astral/kg-server-web-gui/internal/api/response"
@@ -119,50 +118,56 @@ func (h *ApiHandlers) SendRcon(c *fiber.Ctx) error {
 		return c.Status(400).JSON(response.Error("Invalid request body"))
 	}
 
-	resp, err := h.Manager.SendRconCommand(id, req.Command)
+	resp, err := h.Manager.RunRconCommand(id, req.Command, RequestTrigger(c))
+	if errors.Is(err, server.ErrRconForbidden) {
+		return c.Status(403).JSON(response.Error(err.Error()))
+	}
 	if err != nil {
 		return c.Status(500).JSON(response.Error(err.Error()))
 	}
 
-	h.recordCommand(req.Command)
-
 	return c.JSON(response.Success(fiber.Map{
 		"response": resp,
 	}))
 }
 
+// GetRconStatus returns the state of the server's RCON session ({"state":"none"} while none is open)
+func (h *ApiHandlers) GetRconStatus(c *fiber.Ctx) error {
+	if h.Manager.Get(c.Params("id")) == nil {
+		return c.Status(404).JSON(response.Error("서버를 찾을 수 없습니다"))
+	}
+	if st := h.Manager.RconStatus(c.Params("id")); st != nil {
+		return c.JSON(response.Success(st))
+	}
+	return c.JSON(response.Success(fiber.Map{"state": "none"}))
+}
+
+// GetRconMessages returns the latest decoded server messages (chat, joins, kicks...) of the server (?limit=)
+func (h *ApiHandlers) GetRconMessages(c *fiber.Ctx) error {
+	if h.Manager.Get(c.Params("id")) == nil {
+		return c.Status(404).JSON(response.Error("서버를 찾을 수 없습니다"))
+	}
+	return c.JSON(response.Success(h.Manager.RconEvents(c.Params("id"), c.QueryInt("limit", 100))))
+}
+
 // GetCrashes returns the list of detected crash events
 func (h *ApiHandlers) GetCrashes(c *fiber.Ctx) error {
 	return c.JSON(response.Success(h.Watchdog.GetCrashes()))
 }
 
-// GetCommandHistory returns the recent RCON command history
+// GetCommandHistory returns the RCON commands the current user sent recently, newest first
 func (h *ApiHandlers) GetCommandHistory(c *fiber.Ctx) error {
-	path := filepath.Join(h.Manager.GetDataPath(), "command_history.json")
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return c.JSON(response.Success([]string{}))
+	records := h.Manager.RconCommandHistory(RequestTrigger(c).Name, c.Query("server"), 100)
+	history := make([]string, len(records))
+	for i, rec := range records {
+		history[i] = rec.Command
 	}
-	var history []string
-	json.Unmarshal(data, &history)
 	return c.JSON(response.Success(history))
 }
 
-func (h *ApiHandlers) recordCommand(cmd string) {
-	path := filepath.Join(h.Manager.GetDataPath(), "command_history.json")
-	var history []string
-	if data, err := os.ReadFile(path); err == nil {
-		json.Unmarshal(data, &history)
-	}
-
-	// Add to front, limit to 100
-	history = append([]string{cmd}, history...)
-	if len(history) > 100 {
-		history = history[:100]
-	}
-
-	data, _ := json.MarshalIndent(history, "", "  ")
-	os.WriteFile(path, data, 0644)
+// GetRconHistory returns the current user's RCON commands with responses (?server=&limit=)
+func (h *ApiHandlers) GetRconHistory(c *fiber.Ctx) error {
+	return c.JSON(response.Success(h.Manager.RconCommandHistory(RequestTrigger(c).Name, c.Query("server"), c.QueryInt("limit", 100))))
 }
 
 // ValidateConfig checks if the raw config string is valid JSON and follows schema rules
@@ -199,3 +204,11 @@ func (h *ApiHandlers) ValidateConfig(c *fiber.Ctx) error {
 		"warning": warning,
 	})
 }
+
+// RequestTrigger attributes an action to the logged-in panel user
+func RequestTrigger(c *fiber.Ctx) server.Trigger {
+	username, _ := c.Locals("username").(string)
+	by := server.ByUser(username)
+	by.Admin = c.Locals("role") == "admin"
+	return by
+}
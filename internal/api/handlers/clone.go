@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"github.com/astral/kg-server-web-gui/internal/api/response"
+	"github.com/astral/kg-server-web-gui/internal/server"
+	"github.com/gofiber/fiber/v2"
+)
+
+// CloneServer creates a new server from an existing one with its ports moved to the next free set
+func (h *ApiHandlers) CloneServer(c *fiber.Ctx) error {
+	var opts server.CloneOptions
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&opts); err != nil {
+			return c.Status(400).JSON(response.Error(err.Error()))
+		}
+	}
+	opts.Trigger = RequestTrigger(c)
+
+	result, err := h.Manager.Clone(c.Params("id"), opts)
+	if err != nil {
+		return c.Status(400).JSON(response.Error(err.Error()))
+	}
+	return c.Status(201).JSON(response.Success(result))
+}
@@ -1,15 +1,27 @@
 package handlers
 
 import (
+	"encoding/json"
+	"strings"
+
 	"github.com/astral/kg-server-web-gui/internal/api/response"
 	"github.com/astral/kg-server-web-gui/internal/config"
+	"github.com/astral/kg-server-web-gui/internal/server"
 	"github.com/astral/kg-server-web-gui/internal/workshop"
 	"github.com/gofiber/fiber/v2"
 )
 
+// configPath is the ?path of a config request, or the server.json of ?server (default: the default server)
+func (h *ApiHandlers) configPath(c *fiber.Ctx) string {
+	if path := c.Query("path"); path != "" {
+		return path // Security risk? For local tool, acceptable.
+	}
+	return h.Manager.ConfigPath(c.Query("server", "default"))
+}
+
 // GetConfig reads server.json
 func (h *ApiHandlers) GetConfig(c *fiber.Ctx) error {
-	path := c.Query("path", "server.json") // Security risk? For local tool, acceptable.
+	path := h.configPath(c)
 
 	data, err := h.Config.ReadConfig(path)
 	if err != nil {
@@ -21,12 +33,15 @@ func (h *ApiHandlers) GetConfig(c *fiber.Ctx) error {
 
 // SaveConfig writes server.json
 func (h *ApiHandlers) SaveConfig(c *fiber.Ctx) error {
-	path := c.Query("path", "server.json")
+	path := h.configPath(c)
 
 	var data config.ServerConfig
 	if err := c.BodyParser(&data); err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 	}
+	if conflicts := h.portConflicts(c, path, &data); len(conflicts) > 0 {
+		return c.Status(409).JSON(portConflictResponse(conflicts))
+	}
 
 	if err := h.Config.WriteConfig(path, &data); err != nil {
 		return c.Status(500).JSON(response.Error(err.Error()))
@@ -37,7 +52,7 @@ func (h *ApiHandlers) SaveConfig(c *fiber.Ctx) error {
 
 // GetConfigRaw reads server.json as text
 func (h *ApiHandlers) GetConfigRaw(c *fiber.Ctx) error {
-	path := c.Query("path", "server.json")
+	path := h.configPath(c)
 
 	data, err := h.Config.ReadConfigRaw(path)
 	if err != nil {
@@ -49,7 +64,7 @@ func (h *ApiHandlers) GetConfigRaw(c *fiber.Ctx) error {
 
 // SaveConfigRaw writes server.json as text
 func (h *ApiHandlers) SaveConfigRaw(c *fiber.Ctx) error {
-	path := c.Query("path", "server.json")
+	path := h.configPath(c)
 
 	var req struct {
 		Content string `json:"content"`
@@ -57,6 +72,12 @@ func (h *ApiHandlers) SaveConfigRaw(c *fiber.Ctx) error {
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 	}
+	var data config.ServerConfig
+	if json.Unmarshal([]byte(req.Content), &data) == nil {
+		if conflicts := h.portConflicts(c, path, &data); len(conflicts) > 0 {
+			return c.Status(409).JSON(portConflictResponse(conflicts))
+		}
+	}
 
 	if err := h.Config.WriteConfigRaw(path, req.Content); err != nil {
 		return c.Status(500).JSON(response.Error(err.Error()))
@@ -65,6 +86,32 @@ func (h *ApiHandlers) SaveConfigRaw(c *fiber.Ctx) error {
 	return c.JSON(response.Success(fiber.Map{"status": "saved"}))
 }
 
+// portConflicts checks the ports of a config being saved for a server against the other servers.
+// Saving anyway is possible with ?force=true.
+func (h *ApiHandlers) portConflicts(c *fiber.Ctx, path string, data *config.ServerConfig) []server.PortConflict {
+	if c.QueryBool("force") {
+		return nil
+	}
+	id := h.Manager.InstanceByConfigPath(path)
+	if id == "" {
+		return nil
+	}
+	// A running server holds its own ports, so the host is only checked while it is stopped
+	inst := h.Manager.Get(id)
+	host := inst != nil && (inst.Status == server.StatusStopped || inst.Status == server.StatusCrashed)
+	return h.Manager.CheckPorts(id, data, host)
+}
+
+func portConflictResponse(conflicts []server.PortConflict) response.ApiResponse {
+	msgs := make([]string, len(conflicts))
+	for i, c := range conflicts {
+		msgs[i] = c.Message
+	}
+	res := response.Error("포트 충돌: " + strings.Join(msgs, ", "))
+	res.Data = conflicts
+	return res
+}
+
 // EnrichModsRequest is the request body for EnrichMods
 type EnrichModsRequest struct {
 	Mods []struct {
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"github.com/astral/kg-server-web-gui/internal/api/response"
+	"github.com/gofiber/fiber/v2"
+)
+
+const maxTailLines = 5000
+
+// ListLogs returns the console log files of a server instance
+func (h *ApiHandlers) ListLogs(c *fiber.Ctx) error {
+	proc := h.Manager.GetMonitor(c.Params("id"))
+	if proc == nil {
+		return c.Status(404).JSON(response.Error("서버를 찾을 수 없습니다"))
+	}
+
+	files, err := proc.ListLogFiles()
+	if err != nil {
+		return c.Status(500).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(response.Success(files))
+}
+
+// TailLog returns the last lines of a console log file (current file by default)
+func (h *ApiHandlers) TailLog(c *fiber.Ctx) error {
+	proc := h.Manager.GetMonitor(c.Params("id"))
+	if proc == nil {
+		return c.Status(404).JSON(response.Error("서버를 찾을 수 없습니다"))
+	}
+
+	n := c.QueryInt("lines", 200)
+	if n <= 0 {
+		n = 200
+	}
+	if n > maxTailLines {
+		n = maxTailLines
+	}
+
+	lines, err := proc.TailLogFile(c.Query("file"), n)
+	if err != nil {
+		return c.Status(404).JSON(response.Error("로그 파일을 찾을 수 없습니다"))
+	}
+	return c.JSON(response.Success(lines))
+}
+
+// DownloadLog sends a console log file as an attachment
+func (h *ApiHandlers) DownloadLog(c *fiber.Ctx) error {
+	proc := h.Manager.GetMonitor(c.Params("id"))
+	if proc == nil {
+		return c.Status(404).JSON(response.Error("서버를 찾을 수 없습니다"))
+	}
+
+	name := c.Params("file")
+	f, err := proc.OpenLogFile(name)
+	if err != nil {
+		return c.Status(404).JSON(response.Error("로그 파일을 찾을 수 없습니다"))
+	}
+	c.Attachment(c.Params("id") + "-" + name)
+	return c.SendStream(f)
+}
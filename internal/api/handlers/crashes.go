@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"bufio"
+	"fmt"
+
+	"github.com/astral/kg-server-web-gui/internal/api/response"
+	"github.com/astral/kg-server-web-gui/internal/logs"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ListCrashBundles returns the crash forensics bundles of a server, newest first
+func (h *ApiHandlers) ListCrashBundles(c *fiber.Ctx) error {
+	bundles, err := h.Manager.ListCrashBundles(c.Params("id"))
+	if err != nil {
+		return c.Status(404).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(response.Success(bundles))
+}
+
+// DownloadCrashBundle sends one crash bundle as a zip archive
+func (h *ApiHandlers) DownloadCrashBundle(c *fiber.Ctx) error {
+	id, name := c.Params("id"), c.Params("bundle")
+	if !h.Manager.CrashBundleExists(id, name) {
+		return c.Status(404).JSON(response.Error("크래시 기록을 찾을 수 없습니다"))
+	}
+
+	c.Set(fiber.HeaderContentType, "application/zip")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s-crash-%s.zip"`, id, name))
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := h.Manager.WriteCrashBundleZip(id, name, w); err != nil {
+			logs.GlobalLogs.Error(fmt.Sprintf("[%s] 크래시 기록 압축 실패: %v", id, err))
+		}
+		w.Flush()
+	})
+	return nil
+}
@@ -0,0 +1,22 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/astral/kg-server-web-gui/internal/api/response"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ListEvents returns a page of an instance's lifecycle history, newest first
+func (h *ApiHandlers) ListEvents(c *fiber.Ctx) error {
+	var types []string
+	if t := c.Query("type"); t != "" {
+		types = strings.Split(t, ",")
+	}
+
+	page, err := h.Manager.ListEvents(c.Params("id"), c.QueryInt("page", 1), c.QueryInt("limit", 50), types)
+	if err != nil {
+		return c.Status(404).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(response.Success(page))
+}
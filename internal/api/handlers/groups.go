@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"github.com/astral/kg-server-web-gui/internal/api/response"
+	"github.com/astral/kg-server-web-gui/internal/group"
+	"github.com/gofiber/fiber/v2"
+)
+
+type GroupHandler struct {
+	manager *group.Manager
+}
+
+func NewGroupHandler(manager *group.Manager) *GroupHandler {
+	return &GroupHandler{manager: manager}
+}
+
+// ListGroups returns all groups, or those with ?tag
+func (h *GroupHandler) ListGroups(c *fiber.Ctx) error {
+	return c.JSON(response.Success(h.manager.List(c.Query("tag"))))
+}
+
+// GetGroup returns one group
+func (h *GroupHandler) GetGroup(c *fiber.Ctx) error {
+	g := h.manager.Get(c.Params("id"))
+	if g == nil {
+		return c.Status(404).JSON(response.Error("그룹을 찾을 수 없습니다"))
+	}
+	return c.JSON(response.Success(g))
+}
+
+// AddGroup creates a group
+func (h *GroupHandler) AddGroup(c *fiber.Ctx) error {
+	var g group.Group
+	if err := c.BodyParser(&g); err != nil {
+		return c.Status(400).JSON(response.Error(err.Error()))
+	}
+	if err := h.manager.Add(&g); err != nil {
+		return c.Status(400).JSON(response.Error(err.Error()))
+	}
+	return c.Status(201).JSON(response.Success(g))
+}
+
+// UpdateGroup replaces a group's members and settings
+func (h *GroupHandler) UpdateGroup(c *fiber.Ctx) error {
+	var g group.Group
+	if err := c.BodyParser(&g); err != nil {
+		return c.Status(400).JSON(response.Error(err.Error()))
+	}
+	g.ID = c.Params("id") // Ensure ID matches URL
+
+	if err := h.manager.Update(&g); err != nil {
+		return c.Status(400).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(response.Success(h.manager.Get(g.ID)))
+}
+
+// DeleteGroup removes a group
+func (h *GroupHandler) DeleteGroup(c *fiber.Ctx) error {
+	if err := h.manager.Delete(c.Params("id")); err != nil {
+		return c.Status(400).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(response.Success(fiber.Map{"status": "deleted"}))
+}
+
+// RunAction starts, stops or restarts the members of a group in the background
+func (h *GroupHandler) RunAction(action string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req group.OperationRequest
+		if len(c.Body()) > 0 {
+			if err := c.BodyParser(&req); err != nil {
+				return c.Status(400).JSON(response.Error(err.Error()))
+			}
+		}
+		op, err := h.manager.Run(c.Params("id"), action, req, RequestTrigger(c))
+		if err != nil {
+			return c.Status(409).JSON(response.Error(err.Error()))
+		}
+		return c.Status(202).JSON(response.Success(op))
+	}
+}
+
+// GetOperation returns the per-member progress of a group's current or last operation
+func (h *GroupHandler) GetOperation(c *fiber.Ctx) error {
+	op := h.manager.GetOperation(c.Params("id"))
+	if op == nil {
+		return c.Status(404).JSON(response.Error("그룹 작업 기록이 없습니다"))
+	}
+	return c.JSON(response.Success(op))
+}
+
+// CancelOperation stops a group operation before its next member
+func (h *GroupHandler) CancelOperation(c *fiber.Ctx) error {
+	if err := h.manager.Cancel(c.Params("id")); err != nil {
+		return c.Status(409).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(response.Success(fiber.Map{"status": "cancelled"}))
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"github.com/astral/kg-server-web-gui/internal/api/response"
+	"github.com/astral/kg-server-web-gui/internal/server"
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetMaintenance returns the active maintenance lock of a server (null if none)
+func (h *ApiHandlers) GetMaintenance(c *fiber.Ctx) error {
+	if h.Manager.Get(c.Params("id")) == nil {
+		return c.Status(404).JSON(response.Error("서버를 찾을 수 없습니다"))
+	}
+	return c.JSON(response.Success(h.Manager.Maintenance(c.Params("id"))))
+}
+
+// SetMaintenance locks a server for maintenance under the requesting user
+func (h *ApiHandlers) SetMaintenance(c *fiber.Ctx) error {
+	var req server.MaintenanceRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(400).JSON(response.Error(err.Error()))
+		}
+	}
+	lock, err := h.Manager.SetMaintenance(c.Params("id"), req, RequestTrigger(c))
+	if err != nil {
+		return c.Status(ErrorStatus(err, 400)).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(response.Success(lock))
+}
+
+// ClearMaintenance lifts a maintenance lock (owner or admin only)
+func (h *ApiHandlers) ClearMaintenance(c *fiber.Ctx) error {
+	if err := h.Manager.ClearMaintenance(c.Params("id"), RequestTrigger(c)); err != nil {
+		return c.Status(ErrorStatus(err, 400)).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(response.Success(fiber.Map{"status": "cleared"}))
+}
+
+// ErrorStatus maps refusals because of a maintenance lock to 423 Locked, anything else to fallback
+func ErrorStatus(err error, fallback int) int {
+	if server.IsMaintenance(err) {
+		return fiber.StatusLocked
+	}
+	return fallback
+}
@@ -68,21 +68,11 @@ func (h *MapHandler) ApplyMap(c *fiber.Ctx) error {
 		return c.Status(400).JSON(response.Error("유효하지 않은 슬롯 번호"))
 	}
 
-	// Get requester from session if available
-	requester := "Web UI"
-	if user := c.Locals("user"); user != nil {
-		if u, ok := user.(map[string]interface{}); ok {
-			if username, ok := u["username"].(string); ok {
-				requester = username
-			}
-		}
-	}
-
 	// Get instance ID from query or default
 	instanceID := c.Query("instance", "default")
 
-	if err := h.mapService.ChangeMapBySlot(instanceID, slot, requester); err != nil {
-		return c.Status(500).JSON(response.Error(err.Error()))
+	if err := h.mapService.ChangeMapBySlot(instanceID, slot, RequestTrigger(c)); err != nil {
+		return c.Status(ErrorStatus(err, 500)).JSON(response.Error(err.Error()))
 	}
 
 	mapping := h.mapService.GetMappingManager().Get(slot)
@@ -136,17 +126,8 @@ func (h *MapHandler) ApplyMapByScenario(c *fiber.Ctx) error {
 		req.InstanceID = "default"
 	}
 
-	requester := "Web UI"
-	if user := c.Locals("user"); user != nil {
-		if u, ok := user.(map[string]interface{}); ok {
-			if username, ok := u["username"].(string); ok {
-				requester = username
-			}
-		}
-	}
-
-	if err := h.mapService.ChangeMap(req.InstanceID, req.ScenarioID, req.Name, requester); err != nil {
-		return c.Status(500).JSON(response.Error(err.Error()))
+	if err := h.mapService.ChangeMap(req.InstanceID, req.ScenarioID, req.Name, RequestTrigger(c)); err != nil {
+		return c.Status(ErrorStatus(err, 500)).JSON(response.Error(err.Error()))
 	}
 
 	return c.JSON(response.Success(fiber.Map{
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"github.com/astral/kg-server-web-gui/internal/api/response"
+	"github.com/astral/kg-server-web-gui/internal/node"
+	"github.com/astral/kg-server-web-gui/internal/server"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ListNodes returns the registered agent nodes
+func (h *ApiHandlers) ListNodes(c *fiber.Ctx) error {
+	return c.JSON(response.Success(h.Manager.ListNodes()))
+}
+
+// AddNode registers an agent node (or updates its address and token)
+func (h *ApiHandlers) AddNode(c *fiber.Ctx) error {
+	var n server.Node
+	if err := c.BodyParser(&n); err != nil {
+		return c.Status(400).JSON(response.Error(err.Error()))
+	}
+	saved, err := h.Manager.AddNode(n)
+	if err != nil {
+		return c.Status(400).JSON(response.Error(err.Error()))
+	}
+	return c.Status(201).JSON(response.Success(saved))
+}
+
+// GetNode returns a node and whether its agent answers
+func (h *ApiHandlers) GetNode(c *fiber.Ctx) error {
+	st, err := h.Manager.NodeStatus(c.Params("id"))
+	if err != nil {
+		return c.Status(404).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(response.Success(st))
+}
+
+// DeleteNode removes a node no server is bound to
+func (h *ApiHandlers) DeleteNode(c *fiber.Ctx) error {
+	if err := h.Manager.DeleteNode(c.Params("id")); err != nil {
+		return c.Status(400).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(response.Success(fiber.Map{"status": "deleted"}))
+}
+
+// nodeClient returns the client of the node in the URL
+func (h *ApiHandlers) nodeClient(c *fiber.Ctx) (*node.Client, error) {
+	return h.Manager.NodeClient(c.Params("id"))
+}
+
+// GetNodeFile returns a file, or lists a directory, under the agent's working directory (?path=)
+func (h *ApiHandlers) GetNodeFile(c *fiber.Ctx) error {
+	client, err := h.nodeClient(c)
+	if err != nil {
+		return c.Status(404).JSON(response.Error(err.Error()))
+	}
+	data, contentType, err := client.GetFile(c.Query("path"))
+	if err != nil {
+		return c.Status(502).JSON(response.Error(err.Error()))
+	}
+	c.Set(fiber.HeaderContentType, contentType)
+	return c.Send(data)
+}
+
+// PutNodeFile replaces a file under the agent's working directory with the request body (?path=)
+func (h *ApiHandlers) PutNodeFile(c *fiber.Ctx) error {
+	client, err := h.nodeClient(c)
+	if err != nil {
+		return c.Status(404).JSON(response.Error(err.Error()))
+	}
+	if c.Query("path") == "" {
+		return c.Status(400).JSON(response.Error("path가 필요합니다"))
+	}
+	if err := client.WriteFile(c.Query("path"), c.Body()); err != nil {
+		return c.Status(502).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(response.Success(fiber.Map{"status": "saved"}))
+}
+
+// GetNodeSteamCMD returns the state of the server installation on a node
+func (h *ApiHandlers) GetNodeSteamCMD(c *fiber.Ctx) error {
+	client, err := h.nodeClient(c)
+	if err != nil {
+		return c.Status(404).JSON(response.Error(err.Error()))
+	}
+	st, err := client.SteamCMD()
+	if err != nil {
+		return c.Status(502).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(response.Success(st))
+}
+
+// UpdateNodeServer installs or updates the server on a node through its SteamCMD
+func (h *ApiHandlers) UpdateNodeServer(c *fiber.Ctx) error {
+	client, err := h.nodeClient(c)
+	if err != nil {
+		return c.Status(404).JSON(response.Error(err.Error()))
+	}
+	var req struct {
+		Experimental bool `json:"experimental"`
+	}
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(400).JSON(response.Error(err.Error()))
+		}
+	}
+	if err := client.UpdateServer(req.Experimental); err != nil {
+		return c.Status(502).JSON(response.Error(err.Error()))
+	}
+	return c.Status(202).JSON(response.Success(fiber.Map{"status": "started"}))
+}
+
+// BindServerNode moves a stopped server to a node ({"node": ""} = this host)
+func (h *ApiHandlers) BindServerNode(c *fiber.Ctx) error {
+	var req struct {
+		Node string `json:"node"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(response.Error(err.Error()))
+	}
+	if err := h.Manager.BindNode(c.Params("id"), req.Node); err != nil {
+		return c.Status(400).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(response.Success(h.Manager.Get(c.Params("id"))))
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/astral/kg-server-web-gui/internal/api/response"
+	"github.com/astral/kg-server-web-gui/internal/server"
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -58,24 +59,25 @@ func (h *ApiHandlers) BanPlayer(c *fiber.Ctx) error {
 	}
 
 	var req struct {
-		Identifier string `json:"identifier"` // Index, Name, or UID
+		Identifier string `json:"identifier"` // Player number, BE GUID or IP address
 		Reason     string `json:"reason"`
-		Duration   string `json:"duration,omitempty"` // Not used yet by backend method, but might be useful
+		Duration   int    `json:"duration,omitempty"` // Minutes, 0 = permanent
 	}
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(400).JSON(response.Error("Invalid request body"))
 	}
 
 	if req.Identifier == "" {
-		return c.Status(400).JSON(response.Error("Identifier required (index, name, or uid)"))
+		return c.Status(400).JSON(response.Error("Identifier required (player number, BE GUID or IP)"))
 	}
 	if req.Reason == "" {
 		req.Reason = "Banned by admin"
 	}
 
-	if err := h.Manager.BanPlayer(id, req.Identifier, req.Reason); err != nil {
+	record, err := h.Manager.AddBan(id, server.BanRequest{Target: req.Identifier, Minutes: req.Duration, Reason: req.Reason}, RequestTrigger(c))
+	if err != nil {
 		return c.Status(500).JSON(response.Error(fmt.Sprintf("Failed to ban: %v", err)))
 	}
 
-	return c.JSON(response.Success(fiber.Map{"status": "banned", "identifier": req.Identifier}))
+	return c.JSON(response.Success(fiber.Map{"status": "banned", "identifier": req.Identifier, "duration": req.Duration, "ban": record}))
 }
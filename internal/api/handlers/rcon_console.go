@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/astral/kg-server-web-gui/internal/api/response"
+	"github.com/astral/kg-server-web-gui/internal/battleye"
+	"github.com/astral/kg-server-web-gui/internal/server"
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// consoleBacklog is how many recent server messages a new console connection receives
+	consoleBacklog = 50
+	// consoleBuffer holds messages for a slow client; server messages beyond it are dropped
+	consoleBuffer     = 256
+	consoleWriteLimit = 10 * time.Second
+	consolePing       = 30 * time.Second
+)
+
+// ConsoleMessage is a message on the RCON console WebSocket.
+// The client sends {"type":"command","id":"1","command":"players"}; the server answers with
+// "reply" (same id), and pushes "hello" once, then "event" for every server message.
+type ConsoleMessage struct {
+	Type      string             `json:"type"`         // hello, event, command, reply, error
+	ID        string             `json:"id,omitempty"` // Chosen by the client, echoed in the reply
+	Command   string             `json:"command,omitempty"`
+	Response  string             `json:"response,omitempty"`
+	Error     string             `json:"error,omitempty"`
+	Forbidden bool               `json:"forbidden,omitempty"` // The user's role may not send the command
+	Time      time.Time          `json:"time"`
+	Event     *server.RconEvent  `json:"event,omitempty"`
+	Status    *battleye.Status   `json:"status,omitempty"`    // hello: RCON session state (nil = not connected yet)
+	Backlog   []server.RconEvent `json:"backlog,omitempty"`   // hello: latest server messages, oldest first
+	Streaming bool               `json:"streaming,omitempty"` // hello: server messages are pushed (false if the RCON session could not be opened)
+}
+
+// RconConsoleUpgrade admits WebSocket requests for an existing server and keeps the user for the console
+func (h *ApiHandlers) RconConsoleUpgrade(c *fiber.Ctx) error {
+	if !websocket.IsWebSocketUpgrade(c) {
+		return c.Status(fiber.StatusUpgradeRequired).JSON(response.Error("WebSocket 연결이 필요합니다"))
+	}
+	if h.Manager.Get(c.Params("id")) == nil {
+		return c.Status(404).JSON(response.Error("서버를 찾을 수 없습니다"))
+	}
+	c.Locals("trigger", RequestTrigger(c))
+	return c.Next()
+}
+
+// RconConsole streams a server's RCON messages and runs the commands the client sends
+func (h *ApiHandlers) RconConsole(conn *websocket.Conn) {
+	id := conn.Params("id")
+	by, _ := conn.Locals("trigger").(server.Trigger)
+
+	out := make(chan ConsoleMessage, consoleBuffer)
+	done := make(chan struct{})
+	var writer sync.WaitGroup
+	writer.Add(1)
+	go func() {
+		defer writer.Done()
+		ping := time.NewTicker(consolePing)
+		defer ping.Stop()
+		for {
+			var err error
+			select {
+			case <-done:
+				return
+			case msg := <-out:
+				conn.SetWriteDeadline(time.Now().Add(consoleWriteLimit))
+				err = conn.WriteJSON(msg)
+			case <-ping.C:
+				err = conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(consoleWriteLimit))
+			}
+			if err != nil {
+				conn.Close() // Ends the read loop below
+				return
+			}
+		}
+	}()
+	// The connection is released when the handler returns, the writer must be gone by then
+	defer writer.Wait()
+	defer close(done)
+
+	send := func(msg ConsoleMessage) {
+		select {
+		case out <- msg:
+		case <-done:
+		}
+	}
+
+	streaming := h.Manager.OpenRcon(id) == nil
+	if streaming {
+		unsubscribe := h.Manager.SubscribeRcon(func(ev server.RconEvent) {
+			if ev.InstanceID != id {
+				return
+			}
+			// Runs on the session reader: never wait for a slow client
+			select {
+			case out <- ConsoleMessage{Type: "event", Time: ev.Time, Event: &ev}:
+			default:
+			}
+		})
+		defer unsubscribe()
+	}
+	send(ConsoleMessage{
+		Type:      "hello",
+		Time:      time.Now(),
+		Status:    h.Manager.RconStatus(id),
+		Backlog:   h.Manager.RconEvents(id, consoleBacklog),
+		Streaming: streaming,
+	})
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		var msg ConsoleMessage
+		if err := json.Unmarshal(data, &msg); err != nil || msg.Type != "command" {
+			send(ConsoleMessage{Type: "error", ID: msg.ID, Error: "알 수 없는 메시지입니다", Time: time.Now()})
+			continue
+		}
+		// Commands queue on the RCON session; replies are matched to the client's id, not to the order
+		go func(msg ConsoleMessage) {
+			resp, err := h.Manager.RunRconCommand(id, msg.Command, by)
+			reply := ConsoleMessage{Type: "reply", ID: msg.ID, Command: msg.Command, Response: resp, Time: time.Now()}
+			if err != nil {
+				reply.Error = err.Error()
+				reply.Forbidden = errors.Is(err, server.ErrRconForbidden)
+			}
+			send(reply)
+		}(msg)
+	}
+}
@@ -1,21 +1,41 @@
 package handlers
 
 import (
+	"fmt"
+
 	"github.com/astral/kg-server-web-gui/internal/api/response"
 	"github.com/astral/kg-server-web-gui/internal/saves"
+	"github.com/astral/kg-server-web-gui/internal/server"
 	"github.com/gofiber/fiber/v2"
 )
 
 type SavesHandler struct {
-	manager *saves.SaveManager
+	instances *server.InstanceManager
+}
+
+func NewSavesHandler(im *server.InstanceManager) *SavesHandler {
+	return &SavesHandler{instances: im}
 }
 
-func NewSavesHandler(sm *saves.SaveManager) *SavesHandler {
-	return &SavesHandler{manager: sm}
+// manager returns the saves of the server in :id, or ?server= on the routes without one (default server if unset)
+func (h *SavesHandler) manager(c *fiber.Ctx) (*saves.SaveManager, error) {
+	id := c.Params("id")
+	if id == "" {
+		id = c.Query("server", "default")
+	}
+	if h.instances.Get(id) == nil {
+		return nil, fmt.Errorf("서버를 찾을 수 없습니다: %s", id)
+	}
+	ws := h.instances.Workspace(id)
+	return saves.NewSaveManager(ws.SavesDir, ws.SaveBackupsDir), nil
 }
 
 func (h *SavesHandler) ListSaves(c *fiber.Ctx) error {
-	list, err := h.manager.ListSaves()
+	sm, err := h.manager(c)
+	if err != nil {
+		return c.Status(404).JSON(response.Error(err.Error()))
+	}
+	list, err := sm.ListSaves()
 	if err != nil {
 		return c.Status(500).JSON(response.Error(err.Error()))
 	}
@@ -23,7 +43,11 @@ func (h *SavesHandler) ListSaves(c *fiber.Ctx) error {
 }
 
 func (h *SavesHandler) ListBackups(c *fiber.Ctx) error {
-	list, err := h.manager.ListBackups()
+	sm, err := h.manager(c)
+	if err != nil {
+		return c.Status(404).JSON(response.Error(err.Error()))
+	}
+	list, err := sm.ListBackups()
 	if err != nil {
 		return c.Status(500).JSON(response.Error(err.Error()))
 	}
@@ -31,6 +55,10 @@ func (h *SavesHandler) ListBackups(c *fiber.Ctx) error {
 }
 
 func (h *SavesHandler) CreateBackup(c *fiber.Ctx) error {
+	sm, err := h.manager(c)
+	if err != nil {
+		return c.Status(404).JSON(response.Error(err.Error()))
+	}
 	var req struct {
 		SaveName string `json:"saveName"`
 	}
@@ -38,7 +66,7 @@ func (h *SavesHandler) CreateBackup(c *fiber.Ctx) error {
 		return c.Status(400).JSON(response.Error(err.Error()))
 	}
 
-	path, err := h.manager.CreateBackup(req.SaveName)
+	path, err := sm.CreateBackup(req.SaveName)
 	if err != nil {
 		return c.Status(500).JSON(response.Error(err.Error()))
 	}
@@ -47,6 +75,10 @@ func (h *SavesHandler) CreateBackup(c *fiber.Ctx) error {
 }
 
 func (h *SavesHandler) RestoreBackup(c *fiber.Ctx) error {
+	sm, err := h.manager(c)
+	if err != nil {
+		return c.Status(404).JSON(response.Error(err.Error()))
+	}
 	var req struct {
 		BackupName string `json:"backupName"`
 	}
@@ -54,7 +86,7 @@ func (h *SavesHandler) RestoreBackup(c *fiber.Ctx) error {
 		return c.Status(400).JSON(response.Error(err.Error()))
 	}
 
-	if err := h.manager.RestoreBackup(req.BackupName); err != nil {
+	if err := sm.RestoreBackup(req.BackupName); err != nil {
 		return c.Status(500).JSON(response.Error(err.Error()))
 	}
 
@@ -62,10 +94,14 @@ func (h *SavesHandler) RestoreBackup(c *fiber.Ctx) error {
 }
 
 func (h *SavesHandler) DeleteSave(c *fiber.Ctx) error {
+	sm, err := h.manager(c)
+	if err != nil {
+		return c.Status(404).JSON(response.Error(err.Error()))
+	}
 	name := c.Params("name")
 	isBackup := c.Query("backup") == "true"
 
-	if err := h.manager.DeleteSave(name, isBackup); err != nil {
+	if err := sm.DeleteSave(name, isBackup); err != nil {
 		return c.Status(500).JSON(response.Error(err.Error()))
 	}
 
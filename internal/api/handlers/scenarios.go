@@ -18,7 +18,7 @@ func (h *ApiHandlers) ListScenarios(c *fiber.Ctx) error {
 	addonsPath := c.Query("addonsPath", settings.AddonsPath)
 
 	if serverPath == "" {
-		serverPath = "ArmaReforgerServer.exe"
+		serverPath = agent.ServerBinaryName
 	}
 
 	// Support multiple addon paths? usually simple csv or multiple params
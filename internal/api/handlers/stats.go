@@ -5,15 +5,17 @@ import (
 
 	"github.com/astral/kg-server-web-gui/internal/api/response"
 	"github.com/astral/kg-server-web-gui/internal/metrics"
+	"github.com/astral/kg-server-web-gui/internal/server"
 	"github.com/gofiber/fiber/v2"
 )
 
 type StatsHandler struct {
-	manager *metrics.Manager
+	manager     *metrics.Manager
+	instanceMgr *server.InstanceManager
 }
 
-func NewStatsHandler(manager *metrics.Manager) *StatsHandler {
-	return &StatsHandler{manager: manager}
+func NewStatsHandler(manager *metrics.Manager, im *server.InstanceManager) *StatsHandler {
+	return &StatsHandler{manager: manager, instanceMgr: im}
 }
 
 // GetHistory returns metrics for a specific instance and date
@@ -29,37 +31,17 @@ func (h *StatsHandler) GetHistory(c *fiber.Ctx) error {
 	return c.JSON(response.Success(points))
 }
 
-// GetUptime returns basic uptime statistics
+// GetUptime returns uptime statistics computed from the instance's lifecycle history
 func (h *StatsHandler) GetUptime(c *fiber.Ctx) error {
 	id := c.Query("id", "default")
-	// For simplicity, we calculate uptime from today's metrics
-	// Uptime = points where CPU/RAM > 0
-	date := time.Now().Format("2006-01-02")
-	points, err := h.manager.GetHistory(id, date)
-	if err != nil {
-		return c.Status(500).JSON(response.Error(err.Error()))
-	}
-
-	if len(points) == 0 {
-		return c.JSON(response.Success(fiber.Map{
-			"uptimePercent": 0,
-			"points":        0,
-			"online":        0,
-		}))
+	hours := c.QueryInt("hours", 24)
+	if hours <= 0 {
+		hours = 24
 	}
 
-	onlineCount := 0
-	for _, p := range points {
-		if p.CPU > 0 || p.RAM > 0 {
-			onlineCount++
-		}
+	stats, err := h.instanceMgr.Uptime(id, time.Now().Add(-time.Duration(hours)*time.Hour))
+	if err != nil {
+		return c.Status(404).JSON(response.Error(err.Error()))
 	}
-
-	uptimePercent := (float64(onlineCount) / float64(len(points))) * 100
-
-	return c.JSON(response.Success(fiber.Map{
-		"uptimePercent": uptimePercent,
-		"totalPoints":   len(points),
-		"onlinePoints":  onlineCount,
-	}))
+	return c.JSON(response.Success(stats))
 }
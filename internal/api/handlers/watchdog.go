@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"github.com/astral/kg-server-web-gui/internal/agent"
+	"github.com/astral/kg-server-web-gui/internal/api/response"
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetRestartStatus returns the watchdog restart policy of a server and its current state
+func (h *ApiHandlers) GetRestartStatus(c *fiber.Ctx) error {
+	status, err := h.Manager.RestartStatus(c.Params("id"))
+	if err != nil {
+		return c.Status(404).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(response.Success(status))
+}
+
+// UpdateRestartPolicy replaces the restart policy of a server; an empty body restores the defaults
+func (h *ApiHandlers) UpdateRestartPolicy(c *fiber.Ctx) error {
+	var policy *agent.RestartPolicy
+	if len(c.Body()) > 0 {
+		policy = &agent.RestartPolicy{}
+		if err := c.BodyParser(policy); err != nil {
+			return c.Status(400).JSON(response.Error(err.Error()))
+		}
+	}
+
+	id := c.Params("id")
+	if err := h.Manager.SetRestartPolicy(id, policy); err != nil {
+		return c.Status(400).JSON(response.Error(err.Error()))
+	}
+	status, _ := h.Manager.RestartStatus(id)
+	return c.JSON(response.Success(status))
+}
+
+// ResetRestarts clears the restart attempts of a server the watchdog gave up on
+func (h *ApiHandlers) ResetRestarts(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if err := h.Manager.ResetRestarts(id, RequestTrigger(c)); err != nil {
+		return c.Status(404).JSON(response.Error(err.Error()))
+	}
+	status, _ := h.Manager.RestartStatus(id)
+	return c.JSON(response.Success(status))
+}
+
+// GetLastGood returns the config snapshot a server last ran healthy with
+func (h *ApiHandlers) GetLastGood(c *fiber.Ctx) error {
+	snap, err := h.Manager.LastGood(c.Params("id"))
+	if err != nil {
+		return c.Status(404).JSON(response.Error(err.Error()))
+	}
+	if snap == nil {
+		return c.Status(404).JSON(response.Error("정상 동작 설정 기록이 없습니다"))
+	}
+	return c.JSON(response.Success(snap))
+}
@@ -13,18 +13,19 @@ import (
 	"github.com/astral/kg-server-web-gui/internal/auth"
 	"github.com/astral/kg-server-web-gui/internal/config"
 	"github.com/astral/kg-server-web-gui/internal/discord"
+	"github.com/astral/kg-server-web-gui/internal/group"
 	"github.com/astral/kg-server-web-gui/internal/logs"
 	"github.com/astral/kg-server-web-gui/internal/mapchange"
 	"github.com/astral/kg-server-web-gui/internal/metrics"
 	"github.com/astral/kg-server-web-gui/internal/preset"
 	"github.com/astral/kg-server-web-gui/internal/profile"
 	"github.com/astral/kg-server-web-gui/internal/rcon"
-	"github.com/astral/kg-server-web-gui/internal/saves"
 	"github.com/astral/kg-server-web-gui/internal/scheduler"
 	"github.com/astral/kg-server-web-gui/internal/server"
 	"github.com/astral/kg-server-web-gui/internal/settings"
 	"github.com/astral/kg-server-web-gui/internal/steamcmd"
 	"github.com/astral/kg-server-web-gui/internal/workshop"
+	"github.com/gofiber/contrib/websocket"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 )
@@ -33,17 +34,11 @@ func SetupRoutes(app *fiber.App) {
 	// Get working directory for absolute paths
 	workDir, _ := os.Getwd()
 	dataPath := filepath.Join(workDir, "data")
-	profilePath := filepath.Join(workDir, "profile")
-	savesPath := filepath.Join(profilePath, "Saves") // Arma Reforger saves inside profile/Saves
-	backupsPath := filepath.Join(workDir, "backups")
 	serverPath := filepath.Join(workDir, "server")
 	addonsPath := filepath.Join(workDir, "addons")
 
-	// Ensure directories exist
-	os.MkdirAll(profilePath, 0755)
-	os.MkdirAll(savesPath, 0755)
+	// Ensure directories exist (profiles, saves and backups live in each server's workspace)
 	os.MkdirAll(addonsPath, 0755)
-	os.MkdirAll(backupsPath, 0755)
 
 	// Initialize managers
 	userManager := auth.NewUserManager(dataPath)
@@ -57,26 +52,18 @@ func SetupRoutes(app *fiber.App) {
 		currSettings.AddonsPath = addonsPath
 		needsUpdate = true
 	}
-	if currSettings.ProfilesPath == "" {
-		currSettings.ProfilesPath = profilePath
-		needsUpdate = true
-	}
 	if needsUpdate {
 		settingsMgr.Update(currSettings)
 	}
 
 	// Initialize Phase 1 components
 	discordWebhook := agent.NewDiscordClient(currSettings.DiscordWebhookURL)
-	proc := agent.NewProcessMonitor("ArmaReforgerServer.exe")
-	proc.SetServerPath(currSettings.ServerPath) // Ensure path is set if available
-
 	wd := agent.NewWatchdog(discordWebhook, dataPath)
 	wd.SetEnabled(currSettings.EnableWatchdog)
 
 	instanceMgr := server.NewInstanceManager(dataPath, settingsMgr, wd, discordWebhook)
 	cfg := config.NewConfigManager()
 	pm := profile.NewProfileManager(dataPath)
-	sm := saves.NewSaveManager(savesPath, backupsPath)
 	steamcmdMgr := steamcmd.NewManager(workDir, serverPath)
 	presetMgr := preset.NewPresetManager(dataPath)
 	collectionMgr := workshop.NewCollectionManager(dataPath)
@@ -118,6 +105,9 @@ func SetupRoutes(app *fiber.App) {
 	schedulerMgr := scheduler.NewManager(dataPath, instanceMgr, mapService, discordWebhook)
 	schedulerMgr.Start()
 
+	// Initialize server groups
+	groupMgr := group.NewManager(dataPath, instanceMgr)
+
 	// Initialize Metrics
 	metricsMgr := metrics.NewManager(dataPath, instanceMgr)
 	metricsMgr.Start()
@@ -132,12 +122,13 @@ func SetupRoutes(app *fiber.App) {
 
 	baseHandlers := handlers.NewApiHandlers(instanceMgr, cfg, settingsMgr, wd, discordWebhook)
 	profileHandler := handlers.NewProfileHandler(pm)
-	savesHandler := handlers.NewSavesHandler(sm)
+	savesHandler := handlers.NewSavesHandler(instanceMgr)
 	collectionHandler := handlers.NewCollectionHandler(collectionMgr)
 	modCategoryHandler := handlers.NewModCategoryHandler(dataPath)
 	mapHandler := handlers.NewMapHandler(mapService)
 	schedulerHandler := handlers.NewSchedulerHandler(schedulerMgr)
-	statsHandler := handlers.NewStatsHandler(metricsMgr)
+	statsHandler := handlers.NewStatsHandler(metricsMgr, instanceMgr)
+	groupHandler := handlers.NewGroupHandler(groupMgr)
 
 	api := app.Group("/api")
 
@@ -183,6 +174,9 @@ func SetupRoutes(app *fiber.App) {
 	api.Get("/servers", func(c *fiber.Ctx) error {
 		return c.JSON(response.Success(instanceMgr.List()))
 	})
+	api.Get("/ports", func(c *fiber.Ctx) error {
+		return c.JSON(response.Success(instanceMgr.Ports()))
+	})
 	api.Get("/servers/:id", func(c *fiber.Ctx) error {
 		inst := instanceMgr.Get(c.Params("id"))
 		if inst == nil {
@@ -216,6 +210,14 @@ func SetupRoutes(app *fiber.App) {
 		}
 		return c.JSON(response.Success(fiber.Map{"status": "deleted"}))
 	})
+	api.Post("/servers/:id/clone", baseHandlers.CloneServer)
+	api.Get("/servers/:id/maintenance", baseHandlers.GetMaintenance)
+	api.Put("/servers/:id/maintenance", baseHandlers.SetMaintenance)
+	api.Delete("/servers/:id/maintenance", baseHandlers.ClearMaintenance)
+	api.Put("/servers/:id/node", auth.AdminMiddleware(), baseHandlers.BindServerNode)
+	api.Get("/servers/:id/advanced", baseHandlers.GetAdvancedSettings)
+	api.Put("/servers/:id/advanced", baseHandlers.SetAdvancedSettings)
+	api.Get("/servers/:id/cmdline", baseHandlers.GetCommandLine)
 	api.Post("/servers/:id/start", func(c *fiber.Ctx) error {
 		id := c.Params("id")
 		var req struct {
@@ -227,18 +229,68 @@ func SetupRoutes(app *fiber.App) {
 
 		logs.GlobalLogs.Info(fmt.Sprintf("[%s] Starting with args: %v", id, req.Args))
 
-		if err := instanceMgr.Start(id, req.Args); err != nil {
-			return c.Status(500).JSON(response.Error(err.Error()))
+		if err := instanceMgr.StartBy(id, req.Args, handlers.RequestTrigger(c)); err != nil {
+			return c.Status(handlers.ErrorStatus(err, 500)).JSON(response.Error(err.Error()))
 		}
 		return c.JSON(response.Success(fiber.Map{"status": "started"}))
 	})
 	api.Post("/servers/:id/stop", func(c *fiber.Ctx) error {
-		if err := instanceMgr.Stop(c.Params("id")); err != nil {
-			return c.Status(500).JSON(response.Error(err.Error()))
+		opts := server.ShutdownOptions{Trigger: handlers.RequestTrigger(c)}
+		if err := instanceMgr.GracefulStop(c.Params("id"), opts); err != nil {
+			return c.Status(handlers.ErrorStatus(err, 500)).JSON(response.Error(err.Error()))
 		}
 		return c.JSON(response.Success(fiber.Map{"status": "stopped"}))
 	})
+	api.Post("/servers/:id/restart", func(c *fiber.Ctx) error {
+		id := c.Params("id")
+		var req server.ShutdownOptions
+		c.BodyParser(&req)
+		if req.Reason == "" {
+			req.Reason = "수동 재시작"
+		}
+		req.Restart = true
+		req.Trigger = handlers.RequestTrigger(c)
+
+		// With a countdown the restart runs in the background and can be followed/cancelled via /shutdown
+		if req.Countdown {
+			status, err := instanceMgr.BeginShutdown(id, req)
+			if err != nil {
+				return c.Status(handlers.ErrorStatus(err, 409)).JSON(response.Error(err.Error()))
+			}
+			return c.Status(202).JSON(response.Success(status))
+		}
+		if err := instanceMgr.Restart(id, req); err != nil {
+			return c.Status(handlers.ErrorStatus(err, 500)).JSON(response.Error(err.Error()))
+		}
+		return c.JSON(response.Success(fiber.Map{"status": "restarted"}))
+	})
+	api.Post("/servers/:id/shutdown", func(c *fiber.Ctx) error {
+		req := server.ShutdownOptions{Countdown: true}
+		if len(c.Body()) > 0 {
+			if err := c.BodyParser(&req); err != nil {
+				return c.Status(400).JSON(response.Error(err.Error()))
+			}
+		}
+		req.Trigger = handlers.RequestTrigger(c)
+		status, err := instanceMgr.BeginShutdown(c.Params("id"), req)
+		if err != nil {
+			return c.Status(handlers.ErrorStatus(err, 409)).JSON(response.Error(err.Error()))
+		}
+		return c.Status(202).JSON(response.Success(status))
+	})
+	api.Get("/servers/:id/shutdown", func(c *fiber.Ctx) error {
+		return c.JSON(response.Success(instanceMgr.GetShutdownStatus(c.Params("id"))))
+	})
+	api.Delete("/servers/:id/shutdown", func(c *fiber.Ctx) error {
+		if err := instanceMgr.CancelShutdown(c.Params("id")); err != nil {
+			return c.Status(409).JSON(response.Error(err.Error()))
+		}
+		return c.JSON(response.Success(fiber.Map{"status": "cancelled"}))
+	})
 	api.Post("/servers/:id/rcon", baseHandlers.SendRcon)
+	api.Get("/servers/:id/rcon/status", baseHandlers.GetRconStatus)
+	api.Get("/servers/:id/rcon/messages", baseHandlers.GetRconMessages)
+	api.Get("/servers/:id/rcon/ws", baseHandlers.RconConsoleUpgrade, websocket.New(baseHandlers.RconConsole))
 	api.Get("/servers/:id/metrics", func(c *fiber.Ctx) error {
 		metrics, err := instanceMgr.GetServerMetrics(c.Params("id"))
 		if err != nil {
@@ -249,6 +301,27 @@ func SetupRoutes(app *fiber.App) {
 	api.Get("/servers/:id/players", baseHandlers.GetPlayers)
 	api.Post("/servers/:id/kick", baseHandlers.KickPlayer)
 	api.Post("/servers/:id/ban", baseHandlers.BanPlayer)
+	api.Get("/servers/:id/bans", baseHandlers.ListServerBans)
+	api.Post("/servers/:id/bans", baseHandlers.AddServerBan)
+	api.Post("/servers/:id/bans/load", baseHandlers.ReloadServerBans)
+	api.Post("/servers/:id/bans/write", baseHandlers.WriteServerBans)
+	api.Put("/servers/:id/bans/shared", baseHandlers.SetSharedBans)
+	api.Delete("/servers/:id/bans/:target", baseHandlers.RemoveServerBan)
+	api.Get("/bans", baseHandlers.SearchBans)
+	api.Post("/bans", baseHandlers.AddGlobalBan)
+	api.Get("/bans/export", baseHandlers.ExportBans)
+	api.Post("/bans/import", baseHandlers.ImportBans)
+	api.Delete("/bans/:target", baseHandlers.RemoveGlobalBan)
+	api.Get("/servers/:id/events", baseHandlers.ListEvents)
+	api.Get("/servers/:id/watchdog", baseHandlers.GetRestartStatus)
+	api.Put("/servers/:id/watchdog", baseHandlers.UpdateRestartPolicy)
+	api.Post("/servers/:id/watchdog/reset", baseHandlers.ResetRestarts)
+	api.Get("/servers/:id/lastgood", baseHandlers.GetLastGood)
+	api.Get("/servers/:id/crashes", baseHandlers.ListCrashBundles)
+	api.Get("/servers/:id/crashes/:bundle", baseHandlers.DownloadCrashBundle)
+	api.Get("/servers/:id/logs", baseHandlers.ListLogs)
+	api.Get("/servers/:id/logs/tail", baseHandlers.TailLog)
+	api.Get("/servers/:id/logs/:file", baseHandlers.DownloadLog)
 
 	// Legacy Status & Server Control (for backward compatibility)
 	api.Get("/status", baseHandlers.GetStatus)
@@ -261,32 +334,27 @@ func SetupRoutes(app *fiber.App) {
 		c.BodyParser(&req)
 		logs.GlobalLogs.Info("서버 시작 요청")
 
-		if err := instanceMgr.Start("default", req.Args); err != nil {
+		if err := instanceMgr.StartBy("default", req.Args, handlers.RequestTrigger(c)); err != nil {
 			logs.GlobalLogs.Error("서버 시작 실패: " + err.Error())
-			return c.Status(500).JSON(response.Error(err.Error()))
+			return c.Status(handlers.ErrorStatus(err, 500)).JSON(response.Error(err.Error()))
 		}
 		return c.JSON(response.Success(fiber.Map{"status": "started"}))
 	})
 	api.Post("/server/stop", func(c *fiber.Ctx) error {
 		logs.GlobalLogs.Info("서버 중지 요청")
-		if err := instanceMgr.Stop("default"); err != nil {
+		if err := instanceMgr.GracefulStop("default", server.ShutdownOptions{Trigger: handlers.RequestTrigger(c)}); err != nil {
 			logs.GlobalLogs.Error("서버 중지 실패: " + err.Error())
-			return c.Status(500).JSON(response.Error(err.Error()))
+			return c.Status(handlers.ErrorStatus(err, 500)).JSON(response.Error(err.Error()))
 		}
 		return c.JSON(response.Success(fiber.Map{"status": "stopped"}))
 	})
 	api.Post("/server/restart", func(c *fiber.Ctx) error {
 		logs.GlobalLogs.Info("서버 재시작 요청")
 
-		// Stop the server first
-		instanceMgr.Stop("default")
-
-		// Wait briefly for process to stop
-		time.Sleep(2 * time.Second)
-
-		if err := instanceMgr.Start("default", []string{}); err != nil {
+		opts := server.ShutdownOptions{Reason: "수동 재시작", Trigger: handlers.RequestTrigger(c)}
+		if err := instanceMgr.Restart("default", opts); err != nil {
 			logs.GlobalLogs.Error("서버 재시작 실패: " + err.Error())
-			return c.Status(500).JSON(response.Error(err.Error()))
+			return c.Status(handlers.ErrorStatus(err, 500)).JSON(response.Error(err.Error()))
 		}
 
 		return c.JSON(response.Success(fiber.Map{"status": "restarted"}))
@@ -326,6 +394,29 @@ func SetupRoutes(app *fiber.App) {
 	api.Post("/maps/apply", mapHandler.ApplyMapByScenario)
 	api.Get("/servers/:id/map", mapHandler.GetCurrentMap)
 
+	// Agent nodes (servers on other hosts, admin only)
+	nodesApi := api.Group("/nodes", auth.AdminMiddleware())
+	nodesApi.Get("/", baseHandlers.ListNodes)
+	nodesApi.Post("/", baseHandlers.AddNode)
+	nodesApi.Get("/:id", baseHandlers.GetNode)
+	nodesApi.Delete("/:id", baseHandlers.DeleteNode)
+	nodesApi.Get("/:id/files", baseHandlers.GetNodeFile)
+	nodesApi.Put("/:id/files", baseHandlers.PutNodeFile)
+	nodesApi.Get("/:id/steamcmd", baseHandlers.GetNodeSteamCMD)
+	nodesApi.Post("/:id/steamcmd/update", baseHandlers.UpdateNodeServer)
+
+	// Server groups
+	api.Get("/groups", groupHandler.ListGroups)
+	api.Post("/groups", groupHandler.AddGroup)
+	api.Get("/groups/:id", groupHandler.GetGroup)
+	api.Put("/groups/:id", groupHandler.UpdateGroup)
+	api.Delete("/groups/:id", groupHandler.DeleteGroup)
+	api.Post("/groups/:id/start", groupHandler.RunAction(group.ActionStart))
+	api.Post("/groups/:id/stop", groupHandler.RunAction(group.ActionStop))
+	api.Post("/groups/:id/restart", groupHandler.RunAction(group.ActionRestart))
+	api.Get("/groups/:id/operation", groupHandler.GetOperation)
+	api.Delete("/groups/:id/operation", groupHandler.CancelOperation)
+
 	// Scheduler
 	api.Get("/jobs", schedulerHandler.ListJobs)
 	api.Post("/jobs", schedulerHandler.AddJob)
@@ -337,6 +428,7 @@ func SetupRoutes(app *fiber.App) {
 	api.Get("/stats/uptime", statsHandler.GetUptime)
 	api.Get("/crashes", baseHandlers.GetCrashes)
 	api.Get("/commands/history", baseHandlers.GetCommandHistory)
+	api.Get("/rcon/history", baseHandlers.GetRconHistory)
 
 	// Profiles
 	api.Get("/profiles", profileHandler.List)
@@ -346,7 +438,12 @@ func SetupRoutes(app *fiber.App) {
 	api.Delete("/profiles/:id", profileHandler.Delete)
 	api.Post("/profiles/:id/activate", profileHandler.SetActive)
 
-	// Saves
+	// Saves (per server; /saves takes ?server= and defaults to the default server)
+	api.Get("/servers/:id/saves", savesHandler.ListSaves)
+	api.Get("/servers/:id/saves/backups", savesHandler.ListBackups)
+	api.Post("/servers/:id/saves/backup", savesHandler.CreateBackup)
+	api.Post("/servers/:id/saves/restore", savesHandler.RestoreBackup)
+	api.Delete("/servers/:id/saves/:name", savesHandler.DeleteSave)
 	api.Get("/saves", savesHandler.ListSaves)
 	api.Get("/saves/backups", savesHandler.ListBackups)
 	api.Post("/saves/backup", savesHandler.CreateBackup)
@@ -361,7 +458,37 @@ func SetupRoutes(app *fiber.App) {
 		}
 		c.BodyParser(&req)
 
-		go steamcmdMgr.DownloadServer(req.Experimental)
+		if req.ServerID == "" {
+			go steamcmdMgr.DownloadServer(req.Experimental)
+			return c.JSON(response.Success(fiber.Map{"status": "다운로드 시작됨"}))
+		}
+
+		// Updating a server in use: bring it down with the countdown, update, then start it again
+		inst := instanceMgr.Get(req.ServerID)
+		if inst == nil {
+			return c.Status(404).JSON(response.Error("서버를 찾을 수 없습니다"))
+		}
+		wasRunning := inst.Status == server.StatusRunning || inst.Status == server.StatusStarting
+		by := handlers.RequestTrigger(c)
+		go func() {
+			if wasRunning {
+				err := instanceMgr.GracefulStop(req.ServerID, server.ShutdownOptions{Reason: "서버 업데이트", Countdown: true, Trigger: by})
+				if err != nil {
+					logs.GlobalLogs.Warn(fmt.Sprintf("[%s] 업데이트 전 서버 중지 실패: %v", req.ServerID, err))
+					return
+				}
+			}
+			instanceMgr.SetUpdating(req.ServerID, true, by)
+			if err := steamcmdMgr.DownloadServer(req.Experimental); err != nil {
+				logs.GlobalLogs.Error(fmt.Sprintf("[%s] 서버 업데이트 실패: %v", req.ServerID, err))
+			}
+			instanceMgr.SetUpdating(req.ServerID, false, by)
+			if wasRunning {
+				if err := instanceMgr.StartBy(req.ServerID, nil, by); err != nil {
+					logs.GlobalLogs.Error(fmt.Sprintf("[%s] 업데이트 후 서버 시작 실패: %v", req.ServerID, err))
+				}
+			}
+		}()
 		return c.JSON(response.Success(fiber.Map{"status": "다운로드 시작됨"}))
 	})
 	api.Get("/steamcmd/status", func(c *fiber.Ctx) error {
@@ -421,7 +548,7 @@ func SetupRoutes(app *fiber.App) {
 		}
 
 		// Apply preset config - convert map to ServerConfig
-		configPath := filepath.Join(workDir, "server.json")
+		configPath := instanceMgr.ConfigPath("default")
 
 		// Marshal map to JSON, then unmarshal to ServerConfig
 		configBytes, err := json.Marshal(p.Config)
@@ -0,0 +1,72 @@
+package battleye
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FileBan is an entry of BattlEye's bans.txt: "<GUID or IP> <expiry as unix time, -1 = permanent> [reason]"
+type FileBan struct {
+	Target  string    `json:"target"`
+	Kind    string    `json:"kind"`
+	Expires time.Time `json:"expires"` // Zero = permanent
+	Reason  string    `json:"reason,omitempty"`
+}
+
+// Permanent reports whether the ban never expires
+func (b FileBan) Permanent() bool { return b.Expires.IsZero() }
+
+// ParseBansFile reads bans.txt; lines that are not a valid ban are returned by line number in skipped
+func ParseBansFile(data string) (bans []FileBan, skipped []int) {
+	bans = []FileBan{}
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "//") {
+			continue
+		}
+		fields := strings.SplitN(line, " ", 3)
+		if len(fields) < 2 {
+			skipped = append(skipped, n)
+			continue
+		}
+		kind, err := BanKind(fields[0])
+		expiry, convErr := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil || convErr != nil {
+			skipped = append(skipped, n)
+			continue
+		}
+		b := FileBan{Target: fields[0], Kind: kind}
+		if kind == BanGUID {
+			b.Target = strings.ToLower(b.Target)
+		}
+		if expiry > 0 {
+			b.Expires = time.Unix(expiry, 0)
+		}
+		if len(fields) == 3 {
+			b.Reason = strings.TrimSpace(fields[2])
+		}
+		bans = append(bans, b)
+	}
+	return bans, skipped
+}
+
+// FormatBansFile writes bans in the bans.txt format
+func FormatBansFile(bans []FileBan) string {
+	var sb strings.Builder
+	for _, b := range bans {
+		expiry := int64(-1)
+		if !b.Permanent() {
+			expiry = b.Expires.Unix()
+		}
+		line := fmt.Sprintf("%s %d", b.Target, expiry)
+		if reason := singleLine(b.Reason); reason != "" {
+			line += " " + reason
+		}
+		sb.WriteString(line + "\n")
+	}
+	return sb.String()
+}
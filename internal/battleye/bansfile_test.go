@@ -0,0 +1,51 @@
+package battleye
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseBansFile(t *testing.T) {
+	bans, skipped := ParseBansFile(fixture(t, "bans_file.txt"))
+	want := []FileBan{
+		{Target: "5d7c1bde0e6f4d1f8b2a9c3e4f5a6b7c", Kind: BanGUID, Reason: "Cheating (aimbot)"},
+		{Target: "0a1b2c3d4e5f60718293a4b5c6d7e8f9", Kind: BanGUID, Expires: time.Unix(1767225600, 0), Reason: "Teamkilling"},
+		{Target: "203.0.113.7", Kind: BanIP},
+		{Target: "ffeeddccbbaa99887766554433221100", Kind: BanGUID},
+	}
+	if !reflect.DeepEqual(bans, want) {
+		t.Errorf("ParseBansFile() =\n%+v\nwant\n%+v", bans, want)
+	}
+	if !reflect.DeepEqual(skipped, []int{5, 6}) {
+		t.Errorf("skipped = %v, want [5 6]", skipped)
+	}
+}
+
+func TestFormatBansFile(t *testing.T) {
+	tests := []struct {
+		name string
+		bans []FileBan
+		want string
+	}{
+		{"empty", nil, ""},
+		{"permanent", []FileBan{{Target: "203.0.113.7", Reason: "VPN"}}, "203.0.113.7 -1 VPN\n"},
+		{"timed without reason", []FileBan{{Target: "0a1b2c3d4e5f60718293a4b5c6d7e8f9", Expires: time.Unix(1767225600, 0)}}, "0a1b2c3d4e5f60718293a4b5c6d7e8f9 1767225600\n"},
+		{"reason on one line", []FileBan{{Target: "203.0.113.7", Reason: "a\nb"}}, "203.0.113.7 -1 a b\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatBansFile(tt.bans); got != tt.want {
+				t.Errorf("FormatBansFile() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBansFileRoundTrip(t *testing.T) {
+	bans, _ := ParseBansFile(fixture(t, "bans_file.txt"))
+	again, skipped := ParseBansFile(FormatBansFile(bans))
+	if len(skipped) > 0 || !reflect.DeepEqual(again, bans) {
+		t.Errorf("round trip = %+v (skipped %v), want %+v", again, skipped, bans)
+	}
+}
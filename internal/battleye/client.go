@@ -0,0 +1,255 @@
+package battleye
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BattlEye RCON packet types
+const (
+	packetLogin   byte = 0x00
+	packetCommand byte = 0x01
+	packetMessage byte = 0x02
+)
+
+const (
+	// resendInterval is how often an unanswered command is sent again under the same sequence number
+	resendInterval = 2 * time.Second
+	messageBuffer  = 500
+)
+
+// ErrLoginFailed is returned when the server rejects the RCON password
+var ErrLoginFailed = errors.New("RCON 로그인 실패 (비밀번호를 확인하세요)")
+
+// message is a server message with the sequence number BattlEye sent it under
+type message struct {
+	Seq  byte
+	Text string
+}
+
+// reply is one command response packet, possibly a part of a split response
+type reply struct {
+	seq   byte
+	multi bool
+	parts byte
+	index byte
+	text  string
+}
+
+// client is a BattlEye RCON connection. Unlike go-battleye's client it hands server messages over
+// together with their sequence number, so a message BattlEye resends after a lost acknowledgement
+// can be told apart from a new message with the same text.
+type client struct {
+	conn    net.Conn
+	timeout time.Duration
+
+	execMu sync.Mutex // One command in flight at a time
+	seq    byte
+
+	login    chan bool
+	replies  chan reply
+	messages chan message
+	done     chan struct{}
+	once     sync.Once
+
+	errMu sync.Mutex
+	err   error
+}
+
+// dialClient connects and logs in to the BattlEye RCON server at address
+func dialClient(address, password string, timeout time.Duration) (*client, error) {
+	conn, err := net.Dial("udp", address)
+	if err != nil {
+		return nil, err
+	}
+	c := &client{
+		conn:     conn,
+		timeout:  timeout,
+		login:    make(chan bool, 1),
+		replies:  make(chan reply, 16),
+		messages: make(chan message, messageBuffer),
+		done:     make(chan struct{}),
+	}
+	go c.receive()
+
+	if err := c.write(packetLogin, []byte(password)); err != nil {
+		c.Close()
+		return nil, err
+	}
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+	select {
+	case ok := <-c.login:
+		if !ok {
+			c.Close()
+			return nil, ErrLoginFailed
+		}
+		return c, nil
+	case <-c.done:
+		return nil, c.error()
+	case <-t.C:
+		c.Close()
+		return nil, ErrCommandTimeout
+	}
+}
+
+// Messages returns the server messages; the channel is closed when the connection ends
+func (c *client) Messages() <-chan message { return c.messages }
+
+// Exec runs a command and waits for its complete response
+func (c *client) Exec(cmd string) (string, error) {
+	c.execMu.Lock()
+	defer c.execMu.Unlock()
+	seq := c.seq
+	c.seq++
+
+	payload := append([]byte{seq}, cmd...)
+	if err := c.write(packetCommand, payload); err != nil {
+		return "", err
+	}
+	deadline := time.NewTimer(c.timeout)
+	defer deadline.Stop()
+	resend := time.NewTicker(resendInterval)
+	defer resend.Stop()
+
+	var parts []string
+	var got []bool
+	received := 0
+	for {
+		select {
+		case r := <-c.replies:
+			if r.seq != seq {
+				continue // Late answer to a command that already timed out
+			}
+			if !r.multi {
+				return r.text, nil
+			}
+			if parts == nil {
+				parts, got = make([]string, r.parts), make([]bool, r.parts)
+			}
+			if int(r.index) >= len(parts) || got[r.index] {
+				continue
+			}
+			parts[r.index], got[r.index] = r.text, true
+			if received++; received == len(parts) {
+				return strings.Join(parts, ""), nil
+			}
+		case <-resend.C:
+			if err := c.write(packetCommand, payload); err != nil {
+				return "", err
+			}
+		case <-deadline.C:
+			return "", ErrCommandTimeout
+		case <-c.done:
+			return "", c.error()
+		}
+	}
+}
+
+// Close ends the connection
+func (c *client) Close() error {
+	c.once.Do(func() {
+		close(c.done)
+		c.conn.Close()
+	})
+	return nil
+}
+
+func (c *client) fail(err error) {
+	c.errMu.Lock()
+	if c.err == nil {
+		c.err = err
+	}
+	c.errMu.Unlock()
+	c.Close()
+}
+
+func (c *client) error() error {
+	c.errMu.Lock()
+	defer c.errMu.Unlock()
+	if c.err == nil {
+		return ErrClosed
+	}
+	return c.err
+}
+
+// receive reads packets until the connection fails or is closed
+func (c *client) receive() {
+	defer close(c.messages)
+	buf := make([]byte, 64*1024)
+	for {
+		n, err := c.conn.Read(buf)
+		if err != nil {
+			c.fail(err)
+			return
+		}
+		kind, payload, ok := parsePacket(buf[:n])
+		if !ok || len(payload) == 0 {
+			continue // Corrupt or truncated datagram; BattlEye resends what matters
+		}
+
+		switch kind {
+		case packetLogin:
+			select {
+			case c.login <- payload[0] == 0x01:
+			default:
+			}
+		case packetCommand:
+			r := reply{seq: payload[0], text: string(payload[1:])}
+			if len(payload) >= 4 && payload[1] == 0x00 {
+				r.multi, r.parts, r.index, r.text = true, payload[2], payload[3], string(payload[4:])
+			}
+			select {
+			case c.replies <- r:
+			default: // Nobody waits for it
+			}
+		case packetMessage:
+			// Acknowledge only what was handed over; BattlEye resends the rest
+			select {
+			case c.messages <- message{Seq: payload[0], Text: string(payload[1:])}:
+				c.write(packetMessage, payload[:1])
+			default:
+			}
+		}
+	}
+}
+
+// write sends one packet: "BE", the CRC32 of the rest, 0xFF, the type and the payload
+func (c *client) write(kind byte, payload []byte) error {
+	body := append([]byte{0xFF, kind}, payload...)
+	packet := make([]byte, 6, 6+len(body))
+	packet[0], packet[1] = 'B', 'E'
+	binary.LittleEndian.PutUint32(packet[2:], crc32.ChecksumIEEE(body))
+	_, err := c.conn.Write(append(packet, body...))
+	return err
+}
+
+// parsePacket checks the header and checksum of a packet and returns its type and payload
+func parsePacket(raw []byte) (byte, []byte, bool) {
+	if len(raw) < 8 || raw[0] != 'B' || raw[1] != 'E' || raw[6] != 0xFF {
+		return 0, nil, false
+	}
+	if crc32.ChecksumIEEE(raw[6:]) != binary.LittleEndian.Uint32(raw[2:6]) {
+		return 0, nil, false
+	}
+	return raw[7], raw[8:], true
+}
+
+// seqWindow remembers which server message sequence numbers a connection has seen. BattlEye
+// counts them modulo 256, so marking one clears the number half a cycle ahead for its next use.
+type seqWindow [256]bool
+
+// add records seq and reports whether it is new
+func (w *seqWindow) add(seq byte) bool {
+	if w[seq] {
+		return false
+	}
+	w[seq] = true
+	w[seq+128] = false
+	return true
+}
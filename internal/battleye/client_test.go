@@ -0,0 +1,140 @@
+package battleye
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeServer is a BattlEye RCON server that accepts any password and answers commands in two parts
+type fakeServer struct {
+	conn *net.UDPConn
+	mu   sync.Mutex
+	peer *net.UDPAddr
+	acks chan byte
+}
+
+func newFakeServer(t *testing.T) *fakeServer {
+	t.Helper()
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	s := &fakeServer{conn: conn, acks: make(chan byte, 16)}
+	go s.serve()
+	return s
+}
+
+func (s *fakeServer) address() string { return s.conn.LocalAddr().String() }
+
+func (s *fakeServer) serve() {
+	buf := make([]byte, 4096)
+	for {
+		n, addr, err := s.conn.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+		kind, payload, ok := parsePacket(buf[:n])
+		if !ok {
+			continue
+		}
+		switch kind {
+		case packetLogin:
+			s.mu.Lock()
+			s.peer = addr
+			s.mu.Unlock()
+			s.send(packetLogin, []byte{0x01})
+		case packetCommand:
+			seq, cmd := payload[0], string(payload[1:])
+			s.send(packetCommand, append([]byte{seq, 0x00, 2, 1}, " "+cmd...))
+			s.send(packetCommand, append([]byte{seq, 0x00, 2, 0}, "ok:"...))
+		case packetMessage:
+			s.acks <- payload[0]
+		}
+	}
+}
+
+func (s *fakeServer) send(kind byte, payload []byte) {
+	body := append([]byte{0xFF, kind}, payload...)
+	packet := []byte{'B', 'E', 0, 0, 0, 0}
+	binary.LittleEndian.PutUint32(packet[2:], crc32.ChecksumIEEE(body))
+	s.mu.Lock()
+	peer := s.peer
+	s.mu.Unlock()
+	s.conn.WriteToUDP(append(packet, body...), peer)
+}
+
+func (s *fakeServer) push(seq byte, text string) {
+	s.send(packetMessage, append([]byte{seq}, text...))
+}
+
+func TestSessionSplitResponse(t *testing.T) {
+	srv := newFakeServer(t)
+	s := NewSession(srv.address(), "pw", Options{Timeout: time.Second})
+	defer s.Close()
+
+	got, err := s.Exec("players")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "ok: players" {
+		t.Errorf("Exec() = %q, want %q", got, "ok: players")
+	}
+}
+
+func TestSessionResentMessage(t *testing.T) {
+	srv := newFakeServer(t)
+	s := NewSession(srv.address(), "pw", Options{Timeout: time.Second})
+	defer s.Close()
+	msgs := make(chan string, 8)
+	s.Subscribe(func(msg string) { msgs <- msg })
+	if _, err := s.Exec(""); err != nil {
+		t.Fatal(err)
+	}
+
+	// The resent seq 0 is a lost acknowledgement; seq 1 is a second player typing the same thing
+	srv.push(0, "(Global) Ann: !map")
+	srv.push(0, "(Global) Ann: !map")
+	srv.push(1, "(Global) Ann: !map")
+	srv.push(2, "(Global) Bob: hi")
+
+	want := []string{"(Global) Ann: !map", "(Global) Ann: !map", "(Global) Bob: hi"}
+	for i, w := range want {
+		select {
+		case got := <-msgs:
+			if got != w {
+				t.Errorf("message %d = %q, want %q", i, got, w)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("message %d not published", i)
+		}
+	}
+	select {
+	case got := <-msgs:
+		t.Errorf("unexpected message %q", got)
+	case <-time.After(200 * time.Millisecond):
+	}
+	for i := 0; i < 4; i++ {
+		select {
+		case <-srv.acks:
+		case <-time.After(time.Second):
+			t.Fatalf("only %d of 4 messages acknowledged", i)
+		}
+	}
+}
+
+func TestSeqWindow(t *testing.T) {
+	var w seqWindow
+	for i := 0; i < 600; i++ {
+		if !w.add(byte(i)) {
+			t.Fatalf("seq %d (message %d) reported as seen", byte(i), i)
+		}
+		if w.add(byte(i)) {
+			t.Fatalf("resent seq %d (message %d) reported as new", byte(i), i)
+		}
+	}
+}
@@ -0,0 +1,312 @@
+package battleye
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// AllPlayers is the Say target that reaches everyone on the server
+const AllPlayers = -1
+
+// Ban kinds
+const (
+	BanGUID = "guid"
+	BanIP   = "ip"
+)
+
+// Executor runs one RCON command and returns the server's response; *Session implements it
+type Executor interface {
+	Exec(cmd string) (string, error)
+}
+
+// ExecFunc adapts a function to an Executor
+type ExecFunc func(cmd string) (string, error)
+
+func (f ExecFunc) Exec(cmd string) (string, error) { return f(cmd) }
+
+// Player is a row of the players command
+type Player struct {
+	Index    int    `json:"index"` // BE player number used by kick, ban and say
+	Name     string `json:"name"`
+	IP       string `json:"ip"`
+	Port     int    `json:"port"`
+	Ping     int    `json:"ping"` // -1 while unknown
+	GUID     string `json:"beguid"`
+	Verified bool   `json:"verified"` // The GUID was checked by the BattlEye master server
+	Lobby    bool   `json:"lobby"`    // Connected but not yet in the game
+}
+
+// Ban is a row of the bans command
+type Ban struct {
+	Index       int    `json:"index"` // Used by removeBan, shifts after every removal
+	Kind        string `json:"kind"`  // BanGUID or BanIP
+	Target      string `json:"target"`
+	MinutesLeft int    `json:"minutesLeft"` // -1 = permanent
+	Expired     bool   `json:"expired"`     // Kept until the next loadBans
+	Reason      string `json:"reason,omitempty"`
+}
+
+// Permanent reports whether the ban never expires
+func (b Ban) Permanent() bool { return b.MinutesLeft < 0 && !b.Expired }
+
+// Admin is a row of the admins command
+type Admin struct {
+	Index   int    `json:"index"`
+	Address string `json:"address"`
+}
+
+// ServerStatus holds the numbers of the status command
+type ServerStatus struct {
+	FPS        float64 `json:"fps"`
+	Players    int     `json:"players"`
+	MaxPlayers int     `json:"maxPlayers"`
+}
+
+var (
+	playerRow = regexp.MustCompile(`^(\d+)\s+([0-9a-fA-F.:\[\]]+?)(?::(\d+))?\s+(-?\d+)\s+([0-9a-fA-F]+|-)(?:\((OK|\?)\))?\s+(.*?)$`)
+	banRow    = regexp.MustCompile(`^(\d+)\s+(\S+)\s+(perm|-?\d+|-)(?:\s+(.*))?$`)
+	adminRow  = regexp.MustCompile(`^(\d+)\s+(\S+)$`)
+	fpsField  = regexp.MustCompile(`(?i)\bFPS:\s*([0-9.]+)`)
+	slotField = regexp.MustCompile(`(?i)\bPlayers:\s*(\d+)(?:\s*/\s*(\d+))?`)
+	guidValue = regexp.MustCompile(`^[0-9a-fA-F]{32}$`)
+)
+
+// ParsePlayers reads the output of the players command
+func ParsePlayers(out string) []Player {
+	players := []Player{}
+	for _, line := range tableRows(out) {
+		m := playerRow.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		p := Player{
+			Index:    atoi(m[1]),
+			IP:       m[2],
+			Port:     atoi(m[3]),
+			Ping:     atoi(m[4]),
+			Verified: m[6] == "OK",
+			Name:     m[7],
+		}
+		if m[5] != "-" {
+			p.GUID = strings.ToLower(m[5])
+		}
+		if name, ok := strings.CutSuffix(p.Name, " (Lobby)"); ok {
+			p.Name, p.Lobby = strings.TrimSpace(name), true
+		}
+		players = append(players, p)
+	}
+	return players
+}
+
+// ParseBans reads the output of the bans command, GUID and IP sections alike
+func ParseBans(out string) []Ban {
+	bans := []Ban{}
+	kind := BanGUID
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(line, "GUID Bans"):
+			kind = BanGUID
+			continue
+		case strings.HasPrefix(line, "IP Bans"):
+			kind = BanIP
+			continue
+		}
+		m := banRow.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		b := Ban{Index: atoi(m[1]), Kind: kind, Target: m[2], Reason: strings.TrimSpace(m[4])}
+		switch m[3] {
+		case "perm":
+			b.MinutesLeft = -1
+		case "-":
+			b.Expired = true
+		default:
+			b.MinutesLeft = atoi(m[3])
+		}
+		if kind == BanGUID {
+			b.Target = strings.ToLower(b.Target)
+		}
+		bans = append(bans, b)
+	}
+	return bans
+}
+
+// ParseAdmins reads the output of the admins command
+func ParseAdmins(out string) []Admin {
+	admins := []Admin{}
+	for _, line := range tableRows(out) {
+		if m := adminRow.FindStringSubmatch(line); m != nil {
+			admins = append(admins, Admin{Index: atoi(m[1]), Address: m[2]})
+		}
+	}
+	return admins
+}
+
+// ParseStatus reads FPS and player counts from the status command; a reply without FPS is an error,
+// so it is never mistaken for a server running at 0 FPS
+func ParseStatus(out string) (ServerStatus, error) {
+	var st ServerStatus
+	m := fpsField.FindStringSubmatch(out)
+	if m == nil {
+		return st, fmt.Errorf("status 응답에 FPS가 없습니다: %q", singleLine(out))
+	}
+	fps, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return st, fmt.Errorf("status 응답의 FPS를 읽을 수 없습니다: %q", m[1])
+	}
+	st.FPS = fps
+	if m := slotField.FindStringSubmatch(out); m != nil {
+		st.Players, st.MaxPlayers = atoi(m[1]), atoi(m[2])
+	}
+	return st, nil
+}
+
+// tableRows returns the trimmed lines of a BattlEye table that start with a row number
+func tableRows(out string) []string {
+	var rows []string
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && line[0] >= '0' && line[0] <= '9' {
+			rows = append(rows, line)
+		}
+	}
+	return rows
+}
+
+// Commands sends typed BattlEye RCON commands
+type Commands struct {
+	exec Executor
+}
+
+func NewCommands(exec Executor) *Commands {
+	return &Commands{exec: exec}
+}
+
+// Players lists the connected players
+func (c *Commands) Players() ([]Player, error) {
+	out, err := c.exec.Exec("players")
+	if err != nil {
+		return nil, err
+	}
+	return ParsePlayers(out), nil
+}
+
+// Bans lists the GUID and IP bans loaded by the server
+func (c *Commands) Bans() ([]Ban, error) {
+	out, err := c.exec.Exec("bans")
+	if err != nil {
+		return nil, err
+	}
+	return ParseBans(out), nil
+}
+
+// Admins lists the connected RCon admins
+func (c *Commands) Admins() ([]Admin, error) {
+	out, err := c.exec.Exec("admins")
+	if err != nil {
+		return nil, err
+	}
+	return ParseAdmins(out), nil
+}
+
+// Status returns the server FPS and player counts
+func (c *Commands) Status() (ServerStatus, error) {
+	out, err := c.exec.Exec("status")
+	if err != nil {
+		return ServerStatus{}, err
+	}
+	return ParseStatus(out)
+}
+
+// Say sends a chat message to one player, or to everyone with AllPlayers
+func (c *Commands) Say(target int, msg string) error {
+	if target < AllPlayers {
+		return fmt.Errorf("잘못된 플레이어 번호입니다: %d", target)
+	}
+	msg = singleLine(msg)
+	if msg == "" {
+		return fmt.Errorf("메시지가 비어 있습니다")
+	}
+	return c.run(fmt.Sprintf("say %d %s", target, msg))
+}
+
+// Kick removes a player from the server
+func (c *Commands) Kick(id int, reason string) error {
+	if id < 0 {
+		return fmt.Errorf("잘못된 플레이어 번호입니다: %d", id)
+	}
+	return c.run(withReason(fmt.Sprintf("kick %d", id), reason))
+}
+
+// BanPlayer bans a connected player by number for minutes (0 = permanent) and kicks them
+func (c *Commands) BanPlayer(id, minutes int, reason string) error {
+	if id < 0 {
+		return fmt.Errorf("잘못된 플레이어 번호입니다: %d", id)
+	}
+	if minutes < 0 {
+		return fmt.Errorf("차단 시간은 0(영구) 이상이어야 합니다: %d", minutes)
+	}
+	return c.run(withReason(fmt.Sprintf("ban %d %d", id, minutes), reason))
+}
+
+// Ban adds a ban on a BE GUID or IP address for minutes (0 = permanent), whether or not the player is online
+func (c *Commands) Ban(target string, minutes int, reason string) error {
+	if _, err := BanKind(target); err != nil {
+		return err
+	}
+	if minutes < 0 {
+		return fmt.Errorf("차단 시간은 0(영구) 이상이어야 합니다: %d", minutes)
+	}
+	return c.run(withReason(fmt.Sprintf("addBan %s %d", target, minutes), reason))
+}
+
+// RemoveBan removes the ban with the given index from the bans list
+func (c *Commands) RemoveBan(index int) error {
+	if index < 0 {
+		return fmt.Errorf("잘못된 차단 번호입니다: %d", index)
+	}
+	return c.run(fmt.Sprintf("removeBan %d", index))
+}
+
+// LoadBans reloads bans.txt on the server
+func (c *Commands) LoadBans() error { return c.run("loadBans") }
+
+// WriteBans saves the server's ban list to bans.txt, dropping expired bans
+func (c *Commands) WriteBans() error { return c.run("writeBans") }
+
+// run sends a command whose response carries nothing to parse
+func (c *Commands) run(cmd string) error {
+	_, err := c.exec.Exec(cmd)
+	return err
+}
+
+// BanKind tells whether a ban target is a BE GUID or an IP address
+func BanKind(target string) (string, error) {
+	switch {
+	case guidValue.MatchString(target):
+		return BanGUID, nil
+	case net.ParseIP(target) != nil:
+		return BanIP, nil
+	}
+	return "", fmt.Errorf("BE GUID(32자리 16진수) 또는 IP 주소가 아닙니다: %s", target)
+}
+
+func withReason(cmd, reason string) string {
+	if reason = singleLine(reason); reason != "" {
+		return cmd + " " + reason
+	}
+	return cmd
+}
+
+// singleLine keeps user text from splitting into a second command
+func singleLine(s string) string {
+	return strings.TrimSpace(strings.NewReplacer("\r", " ", "\n", " ").Replace(s))
+}
@@ -0,0 +1,159 @@
+package battleye
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func fixture(t *testing.T, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestParsePlayers(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    []Player
+	}{
+		{"players.txt", []Player{
+			{Index: 0, Name: "Rifleman", IP: "192.168.1.10", Port: 2304, Ping: 31, GUID: "5d7c1bde0e6f4d1f8b2a9c3e4f5a6b7c", Verified: true},
+			{Index: 1, Name: "Sgt. Kim [KG]", IP: "10.0.0.5", Port: 2305, Ping: 87, GUID: "0a1b2c3d4e5f60718293a4b5c6d7e8f9", Verified: true},
+			{Index: 3, Name: "Late Joiner", IP: "203.0.113.7", Port: 2304, Ping: -1, GUID: "ffeeddccbbaa99887766554433221100", Lobby: true},
+			{Index: 4, Name: "Connecting", IP: "198.51.100.2", Port: 2304},
+		}},
+		{"players_empty.txt", []Player{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			got := ParsePlayers(fixture(t, tt.fixture))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParsePlayers() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseBans(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    []Ban
+	}{
+		{"bans.txt", []Ban{
+			{Index: 0, Kind: BanGUID, Target: "5d7c1bde0e6f4d1f8b2a9c3e4f5a6b7c", MinutesLeft: -1, Reason: "Cheating"},
+			{Index: 1, Kind: BanGUID, Target: "0a1b2c3d4e5f60718293a4b5c6d7e8f9", MinutesLeft: 58, Reason: "Teamkilling (admin)"},
+			{Index: 2, Kind: BanGUID, Target: "ffeeddccbbaa99887766554433221100", Expired: true},
+			{Index: 3, Kind: BanIP, Target: "203.0.113.7", MinutesLeft: -1, Reason: "VPN abuse"},
+			{Index: 4, Kind: BanIP, Target: "198.51.100.2", MinutesLeft: 1440},
+		}},
+		{"bans_empty.txt", []Ban{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			got := ParseBans(fixture(t, tt.fixture))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseBans() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAdmins(t *testing.T) {
+	want := []Admin{{Index: 0, Address: "127.0.0.1:53211"}, {Index: 1, Address: "192.168.1.20:61002"}}
+	if got := ParseAdmins(fixture(t, "admins.txt")); !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseAdmins() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		out     string
+		want    ServerStatus
+		wantErr bool
+	}{
+		{"status.txt", fixture(t, "status.txt"), ServerStatus{FPS: 59.9, Players: 5}, false},
+		{"status_no_fps.txt", fixture(t, "status_no_fps.txt"), ServerStatus{}, true},
+		{"slots", "FPS: 30, Players: 12/64", ServerStatus{FPS: 30, Players: 12, MaxPlayers: 64}, false},
+		{"zero fps", "FPS: 0", ServerStatus{}, false},
+		{"empty", "", ServerStatus{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseStatus(tt.out)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseStatus() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseStatus() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommands(t *testing.T) {
+	const guid = "5d7c1bde0e6f4d1f8b2a9c3e4f5a6b7c"
+	tests := []struct {
+		name    string
+		run     func(c *Commands) error
+		want    string // Command sent, empty when nothing may be sent
+		wantErr bool
+	}{
+		{"say all", func(c *Commands) error { return c.Say(AllPlayers, "Restart in 5 min") }, "say -1 Restart in 5 min", false},
+		{"say player", func(c *Commands) error { return c.Say(3, " hi\nkick 0 ") }, "say 3 hi kick 0", false},
+		{"say empty", func(c *Commands) error { return c.Say(AllPlayers, " \n") }, "", true},
+		{"say bad target", func(c *Commands) error { return c.Say(-2, "x") }, "", true},
+		{"kick", func(c *Commands) error { return c.Kick(2, "AFK") }, "kick 2 AFK", false},
+		{"kick without reason", func(c *Commands) error { return c.Kick(2, "") }, "kick 2", false},
+		{"kick bad id", func(c *Commands) error { return c.Kick(-1, "") }, "", true},
+		{"ban player", func(c *Commands) error { return c.BanPlayer(1, 60, "Toxic") }, "ban 1 60 Toxic", false},
+		{"ban player negative", func(c *Commands) error { return c.BanPlayer(1, -5, "") }, "", true},
+		{"ban guid", func(c *Commands) error { return c.Ban(guid, 0, "Cheating") }, "addBan " + guid + " 0 Cheating", false},
+		{"ban ip", func(c *Commands) error { return c.Ban("203.0.113.7", 1440, "") }, "addBan 203.0.113.7 1440", false},
+		{"ban bad target", func(c *Commands) error { return c.Ban("Rifleman", 0, "") }, "", true},
+		{"remove ban", func(c *Commands) error { return c.RemoveBan(4) }, "removeBan 4", false},
+		{"load bans", func(c *Commands) error { return c.LoadBans() }, "loadBans", false},
+		{"write bans", func(c *Commands) error { return c.WriteBans() }, "writeBans", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sent string
+			c := NewCommands(ExecFunc(func(cmd string) (string, error) {
+				sent = cmd
+				return "", nil
+			}))
+			err := tt.run(c)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if sent != tt.want {
+				t.Errorf("sent %q, want %q", sent, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommandsParseResponses(t *testing.T) {
+	responses := map[string]string{
+		"players": fixture(t, "players.txt"),
+		"bans":    fixture(t, "bans.txt"),
+	}
+	c := NewCommands(ExecFunc(func(cmd string) (string, error) { return responses[cmd], nil }))
+
+	players, err := c.Players()
+	if err != nil || len(players) != 4 {
+		t.Fatalf("Players() = %d players, %v", len(players), err)
+	}
+	bans, err := c.Bans()
+	if err != nil || len(bans) != 5 {
+		t.Fatalf("Bans() = %d bans, %v", len(bans), err)
+	}
+	if !bans[0].Permanent() || bans[1].Permanent() || bans[2].Permanent() {
+		t.Errorf("Permanent() wrong for %+v", bans[:3])
+	}
+}
@@ -0,0 +1,155 @@
+package battleye
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Server message event types
+const (
+	EventChat       = "chat"
+	EventJoin       = "join"  // Player connected
+	EventGUID       = "guid"  // BE GUID of a connecting player is known (or verified)
+	EventLeave      = "leave" // Player disconnected
+	EventKick       = "kick"
+	EventBan        = "ban" // Kicked because of a ban
+	EventAdminLogin = "admin_login"
+	EventMessage    = "message" // Anything else the server pushed
+)
+
+// Event is a decoded BattlEye server message
+type Event struct {
+	Type       string    `json:"type"`
+	Time       time.Time `json:"time"`
+	Raw        string    `json:"raw"`
+	Channel    string    `json:"channel,omitempty"` // Chat: Global, Side, Command, Group, Vehicle, Direct...
+	PlayerID   int       `json:"playerId"`          // BE player number (-1 = unknown or not a player)
+	PlayerName string    `json:"playerName,omitempty"`
+	GUID       string    `json:"guid,omitempty"`
+	Address    string    `json:"address,omitempty"` // Player or RCon admin IP:port
+	Message    string    `json:"message,omitempty"` // Chat text
+	Reason     string    `json:"reason,omitempty"`  // Kick/ban reason
+	Admin      bool      `json:"admin,omitempty"`   // Chat sent by an RCon admin
+	Verified   bool      `json:"verified,omitempty"`
+}
+
+var (
+	joinPattern     = regexp.MustCompile(`^Player #(\d+) (.+) \(([^()]*)\) connected$`)
+	guidPattern     = regexp.MustCompile(`^Player #(\d+) (.+) - (?:BE )?GUID: ([0-9a-fA-F]+)$`)
+	verifiedPattern = regexp.MustCompile(`^Verified GUID \(([0-9a-fA-F]+)\) of player #(\d+) (.+)$`)
+	leavePattern    = regexp.MustCompile(`^Player #(\d+) (.+) disconnected$`)
+	kickPattern     = regexp.MustCompile(`^Player #(\d+) (.+) \(([0-9a-fA-F-]*)\) has been kicked by BattlEye: (.*)$`)
+	adminPattern    = regexp.MustCompile(`^RCon admin #(\d+) \(([^()]*)\) logged in$`)
+	adminChat       = regexp.MustCompile(`^RCon admin #(\d+): \((\w+)\) (.*)$`)
+	chatPattern     = regexp.MustCompile(`^\((\w+)\) (.*)$`)
+)
+
+// Decoder turns server messages into events. It keeps the roster of connected players
+// so chat lines, which only carry a name, get the player number and GUID.
+type Decoder struct {
+	mu      sync.Mutex
+	players map[int]*rosterEntry
+}
+
+type rosterEntry struct {
+	name string
+	guid string
+}
+
+func NewDecoder() *Decoder {
+	return &Decoder{players: make(map[int]*rosterEntry)}
+}
+
+// Decode parses one server message
+func (d *Decoder) Decode(msg string) Event {
+	msg = strings.TrimRight(msg, "\r\n")
+	ev := Event{Type: EventMessage, Time: time.Now(), Raw: msg, PlayerID: -1}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if m := joinPattern.FindStringSubmatch(msg); m != nil {
+		ev.Type, ev.PlayerID, ev.PlayerName, ev.Address = EventJoin, atoi(m[1]), m[2], m[3]
+		d.players[ev.PlayerID] = &rosterEntry{name: ev.PlayerName}
+		return ev
+	}
+	if m := guidPattern.FindStringSubmatch(msg); m != nil {
+		ev.Type, ev.PlayerID, ev.PlayerName, ev.GUID = EventGUID, atoi(m[1]), m[2], strings.ToLower(m[3])
+		d.remember(ev.PlayerID, ev.PlayerName, ev.GUID)
+		return ev
+	}
+	if m := verifiedPattern.FindStringSubmatch(msg); m != nil {
+		ev.Type, ev.GUID, ev.PlayerID, ev.PlayerName, ev.Verified = EventGUID, strings.ToLower(m[1]), atoi(m[2]), m[3], true
+		d.remember(ev.PlayerID, ev.PlayerName, ev.GUID)
+		return ev
+	}
+	if m := leavePattern.FindStringSubmatch(msg); m != nil {
+		ev.Type, ev.PlayerID, ev.PlayerName = EventLeave, atoi(m[1]), m[2]
+		if p := d.players[ev.PlayerID]; p != nil {
+			ev.GUID = p.guid
+		}
+		delete(d.players, ev.PlayerID)
+		return ev
+	}
+	if m := kickPattern.FindStringSubmatch(msg); m != nil {
+		ev.Type, ev.PlayerID, ev.PlayerName, ev.GUID, ev.Reason = EventKick, atoi(m[1]), m[2], strings.ToLower(m[3]), m[4]
+		if strings.HasPrefix(ev.Reason, "Admin Ban") || strings.HasPrefix(ev.Reason, "Global Ban") || strings.HasPrefix(ev.Reason, "Ban") {
+			ev.Type = EventBan
+		}
+		delete(d.players, ev.PlayerID)
+		return ev
+	}
+	if m := adminPattern.FindStringSubmatch(msg); m != nil {
+		ev.Type, ev.PlayerName, ev.Address = EventAdminLogin, "RCon admin #"+m[1], m[2]
+		return ev
+	}
+	if m := adminChat.FindStringSubmatch(msg); m != nil {
+		ev.Type, ev.PlayerName, ev.Channel, ev.Message, ev.Admin = EventChat, "RCon admin #"+m[1], m[2], m[3], true
+		return ev
+	}
+	if m := chatPattern.FindStringSubmatch(msg); m != nil {
+		if id, name, text, ok := d.splitChat(m[2]); ok {
+			ev.Type, ev.Channel, ev.PlayerID, ev.PlayerName, ev.Message = EventChat, m[1], id, name, text
+			if p := d.players[id]; p != nil {
+				ev.GUID = p.guid
+			}
+		}
+	}
+	return ev
+}
+
+func (d *Decoder) remember(id int, name, guid string) {
+	p := d.players[id]
+	if p == nil {
+		p = &rosterEntry{}
+		d.players[id] = p
+	}
+	p.name, p.guid = name, guid
+}
+
+// splitChat separates "Name: text", preferring the longest connected player name so names with
+// spaces or colons work; unknown senders are split at the first ": "
+func (d *Decoder) splitChat(s string) (id int, name, text string, ok bool) {
+	id = -1
+	for pid, p := range d.players {
+		if len(p.name) > len(name) && strings.HasPrefix(s, p.name+": ") {
+			id, name = pid, p.name
+		}
+	}
+	if name != "" {
+		return id, name, s[len(name)+2:], true
+	}
+	i := strings.Index(s, ": ")
+	if i <= 0 {
+		return -1, "", "", false
+	}
+	return -1, s[:i], s[i+2:], true
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
@@ -0,0 +1,58 @@
+package battleye
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecoder(t *testing.T) {
+	const (
+		rifleman = "5d7c1bde0e6f4d1f8b2a9c3e4f5a6b7c"
+		who      = "0a1b2c3d4e5f60718293a4b5c6d7e8f9"
+		cheater  = "ffeeddccbbaa99887766554433221100"
+	)
+	want := []Event{
+		{Type: EventJoin, PlayerID: 0, PlayerName: "Rifleman", Address: "192.168.1.10:2304"},
+		{Type: EventGUID, PlayerID: 0, PlayerName: "Rifleman", GUID: rifleman},
+		{Type: EventGUID, PlayerID: 0, PlayerName: "Rifleman", GUID: rifleman, Verified: true},
+		{Type: EventJoin, PlayerID: 1, PlayerName: "Dr: Who", Address: "10.0.0.5:2305"},
+		{Type: EventGUID, PlayerID: 1, PlayerName: "Dr: Who", GUID: who},
+		{Type: EventChat, Channel: "Global", PlayerID: 1, PlayerName: "Dr: Who", GUID: who, Message: "meet at grid 045: 112"},
+		{Type: EventChat, Channel: "Side", PlayerID: 0, PlayerName: "Rifleman", GUID: rifleman, Message: "need a medic"},
+		{Type: EventChat, Channel: "Vehicle", PlayerID: -1, PlayerName: "Stranger", Message: "who: is this"},
+		{Type: EventAdminLogin, PlayerID: -1, PlayerName: "RCon admin #0", Address: "127.0.0.1:53412"},
+		{Type: EventChat, Channel: "Global", PlayerID: -1, PlayerName: "RCon admin #0", Message: "Restart in 5 minutes", Admin: true},
+		{Type: EventJoin, PlayerID: 2, PlayerName: "Cheater", Address: "203.0.113.7:2304"},
+		{Type: EventBan, PlayerID: 2, PlayerName: "Cheater", GUID: cheater, Reason: "Admin Ban (Aimbot)"},
+		{Type: EventKick, PlayerID: 1, PlayerName: "Dr: Who", GUID: who, Reason: "Admin Kick (AFK)"},
+		{Type: EventLeave, PlayerID: 0, PlayerName: "Rifleman", GUID: rifleman},
+		{Type: EventMessage, PlayerID: -1},
+	}
+
+	lines := strings.Split(strings.TrimRight(fixture(t, "events.txt"), "\n"), "\n")
+	if len(lines) != len(want) {
+		t.Fatalf("fixture has %d messages, want %d", len(lines), len(want))
+	}
+	d := NewDecoder()
+	for i, line := range lines {
+		got := d.Decode(line)
+		if got.Raw != line {
+			t.Errorf("message %d: Raw = %q, want %q", i+1, got.Raw, line)
+		}
+		got.Time, got.Raw = want[i].Time, ""
+		if !reflect.DeepEqual(got, want[i]) {
+			t.Errorf("message %d %q:\n got %+v\nwant %+v", i+1, line, got, want[i])
+		}
+	}
+}
+
+func TestDecoderChatAfterLeave(t *testing.T) {
+	d := NewDecoder()
+	d.Decode("Player #5 Ghost (10.1.1.1:2304) connected")
+	d.Decode("Player #5 Ghost disconnected")
+	got := d.Decode("(Global) Ghost: still here?")
+	if got.Type != EventChat || got.PlayerID != -1 || got.PlayerName != "Ghost" || got.Message != "still here?" {
+		t.Errorf("Decode() = %+v, want chat from an unknown Ghost", got)
+	}
+}
@@ -0,0 +1,348 @@
+// Package battleye keeps long-lived BattlEye RCON sessions to game servers.
+package battleye
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/astral/kg-server-web-gui/internal/logs"
+)
+
+// Session states
+const (
+	StateConnecting   = "connecting"
+	StateConnected    = "connected"
+	StateDisconnected = "disconnected" // Waiting before the next reconnect attempt
+	StateClosed       = "closed"
+)
+
+const (
+	defaultCommandTimeout = 5 * time.Second
+	// keepAliveInterval must stay below the 45 seconds after which BattlEye drops a silent client
+	keepAliveInterval = 25 * time.Second
+	minBackoff        = time.Second
+	maxBackoff        = time.Minute
+)
+
+var (
+	// ErrNotConnected is returned for commands while the session waits to reconnect
+	ErrNotConnected = errors.New("RCON 연결이 끊어져 있습니다")
+	// ErrClosed is returned for commands on a closed session
+	ErrClosed = errors.New("RCON 세션이 종료되었습니다")
+	// ErrCommandTimeout is returned when a command got no response in time
+	ErrCommandTimeout = errors.New("RCON 명령 응답 시간 초과")
+)
+
+// conn is the part of a BattlEye client a session uses
+type conn interface {
+	Exec(cmd string) (string, error)
+	Messages() <-chan message
+	Close() error
+}
+
+// Status describes the connection of a session
+type Status struct {
+	Address    string    `json:"address"`
+	State      string    `json:"state"`
+	Since      time.Time `json:"since"` // When the state was entered
+	LastError  string    `json:"lastError,omitempty"`
+	Reconnects int       `json:"reconnects"` // Successful connects after the first
+	Queued     int       `json:"queued"`     // Commands waiting for the connection
+}
+
+// Options tune a session; zero values use the defaults
+type Options struct {
+	Label   string        // Log prefix, usually the instance ID
+	Timeout time.Duration // Per-command response timeout
+}
+
+type request struct {
+	cmd   string
+	reply chan result
+}
+
+type result struct {
+	resp string
+	err  error
+}
+
+// Session is a persistent RCON connection with keepalive, reconnects with backoff and a serialized command queue
+type Session struct {
+	address  string
+	password string
+	label    string
+	timeout  time.Duration
+	dial     func(address, password string, timeout time.Duration) (conn, error)
+
+	queue  chan *request
+	closed chan struct{}
+	once   sync.Once
+
+	mu         sync.Mutex
+	state      string
+	since      time.Time
+	lastErr    string
+	connects   int
+	subs       map[int]func(msg string)
+	nextSub    int
+	stateWatch []func(Status)
+}
+
+// NewSession starts connecting to the BattlEye RCON server at address
+func NewSession(address, password string, opts Options) *Session {
+	s := &Session{
+		address:  address,
+		password: password,
+		label:    opts.Label,
+		timeout:  opts.Timeout,
+		dial:     dialBattlEye,
+		queue:    make(chan *request, 64),
+		closed:   make(chan struct{}),
+		state:    StateConnecting,
+		since:    time.Now(),
+		subs:     make(map[int]func(string)),
+	}
+	if s.timeout <= 0 {
+		s.timeout = defaultCommandTimeout
+	}
+	if s.label == "" {
+		s.label = address
+	}
+	go s.run()
+	return s
+}
+
+func dialBattlEye(address, password string, timeout time.Duration) (conn, error) {
+	return dialClient(address, password, timeout)
+}
+
+// Address returns the RCON address of the session
+func (s *Session) Address() string { return s.address }
+
+// Matches reports whether the session uses the given credentials
+func (s *Session) Matches(address, password string) bool {
+	return s.address == address && s.password == password
+}
+
+// Status returns the current connection state
+func (s *Session) Status() Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	reconnects := s.connects - 1
+	if reconnects < 0 {
+		reconnects = 0
+	}
+	return Status{
+		Address:    s.address,
+		State:      s.state,
+		Since:      s.since,
+		LastError:  s.lastErr,
+		Reconnects: reconnects,
+		Queued:     len(s.queue),
+	}
+}
+
+// OnStateChange registers fn for every state change
+func (s *Session) OnStateChange(fn func(Status)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stateWatch = append(s.stateWatch, fn)
+}
+
+func (s *Session) setState(state string, err error) {
+	s.mu.Lock()
+	if s.state == state && err == nil {
+		s.mu.Unlock()
+		return
+	}
+	s.state, s.since = state, time.Now()
+	if err != nil {
+		s.lastErr = err.Error()
+	}
+	if state == StateConnected {
+		s.connects++
+		s.lastErr = ""
+	}
+	watchers := append([]func(Status){}, s.stateWatch...)
+	s.mu.Unlock()
+
+	st := s.Status()
+	for _, fn := range watchers {
+		fn(st)
+	}
+}
+
+// Subscribe registers fn for every server message pushed over the connection; call the returned func to unsubscribe
+func (s *Session) Subscribe(fn func(msg string)) func() {
+	s.mu.Lock()
+	id := s.nextSub
+	s.nextSub++
+	s.subs[id] = fn
+	s.mu.Unlock()
+	return func() {
+		s.mu.Lock()
+		delete(s.subs, id)
+		s.mu.Unlock()
+	}
+}
+
+func (s *Session) publish(msg string) {
+	s.mu.Lock()
+	subs := make([]func(string), 0, len(s.subs))
+	for _, fn := range s.subs {
+		subs = append(subs, fn)
+	}
+	s.mu.Unlock()
+	for _, fn := range subs {
+		fn(msg)
+	}
+}
+
+// Exec queues a command and waits for its response. Commands run one at a time in queue order.
+func (s *Session) Exec(cmd string) (string, error) {
+	s.mu.Lock()
+	state, lastErr := s.state, s.lastErr
+	s.mu.Unlock()
+	switch state {
+	case StateClosed:
+		return "", ErrClosed
+	case StateDisconnected:
+		if lastErr != "" {
+			return "", fmt.Errorf("%w: %s", ErrNotConnected, lastErr)
+		}
+		return "", ErrNotConnected
+	}
+
+	req := &request{cmd: cmd, reply: make(chan result, 1)}
+	wait := time.NewTimer(2*s.timeout + s.timeout/2) // Time to get connected plus the command itself
+	defer wait.Stop()
+	select {
+	case s.queue <- req:
+	case <-s.closed:
+		return "", ErrClosed
+	case <-wait.C:
+		return "", ErrCommandTimeout
+	}
+	select {
+	case r := <-req.reply:
+		return r.resp, r.err
+	case <-s.closed:
+		return "", ErrClosed
+	case <-wait.C:
+		return "", ErrCommandTimeout
+	}
+}
+
+// Close ends the session; queued commands fail with ErrClosed
+func (s *Session) Close() {
+	s.once.Do(func() {
+		close(s.closed)
+		s.setState(StateClosed, nil)
+	})
+}
+
+// run connects, serves the connection until it dies and reconnects with exponential backoff
+func (s *Session) run() {
+	backoff := minBackoff
+	for {
+		select {
+		case <-s.closed:
+			return
+		default:
+		}
+
+		s.setState(StateConnecting, nil)
+		c, err := s.dial(s.address, s.password, s.timeout)
+		if err != nil {
+			s.setState(StateDisconnected, err)
+			s.failQueued(err)
+			select {
+			case <-s.closed:
+				return
+			case <-time.After(backoff):
+			}
+			if backoff *= 2; backoff > maxBackoff {
+				backoff = maxBackoff
+			}
+			continue
+		}
+
+		backoff = minBackoff
+		s.setState(StateConnected, nil)
+		logs.GlobalLogs.Info(fmt.Sprintf("[%s] RCON 연결됨: %s", s.label, s.address))
+		err = s.serve(c)
+		c.Close()
+
+		select {
+		case <-s.closed:
+			return
+		default:
+		}
+		logs.GlobalLogs.Warn(fmt.Sprintf("[%s] RCON 연결 끊김, 재연결합니다: %v", s.label, err))
+		s.setState(StateConnecting, err)
+	}
+}
+
+// serve runs queued commands and forwards server messages until the connection fails
+func (s *Session) serve(c conn) error {
+	ping := time.NewTicker(keepAliveInterval)
+	defer ping.Stop()
+	msgs := c.Messages()
+	var seen seqWindow
+	for {
+		select {
+		case <-s.closed:
+			return ErrClosed
+		case msg, ok := <-msgs:
+			if !ok {
+				return fmt.Errorf("connection closed")
+			}
+			if !seen.add(msg.Seq) {
+				continue // Resent after our acknowledgement got lost
+			}
+			s.publish(msg.Text)
+		case req := <-s.queue:
+			resp, err := s.exec(c, req.cmd)
+			req.reply <- result{resp, err}
+			if err != nil {
+				return err
+			}
+			ping.Reset(keepAliveInterval)
+		case <-ping.C:
+			if _, err := s.exec(c, ""); err != nil {
+				return fmt.Errorf("keepalive: %w", err)
+			}
+		}
+	}
+}
+
+// exec runs one command with the session timeout
+func (s *Session) exec(c conn, cmd string) (string, error) {
+	done := make(chan result, 1)
+	go func() {
+		resp, err := c.Exec(cmd)
+		done <- result{resp, err}
+	}()
+	t := time.NewTimer(s.timeout)
+	defer t.Stop()
+	select {
+	case r := <-done:
+		return r.resp, r.err
+	case <-t.C:
+		return "", ErrCommandTimeout
+	}
+}
+
+// failQueued answers the commands that queued up while connecting
+func (s *Session) failQueued(err error) {
+	for {
+		select {
+		case req := <-s.queue:
+			req.reply <- result{err: fmt.Errorf("%w: %v", ErrNotConnected, err)}
+		default:
+			return
+		}
+	}
+}
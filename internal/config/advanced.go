@@ -0,0 +1,131 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// Log levels accepted by -logLevel
+var logLevels = map[string]bool{"normal": true, "warning": true, "error": true, "fatal": true, "verbose": true, "debug": true}
+
+// Modes accepted by -freezeCheckMode
+var freezeCheckModes = map[string]bool{"crash": true, "minidump": true}
+
+var restartTimePattern = regexp.MustCompile(`^([01]\d|2[0-3]):[0-5]\d$`)
+
+// Validate checks the values of the enabled options
+func (a *AdvancedSettings) Validate() error {
+	checkRange := func(enabled bool, name string, v, min, max int) error {
+		if enabled && (v < min || v > max) {
+			return fmt.Errorf("%s 값은 %d~%d 사이여야 합니다: %d", name, min, max, v)
+		}
+		return nil
+	}
+	for _, err := range []error{
+		checkRange(a.LimitServerMaxFPS, "maxFPS", a.MaxFPS, 1, 1000),
+		checkRange(a.AutoReloadScenario && a.ReloadScenarioInterval != 0, "autoreload", a.ReloadScenarioInterval, 1, 3600),
+		checkRange(a.OverridePort, "bindPort", a.Port, 1, 65535),
+		checkRange(a.NetworkDynamicSimulation, "nds", a.NetworkDynamicSimValue, 1, 10),
+		checkRange(a.SpatialMapResolution, "nwkResolution", a.SpatialMapResValue, 100, 1000),
+		checkRange(a.StaggeringBudget, "staggeringBudget", a.StaggeringBudgetValue, 1, 10000),
+		checkRange(a.DebuggerPort, "debuggerPort", a.DebuggerPortValue, 1, 65535),
+		checkRange(a.ShortWorkerCount, "jobsysShortWorkerCount", a.ShortWorkerCountValue, 1, 256),
+		checkRange(a.LongWorkerCount, "jobsysLongWorkerCount", a.LongWorkerCountValue, 1, 256),
+		checkRange(a.FreezeCheck, "freezeCheck", a.FreezeCheckValue, 1, 3600),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+
+	if a.LogWriting && a.LogLevel != "" && !logLevels[a.LogLevel] {
+		return fmt.Errorf("알 수 없는 로그 레벨입니다: %s", a.LogLevel)
+	}
+	if a.FreezeCheckMode && !freezeCheckModes[a.FreezeCheckModeValue] {
+		return fmt.Errorf("freezeCheckMode는 crash 또는 minidump여야 합니다: %s", a.FreezeCheckModeValue)
+	}
+	if a.DebuggerAddress && a.DebuggerAddressValue == "" {
+		return fmt.Errorf("디버거 주소가 비어 있습니다")
+	}
+	if a.AutoRestart && !restartTimePattern.MatchString(a.RestartTime) {
+		return fmt.Errorf("재시작 시각은 HH:MM 형식이어야 합니다: %s", a.RestartTime)
+	}
+	if a.DisableShadersGeneration && a.ForceGenerateShaders {
+		return fmt.Errorf("셰이더 생성 비활성화와 강제 생성은 함께 사용할 수 없습니다")
+	}
+	return nil
+}
+
+// CheckSupported rejects the options the panel does not implement, so they are not saved and then ignored
+func (a *AdvancedSettings) CheckSupported() error {
+	for _, opt := range []struct {
+		enabled bool
+		name    string
+	}{
+		{a.RestartOnGameDestroyed, "restartOnGameDestroyed (재시작 정책을 사용하세요)"},
+		{a.UseExperimentalServer, "useExperimentalServer"},
+		{a.UseUPnP, "useUPnP"},
+		{a.KeepServerUpToDate, "keepServerUpToDate"},
+	} {
+		if opt.enabled {
+			return fmt.Errorf("지원하지 않는 옵션입니다: %s", opt.name)
+		}
+	}
+	return nil
+}
+
+// LaunchArgs translates the enabled options into engine command-line parameters.
+// AutoRestart/RestartTime has no parameter, the scheduler restarts the server at that time.
+func (a *AdvancedSettings) LaunchArgs() []string {
+	var args []string
+	flag := func(enabled bool, name string) {
+		if enabled {
+			args = append(args, name)
+		}
+	}
+	value := func(enabled bool, name, v string) {
+		if enabled && v != "" {
+			args = append(args, name, v)
+		}
+	}
+	number := func(enabled bool, name string, v int) {
+		value(enabled && v != 0, name, strconv.Itoa(v))
+	}
+
+	number(a.LimitServerMaxFPS, "-maxFPS", a.MaxFPS)
+	if a.AutoReloadScenario {
+		interval := a.ReloadScenarioInterval
+		if interval == 0 {
+			interval = 10
+		}
+		number(true, "-autoreload", interval)
+	}
+	if a.LoadSessionSave {
+		args = append(args, "-loadSessionSave")
+		if a.SessionSavePath != "" {
+			args = append(args, a.SessionSavePath)
+		}
+	}
+	flag(a.VerifyRepairAddons, "-addonsRepair")
+	flag(a.NoBackend, "-noBackend")
+	flag(a.AutoShutdown, "-autoshutdown")
+	value(a.LogWriting, "-logLevel", a.LogLevel)
+	number(a.OverridePort, "-bindPort", a.Port)
+	number(a.NetworkDynamicSimulation, "-nds", a.NetworkDynamicSimValue)
+	number(a.SpatialMapResolution, "-nwkResolution", a.SpatialMapResValue)
+	number(a.StaggeringBudget, "-staggeringBudget", a.StaggeringBudgetValue)
+	flag(a.AIPartialSim, "-aiPartialSim")
+	flag(a.ForceRecreateDatabase, "-createDB")
+	value(a.DebuggerAddress, "-debugger", a.DebuggerAddressValue)
+	number(a.DebuggerPort, "-debuggerPort", a.DebuggerPortValue)
+	flag(a.DisableShadersGeneration, "-disableShadersBuild")
+	flag(a.ForceGenerateShaders, "-generateShaders")
+	flag(a.BPIEncodeAsLongJob, "-rplEncodeAsLongJob")
+	number(a.ShortWorkerCount, "-jobsysShortWorkerCount", a.ShortWorkerCountValue)
+	number(a.LongWorkerCount, "-jobsysLongWorkerCount", a.LongWorkerCountValue)
+	number(a.FreezeCheck, "-freezeCheck", a.FreezeCheckValue)
+	value(a.FreezeCheckMode, "-freezeCheckMode", a.FreezeCheckModeValue)
+	flag(a.ForceDisableNightGrab, "-forceDisableNightGrain")
+	return args
+}
@@ -175,7 +175,7 @@ type AdvancedSettings struct {
 	FreezeCheck          bool   `json:"freezeCheck"`
 	FreezeCheckValue     int    `json:"freezeCheckValue,omitempty"` // seconds
 	FreezeCheckMode      bool   `json:"freezeCheckMode"`
-	FreezeCheckModeValue string `json:"freezeCheckModeValue,omitempty"` // crash, restart
+	FreezeCheckModeValue string `json:"freezeCheckModeValue,omitempty"` // crash, minidump
 
 	// Force Disable Night Grab
 	ForceDisableNightGrab bool `json:"forceDisableNightGrab"`
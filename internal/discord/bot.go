@@ -157,8 +157,17 @@ func (b *Bot) handleStatusCommand(s *discordgo.Session, m *discordgo.MessageCrea
 	}
 
 	status := "🔴 중지됨"
-	if inst.Status == "running" {
+	switch inst.Status {
+	case server.StatusRunning:
 		status = fmt.Sprintf("🟢 실행 중 (PID: %d)", inst.PID)
+	case server.StatusStarting:
+		status = fmt.Sprintf("🟡 시작 중 (PID: %d)", inst.PID)
+	case server.StatusStopping:
+		status = "🟡 종료 중"
+	case server.StatusUpdating:
+		status = "🔵 업데이트 중"
+	case server.StatusCrashed:
+		status = "💥 비정상 종료"
 	}
 
 	s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("📊 **서버 상태 [%s]**\n상태: %s", id, status))
@@ -180,13 +189,19 @@ func (b *Bot) handleStartCommand(s *discordgo.Session, m *discordgo.MessageCreat
 		return
 	}
 
-	if inst.Status == "running" {
+	if inst.Status == server.StatusRunning || inst.Status == server.StatusStarting {
 		s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("⚠️ 서버 '%s'가 이미 실행 중입니다.", id))
 		return
 	}
 
+	by := server.Trigger{Source: server.SourceDiscord, Name: m.Author.Username}
+	if err := b.instanceMgr.CheckMaintenance(id, by); err != nil {
+		s.ChannelMessageSend(m.ChannelID, "🔧 "+err.Error())
+		return
+	}
+
 	s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("🚀 서버 **%s** 시작 중...", id))
-	if err := b.instanceMgr.Start(id, []string{"-server"}); err != nil {
+	if err := b.instanceMgr.StartBy(id, []string{"-server"}, by); err != nil {
 		s.ChannelMessageSend(m.ChannelID, "❌ 시작 실패: "+err.Error())
 	}
 }
@@ -206,8 +221,14 @@ func (b *Bot) handleStopCommand(s *discordgo.Session, m *discordgo.MessageCreate
 		return
 	}
 
+	opts := server.ShutdownOptions{Trigger: server.Trigger{Source: server.SourceDiscord, Name: m.Author.Username}}
+	if err := b.instanceMgr.CheckMaintenance(id, opts.Trigger); err != nil {
+		s.ChannelMessageSend(m.ChannelID, "🔧 "+err.Error())
+		return
+	}
+
 	s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("🛑 서버 **%s** 중지 중...", id))
-	if err := b.instanceMgr.Stop(id); err != nil {
+	if err := b.instanceMgr.GracefulStop(id, opts); err != nil {
 		s.ChannelMessageSend(m.ChannelID, "❌ 중지 실패: "+err.Error())
 	}
 }
@@ -232,16 +253,20 @@ func (b *Bot) handleMapCommand(s *discordgo.Session, m *discordgo.MessageCreate,
 		return
 	}
 
-	// Send processing message
-	s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("🔄 맵 변경 중: **%s** (슬롯 %d)...", mapping.Name, slot))
-
 	b.mu.RLock()
 	instanceID := b.instanceID
 	b.mu.RUnlock()
 
-	requester := fmt.Sprintf("Discord (%s)", m.Author.Username)
+	by := server.Trigger{Source: server.SourceDiscord, Name: m.Author.Username}
+	if err := b.instanceMgr.CheckMaintenance(instanceID, by); err != nil {
+		s.ChannelMessageSend(m.ChannelID, "🔧 "+err.Error())
+		return
+	}
+
+	// Send processing message
+	s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("🔄 맵 변경 중: **%s** (슬롯 %d)...", mapping.Name, slot))
 
-	if err := b.mapService.ChangeMapBySlot(instanceID, slot, requester); err != nil {
+	if err := b.mapService.ChangeMapBySlot(instanceID, slot, by); err != nil {
 		s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("❌ 맵 변경 실패: %s", err.Error()))
 		return
 	}
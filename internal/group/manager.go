@@ -0,0 +1,472 @@
+package group
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/astral/kg-server-web-gui/internal/logs"
+	"github.com/astral/kg-server-web-gui/internal/server"
+	"github.com/google/uuid"
+)
+
+// Group actions
+const (
+	ActionStart   = "start"
+	ActionStop    = "stop"
+	ActionRestart = "restart"
+)
+
+// Operation modes
+const (
+	ModeOrdered = "ordered" // Members one after another, Delay seconds apart
+	ModeRolling = "rolling" // Restart one member at a time and wait until it is ready again
+)
+
+// Operation and member states
+const (
+	StatePending   = "pending"
+	StateRunning   = "running"
+	StateWaiting   = "waiting" // Waiting for readiness (rolling) or the delay before the next member
+	StateDone      = "done"
+	StateFailed    = "failed"
+	StateSkipped   = "skipped" // Not run after an earlier member failed
+	StateCancelled = "cancelled"
+)
+
+// Group is an ordered set of server instances operated together
+type Group struct {
+	ID            string    `json:"id"`
+	Name          string    `json:"name"`
+	Tags          []string  `json:"tags"`
+	Members       []string  `json:"members"`       // Instance IDs in operation order
+	Delay         int       `json:"delay"`         // Seconds between members
+	StopOnFailure bool      `json:"stopOnFailure"` // Skip the remaining members once one fails
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+// OperationRequest configures one group operation; unset fields fall back to the group's settings
+type OperationRequest struct {
+	Mode          string `json:"mode"` // ordered (default) or rolling (restart only)
+	Delay         *int   `json:"delay,omitempty"`
+	StopOnFailure *bool  `json:"stopOnFailure,omitempty"`
+	Reason        string `json:"reason"`
+	Countdown     bool   `json:"countdown"` // Warn players before stopping/restarting each member
+}
+
+// MemberProgress is the state of one member in an operation
+type MemberProgress struct {
+	InstanceID string     `json:"instanceId"`
+	Name       string     `json:"name"`
+	State      string     `json:"state"`
+	Error      string     `json:"error,omitempty"`
+	StartedAt  *time.Time `json:"startedAt,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
+
+// Operation is the progress of a start/stop/restart across a group
+type Operation struct {
+	GroupID    string           `json:"groupId"`
+	Action     string           `json:"action"`
+	Mode       string           `json:"mode"`
+	State      string           `json:"state"`
+	StartedAt  time.Time        `json:"startedAt"`
+	FinishedAt *time.Time       `json:"finishedAt,omitempty"`
+	By         server.Trigger   `json:"by"`
+	Members    []MemberProgress `json:"members"`
+}
+
+type operationTask struct {
+	op     Operation
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Manager stores groups and runs operations on them
+type Manager struct {
+	mu          sync.RWMutex
+	groups      map[string]*Group
+	ops         map[string]*operationTask // Current or last operation per group
+	dataPath    string
+	instanceMgr *server.InstanceManager
+}
+
+// NewManager creates a group manager and loads the saved groups
+func NewManager(dataPath string, im *server.InstanceManager) *Manager {
+	m := &Manager{
+		groups:      make(map[string]*Group),
+		ops:         make(map[string]*operationTask),
+		dataPath:    dataPath,
+		instanceMgr: im,
+	}
+	m.load()
+	return m
+}
+
+// List returns the groups, optionally only those carrying a tag
+func (m *Manager) List(tag string) []*Group {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	list := []*Group{}
+	for _, g := range m.groups {
+		if tag == "" || slices.Contains(g.Tags, tag) {
+			list = append(list, g)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// Get returns a group by ID
+func (m *Manager) Get(id string) *Group {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.groups[id]
+}
+
+// validate checks a group's name and members
+func (m *Manager) validate(g *Group) error {
+	if g.Name == "" {
+		return fmt.Errorf("그룹 이름이 필요합니다")
+	}
+	if g.Delay < 0 {
+		return fmt.Errorf("대기 시간은 음수일 수 없습니다")
+	}
+	seen := make(map[string]bool)
+	for _, id := range g.Members {
+		if m.instanceMgr.Get(id) == nil {
+			return fmt.Errorf("서버를 찾을 수 없습니다: %s", id)
+		}
+		if seen[id] {
+			return fmt.Errorf("서버가 그룹에 중복되어 있습니다: %s", id)
+		}
+		seen[id] = true
+	}
+	if g.Tags == nil {
+		g.Tags = []string{}
+	}
+	if g.Members == nil {
+		g.Members = []string{}
+	}
+	return nil
+}
+
+// Add creates a group
+func (m *Manager) Add(g *Group) error {
+	if err := m.validate(g); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if g.ID == "" {
+		g.ID = uuid.New().String()
+	}
+	if _, exists := m.groups[g.ID]; exists {
+		return fmt.Errorf("그룹 ID가 이미 존재합니다: %s", g.ID)
+	}
+	g.CreatedAt = time.Now()
+	m.groups[g.ID] = g
+	return m.save()
+}
+
+// Update replaces a group's name, tags, members and settings
+func (m *Manager) Update(g *Group) error {
+	if err := m.validate(g); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	existing, ok := m.groups[g.ID]
+	if !ok {
+		return fmt.Errorf("그룹을 찾을 수 없습니다: %s", g.ID)
+	}
+	existing.Name = g.Name
+	existing.Tags = g.Tags
+	existing.Members = g.Members
+	existing.Delay = g.Delay
+	existing.StopOnFailure = g.StopOnFailure
+	return m.save()
+}
+
+// Delete removes a group that has no operation in progress
+func (m *Manager) Delete(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.groups[id]; !ok {
+		return fmt.Errorf("그룹을 찾을 수 없습니다: %s", id)
+	}
+	if task, ok := m.ops[id]; ok && task.running() {
+		return fmt.Errorf("그룹 작업이 진행 중입니다")
+	}
+	delete(m.groups, id)
+	delete(m.ops, id)
+	return m.save()
+}
+
+// Run starts an operation on a group in the background and returns its initial progress
+func (m *Manager) Run(id, action string, req OperationRequest, by server.Trigger) (*Operation, error) {
+	switch action {
+	case ActionStart, ActionStop, ActionRestart:
+	default:
+		return nil, fmt.Errorf("알 수 없는 그룹 작업입니다: %s", action)
+	}
+	if req.Mode == "" {
+		req.Mode = ModeOrdered
+	}
+	switch {
+	case req.Mode == ModeRolling && action != ActionRestart:
+		return nil, fmt.Errorf("순차 재시작(rolling)은 재시작 작업에서만 사용할 수 있습니다")
+	case req.Mode != ModeOrdered && req.Mode != ModeRolling:
+		return nil, fmt.Errorf("알 수 없는 작업 방식입니다: %s", req.Mode)
+	}
+	if req.Delay != nil && *req.Delay < 0 {
+		return nil, fmt.Errorf("대기 시간은 음수일 수 없습니다")
+	}
+
+	m.mu.Lock()
+	g, ok := m.groups[id]
+	if !ok {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("그룹을 찾을 수 없습니다: %s", id)
+	}
+	if prev, ok := m.ops[id]; ok && prev.running() {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("그룹 작업이 이미 진행 중입니다 (%s)", prev.op.Action)
+	}
+	if len(g.Members) == 0 {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("그룹에 서버가 없습니다")
+	}
+
+	delay, stopOnFailure := g.Delay, g.StopOnFailure
+	if req.Delay != nil {
+		delay = *req.Delay
+	}
+	if req.StopOnFailure != nil {
+		stopOnFailure = *req.StopOnFailure
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	task := &operationTask{
+		op: Operation{
+			GroupID:   id,
+			Action:    action,
+			Mode:      req.Mode,
+			State:     StateRunning,
+			StartedAt: time.Now(),
+			By:        by,
+		},
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	for _, member := range g.Members {
+		name := member
+		if inst := m.instanceMgr.Get(member); inst != nil {
+			name = inst.Name
+		}
+		task.op.Members = append(task.op.Members, MemberProgress{InstanceID: member, Name: name, State: StatePending})
+	}
+	m.ops[id] = task
+	name := g.Name
+	m.mu.Unlock()
+
+	logs.GlobalLogs.Info(fmt.Sprintf("[그룹 %s] %s 작업 시작 (%s, 서버 %d개, 요청자: %s)", name, action, req.Mode, len(task.op.Members), by))
+	go func() {
+		defer cancel()
+		m.runOperation(ctx, task, req, time.Duration(delay)*time.Second, stopOnFailure)
+		close(task.done)
+		op := m.GetOperation(id)
+		logs.GlobalLogs.Info(fmt.Sprintf("[그룹 %s] %s 작업 종료: %s", name, action, op.State))
+	}()
+	return m.GetOperation(id), nil
+}
+
+// runOperation works through the members of an operation in order
+func (m *Manager) runOperation(ctx context.Context, task *operationTask, req OperationRequest, delay time.Duration, stopOnFailure bool) {
+	failed := false
+	for i := range task.op.Members {
+		if ctx.Err() != nil {
+			m.finishRemaining(task, i, StateCancelled)
+			m.setOperationState(task, StateCancelled)
+			return
+		}
+		if failed && stopOnFailure {
+			m.finishRemaining(task, i, StateSkipped)
+			break
+		}
+		if i > 0 && delay > 0 {
+			m.setMember(task, i, StateWaiting, "")
+			if !sleep(ctx, delay) {
+				continue // Cancelled: reported at the top of the loop
+			}
+		}
+
+		id := task.op.Members[i].InstanceID
+		m.setMember(task, i, StateRunning, "")
+		err := m.runMember(ctx, task, i, id, req)
+		if err != nil {
+			failed = true
+			if ctx.Err() != nil {
+				m.setMember(task, i, StateCancelled, err.Error())
+				continue
+			}
+			m.setMember(task, i, StateFailed, err.Error())
+			logs.GlobalLogs.Warn(fmt.Sprintf("[그룹 %s] %s 실패: %v", task.op.GroupID, id, err))
+			continue
+		}
+		m.setMember(task, i, StateDone, "")
+	}
+
+	if ctx.Err() != nil {
+		m.setOperationState(task, StateCancelled)
+	} else if failed {
+		m.setOperationState(task, StateFailed)
+	} else {
+		m.setOperationState(task, StateDone)
+	}
+}
+
+// runMember applies the action to one member; rolling restarts also wait for it to be ready
+func (m *Manager) runMember(ctx context.Context, task *operationTask, i int, id string, req OperationRequest) error {
+	opts := server.ShutdownOptions{Reason: req.Reason, Countdown: req.Countdown, Trigger: task.op.By}
+	switch task.op.Action {
+	case ActionStart:
+		return m.instanceMgr.StartBy(id, nil, task.op.By)
+	case ActionStop:
+		if opts.Reason == "" {
+			opts.Reason = "그룹 중지"
+		}
+		return m.instanceMgr.GracefulStop(id, opts)
+	}
+
+	if opts.Reason == "" {
+		opts.Reason = "그룹 재시작"
+	}
+	if err := m.instanceMgr.Restart(id, opts); err != nil {
+		return err
+	}
+	if task.op.Mode != ModeRolling {
+		return nil
+	}
+	m.setMember(task, i, StateWaiting, "")
+	return m.instanceMgr.WaitRunning(ctx, id)
+}
+
+// GetOperation returns the current or last operation of a group, nil if there was none
+func (m *Manager) GetOperation(id string) *Operation {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	task, ok := m.ops[id]
+	if !ok {
+		return nil
+	}
+	op := task.op
+	op.Members = append([]MemberProgress{}, task.op.Members...)
+	return &op
+}
+
+// Cancel stops a running operation; the member in progress finishes its current step
+func (m *Manager) Cancel(id string) error {
+	m.mu.RLock()
+	task, ok := m.ops[id]
+	m.mu.RUnlock()
+	if !ok || !task.running() {
+		return fmt.Errorf("진행 중인 그룹 작업이 없습니다")
+	}
+	task.cancel()
+	return nil
+}
+
+func (t *operationTask) running() bool {
+	select {
+	case <-t.done:
+		return false
+	default:
+		return true
+	}
+}
+
+func (m *Manager) setMember(task *operationTask, i int, state, errMsg string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	member := &task.op.Members[i]
+	member.State = state
+	member.Error = errMsg
+	switch state {
+	case StateRunning:
+		if member.StartedAt == nil {
+			member.StartedAt = &now
+		}
+	case StateDone, StateFailed, StateSkipped, StateCancelled:
+		member.FinishedAt = &now
+	}
+}
+
+// finishRemaining marks the members from i on that have not run
+func (m *Manager) finishRemaining(task *operationTask, from int, state string) {
+	for i := from; i < len(task.op.Members); i++ {
+		m.setMember(task, i, state, "")
+	}
+}
+
+func (m *Manager) setOperationState(task *operationTask, state string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	task.op.State = state
+	task.op.FinishedAt = &now
+}
+
+// sleep waits for d, returning false when ctx ends first
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func (m *Manager) load() {
+	data, err := os.ReadFile(filepath.Join(m.dataPath, "groups.json"))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logs.GlobalLogs.Error(fmt.Sprintf("[그룹] 설정 로드 실패: %v", err))
+		}
+		return
+	}
+	var groups []*Group
+	if err := json.Unmarshal(data, &groups); err != nil {
+		logs.GlobalLogs.Error(fmt.Sprintf("[그룹] JSON 파싱 실패: %v", err))
+		return
+	}
+	for _, g := range groups {
+		m.groups[g.ID] = g
+	}
+}
+
+// save writes groups.json; the caller holds m.mu
+func (m *Manager) save() error {
+	list := make([]*Group, 0, len(m.groups))
+	for _, g := range m.groups {
+		list = append(list, g)
+	}
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	os.MkdirAll(m.dataPath, 0755)
+	return os.WriteFile(filepath.Join(m.dataPath, "groups.json"), data, 0644)
+}
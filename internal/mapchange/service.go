@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"time"
 
 	"github.com/astral/kg-server-web-gui/internal/agent"
 	"github.com/astral/kg-server-web-gui/internal/config"
@@ -40,18 +39,21 @@ func NewMapChangeService(
 }
 
 // ChangeMapBySlot changes map by slot number
-func (s *MapChangeService) ChangeMapBySlot(instanceID string, slot int, requester string) error {
+func (s *MapChangeService) ChangeMapBySlot(instanceID string, slot int, by server.Trigger) error {
 	mapping := s.mappingMgr.Get(slot)
 	if mapping == nil {
 		return fmt.Errorf("슬롯 %d에 등록된 맵이 없습니다", slot)
 	}
 
-	return s.ChangeMap(instanceID, mapping.ScenarioID, mapping.Name, requester)
+	return s.ChangeMap(instanceID, mapping.ScenarioID, mapping.Name, by)
 }
 
 // ChangeMap changes the map to the specified scenario
-func (s *MapChangeService) ChangeMap(instanceID, scenarioID, mapName, requester string) error {
-	logs.GlobalLogs.Info(fmt.Sprintf("[MapChange] 맵 변경 요청: %s → %s (요청자: %s)", instanceID, mapName, requester))
+func (s *MapChangeService) ChangeMap(instanceID, scenarioID, mapName string, by server.Trigger) error {
+	logs.GlobalLogs.Info(fmt.Sprintf("[MapChange] 맵 변경 요청: %s → %s (요청자: %s)", instanceID, mapName, by))
+	if err := s.instanceMgr.CheckMaintenance(instanceID, by); err != nil {
+		return err
+	}
 
 	// 1. Get config path
 	configPath := s.getConfigPath(instanceID)
@@ -94,9 +96,10 @@ func (s *MapChangeService) ChangeMap(instanceID, scenarioID, mapName, requester
 	}
 
 	logs.GlobalLogs.Info(fmt.Sprintf("[MapChange] 설정 업데이트 완료: %s → %s", oldScenario, scenarioID))
+	s.instanceMgr.RecordEvent(instanceID, server.EventMapChanged, fmt.Sprintf("%s: %s → %s", mapName, oldScenario, scenarioID), by)
 
 	// 5. Restart server
-	if err := s.restartServer(instanceID); err != nil {
+	if err := s.restartServer(instanceID, mapName, by); err != nil {
 		return fmt.Errorf("서버 재시작 실패: %w", err)
 	}
 
@@ -104,7 +107,7 @@ func (s *MapChangeService) ChangeMap(instanceID, scenarioID, mapName, requester
 	if s.discord != nil {
 		s.discord.SendMessage(
 			"🗺️ 맵 변경됨",
-			fmt.Sprintf("**%s**\n요청자: %s\n시나리오: `%s`", mapName, requester, scenarioID),
+			fmt.Sprintf("**%s**\n요청자: %s\n시나리오: `%s`", mapName, by, scenarioID),
 			agent.ColorBlue,
 		)
 	}
@@ -143,43 +146,16 @@ func (s *MapChangeService) GetCurrentMap(instanceID string) (*MapMapping, string
 
 // getConfigPath returns the config file path for an instance
 func (s *MapChangeService) getConfigPath(instanceID string) string {
-	if instanceID == "default" {
-		workDir, _ := os.Getwd()
-		return workDir + "/server.json"
-	}
-
-	inst := s.instanceMgr.Get(instanceID)
-	if inst != nil && inst.ConfigPath != "" {
-		return inst.ConfigPath
+	if s.instanceMgr.Get(instanceID) == nil {
+		return ""
 	}
-
-	return ""
+	return s.instanceMgr.ConfigPath(instanceID)
 }
 
-// restartServer restarts the game server
-func (s *MapChangeService) restartServer(instanceID string) error {
-	// First, stop the server
-	if err := s.instanceMgr.Stop(instanceID); err != nil {
-		logs.GlobalLogs.Warn(fmt.Sprintf("[MapChange] 서버 중지 실패 (무시): %v", err))
-	}
-
-	// Wait a bit for clean shutdown
-	time.Sleep(2 * time.Second)
-
-	// Get the instance to retrieve stored args
-	inst := s.instanceMgr.Get(instanceID)
-	if inst == nil && instanceID != "default" {
-		return fmt.Errorf("인스턴스를 찾을 수 없습니다: %s", instanceID)
-	}
-
-	// Start with default args (the router will inject proper paths)
-	args := []string{"-server"}
-
-	if err := s.instanceMgr.Start(instanceID, args); err != nil {
-		return fmt.Errorf("서버 시작 실패: %w", err)
-	}
-
-	return nil
+// restartServer restarts the game server through its graceful shutdown sequence
+func (s *MapChangeService) restartServer(instanceID, mapName string, by server.Trigger) error {
+	opts := server.ShutdownOptions{Reason: fmt.Sprintf("맵 변경: %s", mapName), Trigger: by}
+	return s.instanceMgr.Restart(instanceID, opts)
 }
 
 // GetMappingManager returns the mapping manager for direct access
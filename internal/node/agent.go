@@ -0,0 +1,538 @@
+package node
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/astral/kg-server-web-gui/internal/agent"
+	"github.com/astral/kg-server-web-gui/internal/api/response"
+	"github.com/astral/kg-server-web-gui/internal/battleye"
+	"github.com/astral/kg-server-web-gui/internal/logs"
+	"github.com/astral/kg-server-web-gui/internal/monitor"
+	"github.com/astral/kg-server-web-gui/internal/steamcmd"
+	"github.com/gofiber/fiber/v2"
+	be "github.com/multiplay/go-battleye"
+)
+
+// consoleBufferLines is how many console lines per instance the agent keeps for the panel to fetch
+const consoleBufferLines = 2000
+
+// Agent serves process control, files, logs and SteamCMD of this host to a remote panel
+type Agent struct {
+	version  string
+	workDir  string // Root of every path the panel may touch
+	dataDir  string // <workDir>/data; instance workspaces live in data/instances/<id>
+	steamcmd *steamcmd.Manager
+
+	mu        sync.Mutex
+	processes map[string]*agentProcess
+}
+
+// agentProcess is the server process of one instance plus the console lines not yet fetched
+type agentProcess struct {
+	monitor *agent.ProcessMonitor
+	console *lineBuffer
+
+	rconMu   sync.Mutex
+	rcon     *battleye.Session // Kept while the panel follows the server messages
+	messages *lineBuffer       // Server messages of rcon
+}
+
+// lineBuffer keeps the latest lines under increasing sequence numbers for the panel to long-poll
+type lineBuffer struct {
+	limit int
+
+	mu     sync.Mutex
+	lines  []string
+	first  int64         // Sequence number of lines[0]
+	notify chan struct{} // Closed when a line arrives
+}
+
+func newLineBuffer(limit int) *lineBuffer {
+	return &lineBuffer{limit: limit, notify: make(chan struct{})}
+}
+
+// NewAgent creates the agent for workDir and reattaches to servers still running from a previous agent run
+func NewAgent(version, workDir string) *Agent {
+	a := &Agent{
+		version:   version,
+		workDir:   workDir,
+		dataDir:   filepath.Join(workDir, "data"),
+		steamcmd:  steamcmd.NewManager(workDir, filepath.Join(workDir, serverDirName)),
+		processes: make(map[string]*agentProcess),
+	}
+
+	entries, _ := os.ReadDir(a.instancesDir())
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(a.instancesDir(), e.Name(), agent.PIDFileName)); err != nil {
+			continue
+		}
+		p := a.process(e.Name())
+		if rec, err := p.monitor.Adopt(); err == nil && rec != nil {
+			logs.GlobalLogs.Info(fmt.Sprintf("[%s] 실행 중인 서버에 다시 연결했습니다 (PID %d)", e.Name(), rec.PID))
+		}
+	}
+	return a
+}
+
+func (a *Agent) instancesDir() string {
+	return filepath.Join(a.dataDir, "instances")
+}
+
+// process returns the process of an instance, creating its monitor on first use
+func (a *Agent) process(id string) *agentProcess {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if p, ok := a.processes[id]; ok {
+		return p
+	}
+	m := agent.NewProcessMonitor(agent.ServerBinaryName, filepath.Join(a.instancesDir(), id))
+	m.Label = id
+	p := &agentProcess{
+		monitor:  m,
+		console:  newLineBuffer(consoleBufferLines),
+		messages: newLineBuffer(rconMessageBuffer),
+	}
+	m.SubscribeLines(p.console.append)
+	a.processes[id] = p
+	return p
+}
+
+func (b *lineBuffer) append(line string) {
+	b.mu.Lock()
+	b.lines = append(b.lines, line)
+	if over := len(b.lines) - b.limit; over > 0 {
+		b.lines = b.lines[over:]
+		b.first += int64(over)
+	}
+	close(b.notify)
+	b.notify = make(chan struct{})
+	b.mu.Unlock()
+}
+
+// wake ends the waiting requests without a new line, e.g. to report a state change
+func (b *lineBuffer) wake() {
+	b.mu.Lock()
+	close(b.notify)
+	b.notify = make(chan struct{})
+	b.mu.Unlock()
+}
+
+// after returns the buffered lines from sequence number next on, waiting up to wait for new ones
+func (b *lineBuffer) after(next int64, wait time.Duration) ConsoleLines {
+	deadline := time.After(wait)
+	for {
+		b.mu.Lock()
+		end := b.first + int64(len(b.lines))
+		if next < b.first || next > end {
+			next = end // Fell behind the buffer, or the agent restarted: continue with new lines
+		}
+		if next < end || wait <= 0 {
+			out := ConsoleLines{Lines: append([]string{}, b.lines[next-b.first:]...), Next: end}
+			b.mu.Unlock()
+			return out
+		}
+		notify := b.notify
+		b.mu.Unlock()
+
+		select {
+		case <-notify:
+			wait = 0 // Return what is there now, even if it was only a wake
+		case <-deadline:
+			return ConsoleLines{Lines: []string{}, Next: next}
+		}
+	}
+}
+
+// LoadOrCreateToken returns the agent token kept in dataDir/agent_token, generating one on first use (created)
+func LoadOrCreateToken(dataDir string) (token string, created bool, err error) {
+	path := filepath.Join(dataDir, "agent_token")
+	if data, err := os.ReadFile(path); err == nil {
+		if token = strings.TrimSpace(string(data)); token != "" {
+			return token, false, nil
+		}
+	}
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", false, err
+	}
+	token = hex.EncodeToString(buf)
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return "", false, err
+	}
+	if err := os.WriteFile(path, []byte(token+"\n"), 0600); err != nil {
+		return "", false, err
+	}
+	return token, true, nil
+}
+
+// TokenAuth rejects requests without the agent's token
+func TokenAuth(token string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		got := strings.TrimPrefix(c.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			return c.Status(401).JSON(response.Error("unauthorized"))
+		}
+		return c.Next()
+	}
+}
+
+// Register adds the agent API to r
+func (a *Agent) Register(r fiber.Router) {
+	r.Get("/info", a.getInfo)
+
+	r.Get("/processes/:id", a.getStatus)
+	r.Post("/processes/:id/start", a.start)
+	r.Post("/processes/:id/terminate", a.terminate)
+	r.Post("/processes/:id/kill", a.kill)
+	r.Post("/processes/:id/adopt", a.adopt)
+	r.Get("/processes/:id/wait", a.wait)
+	r.Get("/processes/:id/resources", a.resources)
+	r.Get("/processes/:id/console", a.console)
+	r.Get("/processes/:id/logs", a.listLogs)
+	r.Get("/processes/:id/logs/tail", a.tailLog)
+	r.Get("/processes/:id/logs/:file", a.downloadLog)
+
+	r.Post("/rcon", a.rcon)
+	r.Put("/processes/:id/rcon", a.openRcon)
+	r.Get("/processes/:id/rcon/messages", a.rconMessages)
+	r.Delete("/processes/:id/rcon", a.closeRcon)
+
+	r.Get("/files", a.readFile)
+	r.Put("/files", a.writeFile)
+
+	r.Get("/steamcmd", a.steamcmdStatus)
+	r.Post("/steamcmd/update", a.steamcmdUpdate)
+}
+
+// instance resolves the :id of a request to its process
+func (a *Agent) instance(c *fiber.Ctx) (*agentProcess, error) {
+	id := c.Params("id")
+	if id == "" || id != filepath.Base(id) || id == "." || id == ".." {
+		return nil, fmt.Errorf("잘못된 서버 ID입니다: %s", id)
+	}
+	return a.process(id), nil
+}
+
+func (a *Agent) getInfo(c *fiber.Ctx) error {
+	host, _ := os.Hostname()
+	info := Info{
+		Version:   a.version,
+		Hostname:  host,
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+		WorkDir:   a.workDir,
+		Instances: []string{},
+	}
+	if entries, err := os.ReadDir(a.instancesDir()); err == nil {
+		for _, e := range entries {
+			if e.IsDir() {
+				info.Instances = append(info.Instances, e.Name())
+			}
+		}
+	}
+	info.System, _ = monitor.GetSystemStats()
+	return c.JSON(response.Success(info))
+}
+
+func (p *agentProcess) status() ProcessStatus {
+	running, pid, _ := p.monitor.IsRunning()
+	st := ProcessStatus{
+		Running:  running,
+		PID:      pid,
+		Record:   p.monitor.GetRecord(),
+		LastExit: p.monitor.LastExit(),
+	}
+	if t, err := p.monitor.LastOutput(); err == nil {
+		st.LastOutput = &t
+	}
+	return st
+}
+
+func (a *Agent) getStatus(c *fiber.Ctx) error {
+	p, err := a.instance(c)
+	if err != nil {
+		return c.Status(400).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(response.Success(p.status()))
+}
+
+func (a *Agent) start(c *fiber.Ctx) error {
+	p, err := a.instance(c)
+	if err != nil {
+		return c.Status(400).JSON(response.Error(err.Error()))
+	}
+	var req StartRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(response.Error(err.Error()))
+	}
+	if req.ServerDir == "" {
+		req.ServerDir = a.serverDir() // Installed through the agent's SteamCMD
+	}
+
+	ws := filepath.Join(a.instancesDir(), c.Params("id"))
+	profile := filepath.Join(ws, "profile")
+	if err := os.MkdirAll(profile, 0755); err != nil {
+		return c.Status(500).JSON(response.Error(err.Error()))
+	}
+	args := req.Args
+	if len(req.Config) > 0 {
+		if err := os.WriteFile(filepath.Join(ws, "server.json"), req.Config, 0644); err != nil {
+			return c.Status(500).JSON(response.Error(fmt.Sprintf("설정 파일 저장 실패: %v", err)))
+		}
+	}
+	addons := filepath.Join(a.workDir, "addons")
+	args = withDefaultArg(args, "-config", filepath.Join(ws, "server.json"))
+	args = withDefaultArg(args, "-profile", profile)
+	args = withDefaultArg(args, "-addonDownloadDir", addons)
+	args = withDefaultArg(args, "-addonsDir", addons)
+
+	p.monitor.SetLogRotation(req.Rotation)
+	if err := p.monitor.Start(filepath.Join(req.ServerDir, agent.ServerBinaryName), args); err != nil {
+		return c.Status(500).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(response.Success(p.status()))
+}
+
+// withDefaultArg appends flag and value unless the arguments already carry the flag
+func withDefaultArg(args []string, flag, value string) []string {
+	for _, a := range args {
+		if a == flag {
+			return args
+		}
+	}
+	return append(args, flag, value)
+}
+
+func (a *Agent) terminate(c *fiber.Ctx) error {
+	p, err := a.instance(c)
+	if err != nil {
+		return c.Status(400).JSON(response.Error(err.Error()))
+	}
+	if err := p.monitor.Terminate(); err != nil {
+		return c.Status(500).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(response.Success(p.status()))
+}
+
+func (a *Agent) kill(c *fiber.Ctx) error {
+	p, err := a.instance(c)
+	if err != nil {
+		return c.Status(400).JSON(response.Error(err.Error()))
+	}
+	if err := p.monitor.Kill(); err != nil {
+		return c.Status(500).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(response.Success(p.status()))
+}
+
+func (a *Agent) adopt(c *fiber.Ctx) error {
+	p, err := a.instance(c)
+	if err != nil {
+		return c.Status(400).JSON(response.Error(err.Error()))
+	}
+	// Already tracked: adopted when the agent started, or launched by this agent
+	if rec := p.monitor.GetRecord(); rec != nil {
+		return c.JSON(response.Success(rec))
+	}
+	rec, err := p.monitor.Adopt()
+	if err != nil {
+		return c.Status(500).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(response.Success(rec))
+}
+
+func (a *Agent) wait(c *fiber.Ctx) error {
+	p, err := a.instance(c)
+	if err != nil {
+		return c.Status(400).JSON(response.Error(err.Error()))
+	}
+	timeout := time.Duration(c.QueryInt("timeoutMs", 5000)) * time.Millisecond
+	if timeout > 10*time.Minute {
+		timeout = 10 * time.Minute
+	}
+	return c.JSON(response.Success(fiber.Map{"exited": p.monitor.WaitExit(timeout)}))
+}
+
+func (a *Agent) resources(c *fiber.Ctx) error {
+	p, err := a.instance(c)
+	if err != nil {
+		return c.Status(400).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(response.Success(p.monitor.GetResourceHistory()))
+}
+
+func (a *Agent) console(c *fiber.Ctx) error {
+	p, err := a.instance(c)
+	if err != nil {
+		return c.Status(400).JSON(response.Error(err.Error()))
+	}
+	wait := time.Duration(c.QueryInt("waitMs", 0)) * time.Millisecond
+	if wait > 30*time.Second {
+		wait = 30 * time.Second
+	}
+	return c.JSON(response.Success(p.console.after(int64(c.QueryInt("after", -1)), wait)))
+}
+
+func (a *Agent) listLogs(c *fiber.Ctx) error {
+	p, err := a.instance(c)
+	if err != nil {
+		return c.Status(400).JSON(response.Error(err.Error()))
+	}
+	files, err := p.monitor.ListLogFiles()
+	if err != nil {
+		return c.Status(500).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(response.Success(files))
+}
+
+func (a *Agent) tailLog(c *fiber.Ctx) error {
+	p, err := a.instance(c)
+	if err != nil {
+		return c.Status(400).JSON(response.Error(err.Error()))
+	}
+	lines, err := p.monitor.TailLogFile(c.Query("file"), c.QueryInt("lines", 200))
+	if err != nil {
+		return c.Status(404).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(response.Success(lines))
+}
+
+func (a *Agent) downloadLog(c *fiber.Ctx) error {
+	p, err := a.instance(c)
+	if err != nil {
+		return c.Status(400).JSON(response.Error(err.Error()))
+	}
+	f, err := p.monitor.OpenLogFile(c.Params("file"))
+	if err != nil {
+		return c.Status(404).JSON(response.Error(err.Error()))
+	}
+	return c.SendStream(f)
+}
+
+func (a *Agent) rcon(c *fiber.Ctx) error {
+	var req RconRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(response.Error(err.Error()))
+	}
+	address, err := localRconAddress(req.Address)
+	if err != nil {
+		return c.Status(400).JSON(response.Error(err.Error()))
+	}
+
+	client, err := be.NewClient(address, req.Password)
+	if err != nil {
+		return c.Status(502).JSON(response.Error(fmt.Sprintf("failed to connect to BattlEye RCON: %v", err)))
+	}
+	defer client.Close()
+	resp, err := client.Exec(req.Command)
+	if err != nil {
+		return c.Status(502).JSON(response.Error(fmt.Sprintf("failed to execute command: %v", err)))
+	}
+	return c.JSON(response.Success(resp))
+}
+
+// resolvePath maps a path from the panel into the agent's working directory
+func (a *Agent) resolvePath(p string) (string, error) {
+	if p == "" {
+		return a.workDir, nil
+	}
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(a.workDir, p)
+	}
+	p = filepath.Clean(p)
+	rel, err := filepath.Rel(a.workDir, p)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("작업 폴더 밖의 경로입니다: %s", p)
+	}
+	return p, nil
+}
+
+// readFile returns a file's contents, or the listing of a directory
+func (a *Agent) readFile(c *fiber.Ctx) error {
+	path, err := a.resolvePath(c.Query("path"))
+	if err != nil {
+		return c.Status(403).JSON(response.Error(err.Error()))
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return c.Status(404).JSON(response.Error(err.Error()))
+	}
+	if !info.IsDir() {
+		return c.SendFile(path)
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return c.Status(500).JSON(response.Error(err.Error()))
+	}
+	list := make([]FileEntry, 0, len(entries))
+	for _, e := range entries {
+		fi, err := e.Info()
+		if err != nil {
+			continue
+		}
+		list = append(list, FileEntry{Name: e.Name(), Size: fi.Size(), Modified: fi.ModTime(), IsDir: e.IsDir()})
+	}
+	return c.JSON(response.Success(list))
+}
+
+// writeFile replaces a file with the request body, writing through a temp file
+func (a *Agent) writeFile(c *fiber.Ctx) error {
+	path, err := a.resolvePath(c.Query("path"))
+	if err != nil {
+		return c.Status(403).JSON(response.Error(err.Error()))
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return c.Status(500).JSON(response.Error(err.Error()))
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, c.Body(), 0644); err != nil {
+		return c.Status(500).JSON(response.Error(err.Error()))
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return c.Status(500).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(response.Success(fiber.Map{"path": path, "size": len(c.Body())}))
+}
+
+// serverDir is where the agent's SteamCMD installs the server
+func (a *Agent) serverDir() string {
+	return filepath.Join(a.workDir, serverDirName)
+}
+
+func (a *Agent) steamcmdStatus(c *fiber.Ctx) error {
+	return c.JSON(response.Success(SteamCMDStatus{
+		Running:           a.steamcmd.IsRunning(),
+		ServerInstalled:   a.steamcmd.CheckInstalled(),
+		SteamCMDInstalled: a.steamcmd.SteamCMDInstalled(),
+	}))
+}
+
+func (a *Agent) steamcmdUpdate(c *fiber.Ctx) error {
+	var req struct {
+		Experimental bool `json:"experimental"`
+	}
+	c.BodyParser(&req)
+	if a.steamcmd.IsRunning() {
+		return c.Status(409).JSON(response.Error("SteamCMD가 이미 실행 중입니다"))
+	}
+	go func() {
+		if err := a.steamcmd.UpdateServer(req.Experimental); err != nil {
+			logs.GlobalLogs.Error(fmt.Sprintf("[SteamCMD] 서버 업데이트 실패: %v", err))
+		}
+	}()
+	return c.Status(202).JSON(response.Success(fiber.Map{"status": "started"}))
+}
@@ -0,0 +1,95 @@
+package node
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/astral/kg-server-web-gui/internal/api/response"
+	"github.com/astral/kg-server-web-gui/internal/battleye"
+	"github.com/gofiber/fiber/v2"
+)
+
+// rconMessageBuffer is how many server messages per instance the agent keeps for the panel to fetch
+const rconMessageBuffer = 500
+
+// localRconAddress turns the RCON address of a server.json into one reachable from the agent (0.0.0.0 means loopback)
+func localRconAddress(address string) (string, error) {
+	host, port, ok := strings.Cut(address, ":")
+	if !ok {
+		return "", fmt.Errorf("잘못된 RCON 주소입니다: %s", address)
+	}
+	if host == "" || host == "0.0.0.0" {
+		host = "127.0.0.1"
+	}
+	return host + ":" + port, nil
+}
+
+// openRcon keeps an RCON session to an instance's server so its server messages can be fetched;
+// an open session with the same credentials is kept
+func (a *Agent) openRcon(c *fiber.Ctx) error {
+	p, err := a.instance(c)
+	if err != nil {
+		return c.Status(400).JSON(response.Error(err.Error()))
+	}
+	var req RconRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(response.Error(err.Error()))
+	}
+	address, err := localRconAddress(req.Address)
+	if err != nil {
+		return c.Status(400).JSON(response.Error(err.Error()))
+	}
+
+	p.rconMu.Lock()
+	defer p.rconMu.Unlock()
+	if p.rcon == nil || !p.rcon.Matches(address, req.Password) {
+		if p.rcon != nil {
+			p.rcon.Close()
+		}
+		p.rcon = battleye.NewSession(address, req.Password, battleye.Options{Label: c.Params("id")})
+		p.rcon.Subscribe(p.messages.append)
+		p.rcon.OnStateChange(func(battleye.Status) { p.messages.wake() })
+	}
+	return c.JSON(response.Success(p.rcon.Status()))
+}
+
+// rconMessages returns the server messages after sequence number ?after=, waiting up to ?waitMs= for new ones
+// or for the session to leave the panel's last known ?state=
+func (a *Agent) rconMessages(c *fiber.Ctx) error {
+	p, err := a.instance(c)
+	if err != nil {
+		return c.Status(400).JSON(response.Error(err.Error()))
+	}
+	p.rconMu.Lock()
+	session := p.rcon
+	p.rconMu.Unlock()
+	if session == nil {
+		return c.Status(404).JSON(response.Error("RCON 세션이 열려 있지 않습니다"))
+	}
+
+	wait := time.Duration(c.QueryInt("waitMs", 0)) * time.Millisecond
+	if wait > 30*time.Second {
+		wait = 30 * time.Second
+	}
+	if session.Status().State != c.Query("state") {
+		wait = 0
+	}
+	lines := p.messages.after(int64(c.QueryInt("after", -1)), wait)
+	return c.JSON(response.Success(RconMessages{Messages: lines.Lines, Next: lines.Next, Status: session.Status()}))
+}
+
+// closeRcon ends the RCON session of an instance, if any
+func (a *Agent) closeRcon(c *fiber.Ctx) error {
+	p, err := a.instance(c)
+	if err != nil {
+		return c.Status(400).JSON(response.Error(err.Error()))
+	}
+	p.rconMu.Lock()
+	if p.rcon != nil {
+		p.rcon.Close()
+		p.rcon = nil
+	}
+	p.rconMu.Unlock()
+	return c.JSON(response.Success(nil))
+}
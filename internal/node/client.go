@@ -0,0 +1,285 @@
+package node
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/astral/kg-server-web-gui/internal/agent"
+	"github.com/astral/kg-server-web-gui/internal/battleye"
+)
+
+// requestTimeout bounds agent calls that do not wait on the server process
+const requestTimeout = 15 * time.Second
+
+// Client talks to the API of an agent node
+type Client struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+func NewClient(baseURL, token string) *Client {
+	return &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		token:   token,
+		http:    &http.Client{},
+	}
+}
+
+// Host returns the host name of the agent (for ports that are queried directly, like A2S)
+func (c *Client) Host() string {
+	u, err := url.Parse(c.baseURL)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
+
+// send performs a request and returns the raw response; the caller closes the body
+func (c *Client) send(ctx context.Context, method, path string, body io.Reader, contentType string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+"/agent"+path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("에이전트 연결 실패: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		var envelope struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&envelope)
+		if envelope.Error == "" {
+			envelope.Error = resp.Status
+		}
+		return nil, fmt.Errorf("에이전트 오류: %s", envelope.Error)
+	}
+	return resp, nil
+}
+
+// call sends in as JSON and decodes the data of the agent's response into out
+func (c *Client) call(timeout time.Duration, method, path string, in, out any) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var body io.Reader
+	contentType := ""
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body, contentType = bytes.NewReader(data), "application/json"
+	}
+	resp, err := c.send(ctx, method, path, body, contentType)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if out == nil {
+		return nil
+	}
+	envelope := struct {
+		Data any `json:"data"`
+	}{Data: out}
+	return json.NewDecoder(resp.Body).Decode(&envelope)
+}
+
+// Info returns the agent's host description
+func (c *Client) Info() (*Info, error) {
+	var info Info
+	if err := c.call(requestTimeout, http.MethodGet, "/info", nil, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+func processPath(id, suffix string) string {
+	return "/processes/" + url.PathEscape(id) + suffix
+}
+
+// Status returns the state of an instance's process
+func (c *Client) Status(id string) (*ProcessStatus, error) {
+	var st ProcessStatus
+	if err := c.call(requestTimeout, http.MethodGet, processPath(id, ""), nil, &st); err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
+// Start launches an instance's server on the agent
+func (c *Client) Start(id string, req StartRequest) (*ProcessStatus, error) {
+	var st ProcessStatus
+	if err := c.call(time.Minute, http.MethodPost, processPath(id, "/start"), req, &st); err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
+// Terminate asks an instance's server to exit
+func (c *Client) Terminate(id string) error {
+	return c.call(requestTimeout, http.MethodPost, processPath(id, "/terminate"), nil, nil)
+}
+
+// Kill force kills an instance's server
+func (c *Client) Kill(id string) error {
+	return c.call(requestTimeout, http.MethodPost, processPath(id, "/kill"), nil, nil)
+}
+
+// Adopt makes the agent track a server it launched before restarting
+func (c *Client) Adopt(id string) (*agent.ProcessRecord, error) {
+	var rec *agent.ProcessRecord
+	if err := c.call(requestTimeout, http.MethodPost, processPath(id, "/adopt"), nil, &rec); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+// WaitExit waits on the agent until an instance's server is gone or the timeout elapses
+func (c *Client) WaitExit(id string, timeout time.Duration) (bool, error) {
+	var out struct {
+		Exited bool `json:"exited"`
+	}
+	path := processPath(id, fmt.Sprintf("/wait?timeoutMs=%d", timeout.Milliseconds()))
+	if err := c.call(timeout+requestTimeout, http.MethodGet, path, nil, &out); err != nil {
+		return false, err
+	}
+	return out.Exited, nil
+}
+
+// Resources returns the CPU/memory history of an instance's server
+func (c *Client) Resources(id string) ([]agent.ResourceData, error) {
+	var history []agent.ResourceData
+	err := c.call(requestTimeout, http.MethodGet, processPath(id, "/resources"), nil, &history)
+	return history, err
+}
+
+// Console returns the console lines after sequence number after, waiting up to wait for new ones
+func (c *Client) Console(id string, after int64, wait time.Duration) (*ConsoleLines, error) {
+	var out ConsoleLines
+	path := processPath(id, fmt.Sprintf("/console?after=%d&waitMs=%d", after, wait.Milliseconds()))
+	if err := c.call(wait+requestTimeout, http.MethodGet, path, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListLogs returns an instance's console log files
+func (c *Client) ListLogs(id string) ([]agent.LogFile, error) {
+	var files []agent.LogFile
+	err := c.call(requestTimeout, http.MethodGet, processPath(id, "/logs"), nil, &files)
+	return files, err
+}
+
+// TailLog returns the last n lines of a console log file
+func (c *Client) TailLog(id, name string, n int) ([]string, error) {
+	var lines []string
+	path := processPath(id, fmt.Sprintf("/logs/tail?file=%s&lines=%d", url.QueryEscape(name), n))
+	err := c.call(requestTimeout, http.MethodGet, path, nil, &lines)
+	return lines, err
+}
+
+// OpenLog streams a console log file; the caller closes it
+func (c *Client) OpenLog(id, name string) (io.ReadCloser, error) {
+	if name == "" {
+		name = agent.ConsoleLogName
+	}
+	resp, err := c.send(context.Background(), http.MethodGet, processPath(id, "/logs/"+url.PathEscape(name)), nil, "")
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// Rcon runs an RCON command against a server on the agent's host
+func (c *Client) Rcon(req RconRequest) (string, error) {
+	var out string
+	err := c.call(requestTimeout, http.MethodPost, "/rcon", req, &out)
+	return out, err
+}
+
+// OpenRcon makes the agent keep an RCON session to an instance's server (Command is not used)
+func (c *Client) OpenRcon(id string, req RconRequest) (*battleye.Status, error) {
+	var st battleye.Status
+	if err := c.call(requestTimeout, http.MethodPut, processPath(id, "/rcon"), req, &st); err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
+// RconMessages returns the server messages after sequence number after, waiting up to wait for new ones
+// or for the session to leave state
+func (c *Client) RconMessages(id string, after int64, state string, wait time.Duration) (*RconMessages, error) {
+	var out RconMessages
+	path := processPath(id, fmt.Sprintf("/rcon/messages?after=%d&state=%s&waitMs=%d", after, url.QueryEscape(state), wait.Milliseconds()))
+	if err := c.call(wait+requestTimeout, http.MethodGet, path, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CloseRcon ends the agent's RCON session to an instance's server
+func (c *Client) CloseRcon(id string) error {
+	return c.call(requestTimeout, http.MethodDelete, processPath(id, "/rcon"), nil, nil)
+}
+
+// GetFile returns a file on the agent (relative to its working directory) with its content type;
+// for a directory it is the agent's JSON listing
+func (c *Client) GetFile(path string) ([]byte, string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	resp, err := c.send(ctx, http.MethodGet, "/files?path="+url.QueryEscape(path), nil, "")
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	return data, resp.Header.Get("Content-Type"), err
+}
+
+// ListDir returns the entries of a directory on the agent
+func (c *Client) ListDir(path string) ([]FileEntry, error) {
+	var entries []FileEntry
+	err := c.call(requestTimeout, http.MethodGet, "/files?path="+url.QueryEscape(path), nil, &entries)
+	return entries, err
+}
+
+// WriteFile replaces a file on the agent
+func (c *Client) WriteFile(path string, data []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	resp, err := c.send(ctx, http.MethodPut, "/files?path="+url.QueryEscape(path), bytes.NewReader(data), "application/octet-stream")
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// SteamCMD returns the state of the server installation on the agent
+func (c *Client) SteamCMD() (*SteamCMDStatus, error) {
+	var st SteamCMDStatus
+	if err := c.call(requestTimeout, http.MethodGet, "/steamcmd", nil, &st); err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
+// UpdateServer starts a SteamCMD update of the server on the agent
+func (c *Client) UpdateServer(experimental bool) error {
+	return c.call(requestTimeout, http.MethodPost, "/steamcmd/update", map[string]bool{"experimental": experimental}, nil)
+}
@@ -0,0 +1,81 @@
+// Package node runs this binary as a headless agent (-mode agent) that a panel on another host
+// drives over HTTP, and provides the panel's client for such agents.
+package node
+
+import (
+	"time"
+
+	"github.com/astral/kg-server-web-gui/internal/agent"
+	"github.com/astral/kg-server-web-gui/internal/battleye"
+	"github.com/astral/kg-server-web-gui/internal/monitor"
+)
+
+// DefaultPort is the port the agent listens on unless -port is given
+const DefaultPort = "3100"
+
+// serverDirName is the directory under the agent's working directory SteamCMD installs the server to
+const serverDirName = "server"
+
+// Info describes an agent node
+type Info struct {
+	Version   string               `json:"version"`
+	Hostname  string               `json:"hostname"`
+	OS        string               `json:"os"`
+	Arch      string               `json:"arch"`
+	WorkDir   string               `json:"workDir"`
+	Instances []string             `json:"instances"` // Instances with a workspace on the node
+	System    *monitor.SystemStats `json:"system,omitempty"`
+}
+
+// StartRequest launches the server of an instance on the agent.
+// -config, -profile, -addonsDir and -addonDownloadDir are filled in with the node's own paths when missing.
+type StartRequest struct {
+	ServerDir string            `json:"serverDir"` // Directory holding the server binary (empty = the agent's SteamCMD install)
+	Args      []string          `json:"args"`
+	Config    []byte            `json:"config,omitempty"` // server.json written to the instance workspace before starting
+	Rotation  agent.LogRotation `json:"rotation"`
+}
+
+// ProcessStatus is the state of an instance's server process on the agent
+type ProcessStatus struct {
+	Running    bool                 `json:"running"`
+	PID        int                  `json:"pid"`
+	Record     *agent.ProcessRecord `json:"record,omitempty"`
+	LastExit   *agent.ExitInfo      `json:"lastExit,omitempty"`
+	LastOutput *time.Time           `json:"lastOutput,omitempty"` // Last write to the console log
+}
+
+// ConsoleLines are the console lines an agent buffered after a sequence number
+type ConsoleLines struct {
+	Lines []string `json:"lines"`
+	Next  int64    `json:"next"` // Sequence number to ask for next
+}
+
+// RconRequest runs a BattlEye RCON command against a server on the agent's host
+type RconRequest struct {
+	Address  string `json:"address"` // host:port from server.json (0.0.0.0 means loopback)
+	Password string `json:"password"`
+	Command  string `json:"command"`
+}
+
+// RconMessages are the BattlEye server messages an agent received after a sequence number
+type RconMessages struct {
+	Messages []string        `json:"messages"`
+	Next     int64           `json:"next"`   // Sequence number to ask for next
+	Status   battleye.Status `json:"status"` // State of the agent's RCON session
+}
+
+// FileEntry is one entry of a directory listing on the agent
+type FileEntry struct {
+	Name     string    `json:"name"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+	IsDir    bool      `json:"isDir"`
+}
+
+// SteamCMDStatus reports the server installation on the agent
+type SteamCMDStatus struct {
+	Running           bool `json:"running"`
+	ServerInstalled   bool `json:"serverInstalled"`
+	SteamCMDInstalled bool `json:"steamcmdInstalled"`
+}
@@ -0,0 +1,348 @@
+package node
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/astral/kg-server-web-gui/internal/agent"
+	"github.com/astral/kg-server-web-gui/internal/logs"
+)
+
+// pollInterval is how often a remote process is checked for an exit
+const pollInterval = 2 * time.Second
+
+// consoleWait is how long a console request waits on the agent for new lines
+const consoleWait = 20 * time.Second
+
+// errStatusPending is reported by IsRunning until the node answered a first status request
+var errStatusPending = errors.New("원격 서버 상태를 아직 확인하지 못했습니다")
+
+// RemoteProcess controls the server of an instance running on an agent node
+type RemoteProcess struct {
+	id     string
+	client *Client
+
+	// Config returns the server.json sent along with every start
+	Config func() ([]byte, error)
+
+	mu        sync.Mutex
+	rotation  agent.LogRotation
+	tracked   *agent.ProcessRecord // Process seen running by the last check
+	running   bool                 // Last status the node reported, served by IsRunning
+	pid       int
+	statusErr error // Why the last status request failed (nil = running/pid are current)
+	lastExit  *agent.ExitInfo
+	lastOut   *time.Time
+	onExit    func(rec *agent.ProcessRecord, err error)
+	lineSubs  map[int]func(line string)
+	nextSub   int
+	tailing   bool
+	closed    chan struct{}
+}
+
+var _ agent.ProcessController = (*RemoteProcess)(nil)
+
+// NewRemoteProcess creates the controller of instance id on the agent behind client
+func NewRemoteProcess(id string, client *Client) *RemoteProcess {
+	r := &RemoteProcess{
+		id:        id,
+		client:    client,
+		rotation:  agent.DefaultLogRotation(),
+		lineSubs:  make(map[int]func(line string)),
+		closed:    make(chan struct{}),
+		statusErr: errStatusPending,
+	}
+	go r.poll()
+	return r
+}
+
+// Close stops watching the remote process (the process itself is left alone)
+func (r *RemoteProcess) Close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	select {
+	case <-r.closed:
+	default:
+		close(r.closed)
+	}
+}
+
+// SetClient points the controller at a node whose address or token changed
+func (r *RemoteProcess) SetClient(client *Client) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.client = client
+}
+
+func (r *RemoteProcess) conn() *Client {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.client
+}
+
+// remoteExit is the exit of a process on a node; ExitCode matches *exec.ExitError
+type remoteExit struct {
+	code int
+	msg  string
+}
+
+func (e *remoteExit) Error() string { return e.msg }
+func (e *remoteExit) ExitCode() int { return e.code }
+
+// exitError turns a remote ExitInfo back into the error a local Wait() would have returned
+func exitError(info *agent.ExitInfo) error {
+	switch {
+	case info == nil:
+		return nil
+	case info.ExitCode != nil && *info.ExitCode != 0:
+		msg := info.Error
+		if msg == "" {
+			msg = fmt.Sprintf("exit status %d", *info.ExitCode)
+		}
+		return &remoteExit{code: *info.ExitCode, msg: msg}
+	case info.Error != "":
+		return fmt.Errorf("%s", info.Error)
+	}
+	return nil
+}
+
+// poll keeps the process status of the node current and detects the exit of the tracked process
+// like a local monitor would
+func (r *RemoteProcess) poll() {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		st, err := r.conn().Status(r.id)
+		if err != nil {
+			// Node unreachable: keep the last known state, IsRunning reports the error
+			r.mu.Lock()
+			r.statusErr = err
+			r.mu.Unlock()
+		} else {
+			r.observe(st)
+		}
+		select {
+		case <-r.closed:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// observe updates the tracked process from a status and fires the exit handler when it ended
+func (r *RemoteProcess) observe(st *ProcessStatus) {
+	r.mu.Lock()
+	prev := r.tracked
+	r.running, r.pid, r.statusErr = st.Running, st.PID, nil
+	r.lastExit, r.lastOut = st.LastExit, st.LastOutput
+	if st.Running {
+		r.tracked = st.Record
+	} else {
+		r.tracked = nil
+	}
+	onExit := r.onExit
+	r.mu.Unlock()
+
+	if prev != nil && (!st.Running || st.Record == nil || st.Record.PID != prev.PID) && onExit != nil {
+		logs.GlobalLogs.Info(fmt.Sprintf("[%s] 원격 서버가 종료되었습니다", r.id))
+		onExit(prev, exitError(st.LastExit))
+	}
+}
+
+// Start launches the server on the node; path is the server directory there (empty = the agent's SteamCMD install)
+func (r *RemoteProcess) Start(path string, args []string) error {
+	req := StartRequest{ServerDir: path, Args: args}
+	if r.Config != nil {
+		cfg, err := r.Config()
+		if err != nil {
+			return fmt.Errorf("설정 파일 로드 실패: %w", err)
+		}
+		req.Config = cfg
+	}
+	r.mu.Lock()
+	req.Rotation = r.rotation
+	r.mu.Unlock()
+
+	st, err := r.conn().Start(r.id, req)
+	if err != nil {
+		return err
+	}
+	r.observe(st)
+	r.ensureTail()
+	return nil
+}
+
+// IsRunning returns the status of the last poll; it never waits on the node, so it is safe under locks
+func (r *RemoteProcess) IsRunning() (bool, int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.statusErr != nil {
+		return false, 0, r.statusErr
+	}
+	return r.running, r.pid, nil
+}
+
+func (r *RemoteProcess) Terminate() error {
+	return r.conn().Terminate(r.id)
+}
+
+func (r *RemoteProcess) Kill() error {
+	return r.conn().Kill(r.id)
+}
+
+func (r *RemoteProcess) WaitExit(timeout time.Duration) bool {
+	exited, err := r.conn().WaitExit(r.id, timeout)
+	if err != nil {
+		return false
+	}
+	if exited {
+		// Report the exit now instead of at the next poll
+		if st, err := r.conn().Status(r.id); err == nil {
+			r.observe(st)
+		}
+	}
+	return exited
+}
+
+func (r *RemoteProcess) Adopt() (*agent.ProcessRecord, error) {
+	rec, err := r.conn().Adopt(r.id)
+	if err != nil || rec == nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	r.tracked = rec
+	r.running, r.pid, r.statusErr = true, rec.PID, nil
+	r.mu.Unlock()
+	r.ensureTail()
+	return rec, nil
+}
+
+func (r *RemoteProcess) GetRecord() *agent.ProcessRecord {
+	st, err := r.conn().Status(r.id)
+	if err != nil {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		return r.tracked
+	}
+	return st.Record
+}
+
+// LastExit returns the exit the last poll reported
+func (r *RemoteProcess) LastExit() *agent.ExitInfo {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.lastExit
+}
+
+func (r *RemoteProcess) GetResourceHistory() []agent.ResourceData {
+	history, err := r.conn().Resources(r.id)
+	if err != nil {
+		return nil
+	}
+	return history
+}
+
+func (r *RemoteProcess) SetExitHandler(fn func(rec *agent.ProcessRecord, err error)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onExit = fn
+}
+
+func (r *RemoteProcess) SetLogRotation(rot agent.LogRotation) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rotation = rot
+}
+
+// SubscribeLines registers fn for every console line of the remote server; call the returned func to unsubscribe
+func (r *RemoteProcess) SubscribeLines(fn func(line string)) func() {
+	r.mu.Lock()
+	id := r.nextSub
+	r.nextSub++
+	r.lineSubs[id] = fn
+	r.mu.Unlock()
+	r.ensureTail()
+
+	return func() {
+		r.mu.Lock()
+		delete(r.lineSubs, id)
+		r.mu.Unlock()
+	}
+}
+
+// ensureTail starts following the remote console unless it is followed already
+func (r *RemoteProcess) ensureTail() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.tailing {
+		return
+	}
+	r.tailing = true
+	go r.tail()
+}
+
+// tail long-polls the agent for console lines while the process is tracked or anyone is subscribed,
+// mirroring them into the panel log like a local monitor does
+func (r *RemoteProcess) tail() {
+	next := int64(-1) // Only lines written from now on
+	for {
+		r.mu.Lock()
+		subs := make([]func(string), 0, len(r.lineSubs))
+		for _, fn := range r.lineSubs {
+			subs = append(subs, fn)
+		}
+		if len(subs) == 0 && r.tracked == nil {
+			r.tailing = false
+			r.mu.Unlock()
+			return
+		}
+		r.mu.Unlock()
+
+		select {
+		case <-r.closed:
+			return
+		default:
+		}
+
+		out, err := r.conn().Console(r.id, next, consoleWait)
+		if err != nil {
+			time.Sleep(pollInterval)
+			continue
+		}
+		next = out.Next
+		for _, line := range out.Lines {
+			logs.GlobalLogs.Info(fmt.Sprintf("[%s] %s", r.id, line))
+			for _, fn := range subs {
+				fn(line)
+			}
+		}
+	}
+}
+
+func (r *RemoteProcess) ListLogFiles() ([]agent.LogFile, error) {
+	return r.conn().ListLogs(r.id)
+}
+
+func (r *RemoteProcess) TailLogFile(name string, n int) ([]string, error) {
+	return r.conn().TailLog(r.id, name, n)
+}
+
+func (r *RemoteProcess) OpenLogFile(name string) (io.ReadCloser, error) {
+	return r.conn().OpenLog(r.id, name)
+}
+
+// LastOutput returns the console activity the last poll reported
+func (r *RemoteProcess) LastOutput() (time.Time, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.statusErr != nil {
+		return time.Time{}, r.statusErr
+	}
+	if r.lastOut == nil {
+		return time.Time{}, fmt.Errorf("no console output yet")
+	}
+	return *r.lastOut, nil
+}
@@ -0,0 +1,160 @@
+package node
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/astral/kg-server-web-gui/internal/battleye"
+	"github.com/astral/kg-server-web-gui/internal/logs"
+)
+
+// RemoteRcon follows the RCON session an agent keeps to a server on its node. It offers what the
+// panel uses of a local battleye.Session, so the server messages of remote servers stream in too.
+type RemoteRcon struct {
+	id       string
+	address  string
+	password string
+	process  *RemoteProcess
+
+	mu      sync.Mutex
+	status  battleye.Status
+	subs    map[int]func(msg string)
+	nextSub int
+	closed  chan struct{}
+	once    sync.Once
+}
+
+// OpenRcon makes the agent keep an RCON session to the server and follows its server messages until Close
+func (r *RemoteProcess) OpenRcon(address, password string) *RemoteRcon {
+	s := &RemoteRcon{
+		id:       r.id,
+		address:  address,
+		password: password,
+		process:  r,
+		status:   battleye.Status{Address: address, State: battleye.StateConnecting, Since: time.Now()},
+		subs:     make(map[int]func(string)),
+		closed:   make(chan struct{}),
+	}
+	go s.follow()
+	return s
+}
+
+// Matches reports whether the session uses the given credentials
+func (s *RemoteRcon) Matches(address, password string) bool {
+	return s.address == address && s.password == password
+}
+
+// Status returns the state of the agent's session as last reported
+func (s *RemoteRcon) Status() battleye.Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.status
+}
+
+// Subscribe registers fn for every server message; call the returned func to unsubscribe
+func (s *RemoteRcon) Subscribe(fn func(msg string)) func() {
+	s.mu.Lock()
+	id := s.nextSub
+	s.nextSub++
+	s.subs[id] = fn
+	s.mu.Unlock()
+	return func() {
+		s.mu.Lock()
+		delete(s.subs, id)
+		s.mu.Unlock()
+	}
+}
+
+// Exec runs a command through the agent
+func (s *RemoteRcon) Exec(cmd string) (string, error) {
+	return s.process.conn().Rcon(RconRequest{Address: s.address, Password: s.password, Command: cmd})
+}
+
+// Close stops following the server messages and ends the agent's session
+func (s *RemoteRcon) Close() {
+	s.once.Do(func() {
+		close(s.closed)
+		go func() {
+			if err := s.process.conn().CloseRcon(s.id); err != nil {
+				logs.GlobalLogs.Warn(fmt.Sprintf("[%s] 원격 RCON 세션 종료 실패: %v", s.id, err))
+			}
+		}()
+	})
+}
+
+func (s *RemoteRcon) done() bool {
+	select {
+	case <-s.closed:
+		return true
+	case <-s.process.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+// follow opens the session on the agent (again after errors, e.g. when the agent restarted) and
+// long-polls its server messages
+func (s *RemoteRcon) follow() {
+	open := false
+	next := int64(-1) // Only messages from now on
+	for !s.done() {
+		client := s.process.conn()
+		if !open {
+			st, err := client.OpenRcon(s.id, RconRequest{Address: s.address, Password: s.password})
+			if err != nil {
+				s.failed(err)
+				continue
+			}
+			s.setStatus(*st)
+			open = true
+		}
+
+		out, err := client.RconMessages(s.id, next, s.Status().State, consoleWait)
+		if err != nil {
+			open = false
+			s.failed(err)
+			continue
+		}
+		next = out.Next
+		s.setStatus(out.Status)
+		if s.done() {
+			return
+		}
+
+		s.mu.Lock()
+		subs := make([]func(string), 0, len(s.subs))
+		for _, fn := range s.subs {
+			subs = append(subs, fn)
+		}
+		s.mu.Unlock()
+		for _, msg := range out.Messages {
+			for _, fn := range subs {
+				fn(msg)
+			}
+		}
+	}
+}
+
+func (s *RemoteRcon) setStatus(st battleye.Status) {
+	s.mu.Lock()
+	s.status = st
+	s.mu.Unlock()
+}
+
+// failed records an agent error as a disconnected session and waits before the next attempt
+func (s *RemoteRcon) failed(err error) {
+	s.mu.Lock()
+	if s.status.State != battleye.StateDisconnected {
+		s.status.State, s.status.Since = battleye.StateDisconnected, time.Now()
+	}
+	s.status.LastError = err.Error()
+	s.mu.Unlock()
+
+	select {
+	case <-s.closed:
+	case <-s.process.closed:
+	case <-time.After(pollInterval):
+	}
+}
@@ -2,43 +2,42 @@ package rcon
 
 import (
 	"fmt"
-	"regexp"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/astral/kg-server-web-gui/internal/battleye"
 	"github.com/astral/kg-server-web-gui/internal/logs"
 	"github.com/astral/kg-server-web-gui/internal/mapchange"
 	"github.com/astral/kg-server-web-gui/internal/server"
 )
 
-// ChatMonitor monitors in-game chat for commands via RCON
+// ChatMonitor reacts to in-game chat commands pushed over the instance's RCON session
 type ChatMonitor struct {
-	instanceMgr  *server.InstanceManager
-	mapService   *mapchange.MapChangeService
-	instanceID   string
-	pollInterval time.Duration
-	running      bool
-	stopChan     chan struct{}
-	mu           sync.RWMutex
-
-	// Track last message to avoid duplicates
-	lastMessageTime time.Time
+	instanceMgr *server.InstanceManager
+	mapService  *mapchange.MapChangeService
+	instanceID  string
+	running     bool
+	unsubscribe func()
+	stopChan    chan struct{}
+	mu          sync.RWMutex
 }
 
+// sessionCheckInterval is how often the monitor makes sure the watched server has an RCON session
+const sessionCheckInterval = 30 * time.Second
+
 // NewChatMonitor creates a new chat monitor
 func NewChatMonitor(im *server.InstanceManager, ms *mapchange.MapChangeService) *ChatMonitor {
 	return &ChatMonitor{
-		instanceMgr:  im,
-		mapService:   ms,
-		instanceID:   "default",
-		pollInterval: 5 * time.Second, // Poll every 5 seconds
-		stopChan:     make(chan struct{}),
+		instanceMgr: im,
+		mapService:  ms,
+		instanceID:  "default",
+		stopChan:    make(chan struct{}),
 	}
 }
 
-// Start starts the chat monitoring loop
+// Start subscribes to the chat of the monitored instance
 func (m *ChatMonitor) Start() {
 	m.mu.Lock()
 	if m.running {
@@ -47,11 +46,12 @@ func (m *ChatMonitor) Start() {
 	}
 	m.running = true
 	m.stopChan = make(chan struct{})
+	m.unsubscribe = m.instanceMgr.SubscribeRcon(m.handleEvent)
 	m.mu.Unlock()
 
 	logs.GlobalLogs.Info("[RconMonitor] 채팅 모니터링 시작")
 
-	go m.pollLoop()
+	go m.sessionLoop()
 }
 
 // Stop stops the chat monitoring
@@ -64,6 +64,7 @@ func (m *ChatMonitor) Stop() {
 	}
 
 	m.running = false
+	m.unsubscribe()
 	close(m.stopChan)
 	logs.GlobalLogs.Info("[RconMonitor] 채팅 모니터링 중지")
 }
@@ -78,108 +79,69 @@ func (m *ChatMonitor) IsRunning() bool {
 // SetInstanceID sets the server instance to monitor
 func (m *ChatMonitor) SetInstanceID(id string) {
 	m.mu.Lock()
-	defer m.mu.Unlock()
 	m.instanceID = id
+	m.mu.Unlock()
+	m.ensureSession()
 }
 
-// pollLoop continuously polls for chat messages
-func (m *ChatMonitor) pollLoop() {
-	ticker := time.NewTicker(m.pollInterval)
+// sessionLoop keeps an RCON session open to the running instance so its chat is pushed to us
+func (m *ChatMonitor) sessionLoop() {
+	ticker := time.NewTicker(sessionCheckInterval)
 	defer ticker.Stop()
 
-	// First, enable console debug to get chat messages
-	m.enableConsoleDebug()
-
+	m.ensureSession()
 	for {
 		select {
 		case <-m.stopChan:
 			return
 		case <-ticker.C:
-			m.checkForCommands()
+			m.ensureSession()
 		}
 	}
 }
 
-// enableConsoleDebug enables console output forwarding via RCON
-func (m *ChatMonitor) enableConsoleDebug() {
+func (m *ChatMonitor) ensureSession() {
 	m.mu.RLock()
 	instanceID := m.instanceID
 	m.mu.RUnlock()
 
-	// Try to enable console debug output
-	// This forwards server console prints to the RCON client
-	_, err := m.instanceMgr.SendRconCommand(instanceID, "#debugon Console")
-	if err != nil {
-		logs.GlobalLogs.Warn(fmt.Sprintf("[RconMonitor] Console 디버그 활성화 실패: %v", err))
-	}
-}
-
-// checkForCommands checks for new chat commands
-func (m *ChatMonitor) checkForCommands() {
-	m.mu.RLock()
-	instanceID := m.instanceID
-	m.mu.RUnlock()
-
-	// Get server status/console output
-	// Note: This is a simplified approach. Real implementation might need
-	// to keep a persistent RCON connection and read continuously.
-	resp, err := m.instanceMgr.SendRconCommand(instanceID, "")
-	if err != nil {
-		// Server might be offline, ignore
+	if inst := m.instanceMgr.Get(instanceID); inst == nil || inst.Status != server.StatusRunning {
 		return
 	}
-
-	// Parse chat messages from response
-	messages := m.parseChatMessages(resp)
-
-	for _, msg := range messages {
-		m.processMessage(msg)
+	if err := m.instanceMgr.OpenRcon(instanceID); err != nil {
+		logs.GlobalLogs.Warn(fmt.Sprintf("[RconMonitor] RCON 세션 열기 실패: %v", err))
 	}
 }
 
-// ChatMessage represents a parsed chat message
+// ChatMessage is a chat command sent by a player
 type ChatMessage struct {
+	PlayerID   int // BE player number (-1 = unknown)
 	PlayerName string
+	Channel    string
 	Content    string
 	Timestamp  time.Time
 }
 
-// parseChatMessages parses chat messages from RCON output
-func (m *ChatMonitor) parseChatMessages(output string) []ChatMessage {
-	var messages []ChatMessage
-
-	// Chat message pattern varies by server configuration
-	// Common formats:
-	// [Global] PlayerName: message
-	// PlayerName: message
-	// etc.
-
-	lines := strings.Split(output, "\n")
-	chatPattern := regexp.MustCompile(`(?:\[.*?\]\s*)?(\w+):\s*(.+)`)
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
+// handleEvent picks the chat commands of the monitored instance out of its server messages
+func (m *ChatMonitor) handleEvent(ev server.RconEvent) {
+	m.mu.RLock()
+	instanceID := m.instanceID
+	m.mu.RUnlock()
 
-		matches := chatPattern.FindStringSubmatch(line)
-		if len(matches) >= 3 {
-			playerName := matches[1]
-			content := strings.TrimSpace(matches[2])
-
-			// Only process command messages
-			if strings.HasPrefix(content, "!") {
-				messages = append(messages, ChatMessage{
-					PlayerName: playerName,
-					Content:    content,
-					Timestamp:  time.Now(),
-				})
-			}
-		}
+	if ev.InstanceID != instanceID || ev.Type != battleye.EventChat || ev.Admin {
+		return
 	}
-
-	return messages
+	if !strings.HasPrefix(strings.TrimSpace(ev.Message), "!") {
+		return
+	}
+	// Commands answer over the same RCON session, which waits for this handler to return
+	go m.processMessage(ChatMessage{
+		PlayerID:   ev.PlayerID,
+		PlayerName: ev.PlayerName,
+		Channel:    ev.Channel,
+		Content:    ev.Message,
+		Timestamp:  ev.Time,
+	})
 }
 
 // processMessage processes a chat command message
@@ -230,10 +192,15 @@ func (m *ChatMonitor) handleMapCommand(instanceID, playerName string, args []str
 		return
 	}
 
+	by := server.Trigger{Source: server.SourceChat, Name: playerName}
+	if lock := m.instanceMgr.Maintenance(instanceID); lock != nil {
+		m.sendGameMessage(instanceID, "점검 중이라 맵을 변경할 수 없습니다: "+lock.Reason)
+		return
+	}
+
 	m.sendGameMessage(instanceID, fmt.Sprintf("맵 변경 중: %s... 서버가 재시작됩니다!", mapping.Name))
 
-	requester := fmt.Sprintf("게임내 (%s)", playerName)
-	if err := m.mapService.ChangeMapBySlot(instanceID, slot, requester); err != nil {
+	if err := m.mapService.ChangeMapBySlot(instanceID, slot, by); err != nil {
 		logs.GlobalLogs.Error(fmt.Sprintf("[RconMonitor] 맵 변경 실패: %v", err))
 	}
 }
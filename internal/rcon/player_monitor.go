@@ -54,7 +54,7 @@ func (pm *PlayerMonitor) checkPlayers() {
 	instances := pm.instanceMgr.List()
 
 	for _, inst := range instances {
-		if inst.Status != "running" {
+		if inst.Status != server.StatusRunning {
 			// If server stopped, clear cache
 			if _, ok := pm.lastPlayers[inst.ID]; ok {
 				delete(pm.lastPlayers, inst.ID)
@@ -73,7 +73,9 @@ func (pm *PlayerMonitor) checkPlayers() {
 			pm.lastPlayers[inst.ID] = make(map[string]string)
 			// Initial population (don't notify on first run to avoid spam)
 			for _, p := range currentPlayers {
-				pm.lastPlayers[inst.ID][p.BEGUID] = p.Name
+				if p.GUID != "" {
+					pm.lastPlayers[inst.ID][p.GUID] = p.Name
+				}
 			}
 			continue
 		}
@@ -83,8 +85,12 @@ func (pm *PlayerMonitor) checkPlayers() {
 
 		// Detect Joins
 		for _, p := range currentPlayers {
-			currentSnapshot[p.BEGUID] = p.Name
-			if _, exists := lastSnapshot[p.BEGUID]; !exists {
+			if p.GUID == "" {
+				// Still connecting, reported once BattlEye knows the GUID
+				continue
+			}
+			currentSnapshot[p.GUID] = p.Name
+			if _, exists := lastSnapshot[p.GUID]; !exists {
 				// Player Joined
 				pm.discord.SendMessage("➕ 플레이어 입장", fmt.Sprintf("**%s** 님이 서버(%s)에 접속했습니다.", p.Name, inst.Name), agent.ColorGreen)
 				logs.GlobalLogs.Info(fmt.Sprintf("[%s] Player Joined: %s", inst.ID, p.Name))
@@ -0,0 +1,41 @@
+package scheduler
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/astral/kg-server-web-gui/internal/logs"
+	"github.com/astral/kg-server-web-gui/internal/server"
+)
+
+// autoRestartSpec checks the daily restart times once a minute
+const autoRestartSpec = "0 * * * * *"
+
+// scheduleAutoRestarts runs the daily restarts set by AutoRestart/RestartTime in the advanced settings.
+// The settings are read every minute, so edits apply without rescheduling.
+func (m *Manager) scheduleAutoRestarts() {
+	if _, err := m.cron.AddFunc(autoRestartSpec, m.runAutoRestarts); err != nil {
+		logs.GlobalLogs.Error(fmt.Sprintf("[Scheduler] 자동 재시작 예약 실패: %v", err))
+	}
+}
+
+// runAutoRestarts restarts the running servers whose restart time is now
+func (m *Manager) runAutoRestarts() {
+	now := time.Now().Format("15:04")
+	for _, inst := range m.instanceMgr.List() {
+		adv, err := m.instanceMgr.AdvancedSettings(inst.ID)
+		if err != nil || adv == nil || !adv.AutoRestart || adv.RestartTime != now {
+			continue
+		}
+		if current := m.instanceMgr.Get(inst.ID); current == nil || current.Status != server.StatusRunning {
+			continue
+		}
+		go m.runJob(&Job{
+			ID:      "auto-restart-" + inst.ID,
+			Name:    fmt.Sprintf("%s 자동 재시작 (%s)", inst.ID, adv.RestartTime),
+			Type:    JobRestart,
+			Args:    []string{inst.ID},
+			Enabled: true,
+		})
+	}
+}
@@ -68,6 +68,7 @@ func NewManager(dataPath string, im *server.InstanceManager, ms *mapchange.MapCh
 // Start starts the cron scheduler
 func (m *Manager) Start() {
 	m.load()
+	m.scheduleAutoRestarts()
 	m.cron.Start()
 	logs.GlobalLogs.Info("[Scheduler] 스케줄러 시작됨")
 }
@@ -213,7 +214,7 @@ func (m *Manager) runJob(job *Job) {
 
 	switch job.Type {
 	case JobRestart:
-		err = m.runRestart(instanceID)
+		err = m.runRestart(instanceID, jobTrigger(job))
 	case JobChangeMap:
 		if len(job.Args) < 2 {
 			err = fmt.Errorf("map slot or scenarioId missing")
@@ -224,12 +225,12 @@ func (m *Manager) runJob(job *Job) {
 			// But wait, ChangeMapBySlot needs int.
 			// Let's assume we store slot as string in args for simplicity
 			// Or we could try to parse types.
-			err = m.runChangeMap(instanceID, target)
+			err = m.runChangeMap(instanceID, target, jobTrigger(job))
 		}
 	case JobStart:
-		err = m.instanceMgr.Start(instanceID, nil)
+		err = m.instanceMgr.StartBy(instanceID, nil, jobTrigger(job))
 	case JobStop:
-		err = m.instanceMgr.Stop(instanceID)
+		err = m.instanceMgr.GracefulStop(instanceID, server.ShutdownOptions{Reason: "예약된 서버 중지", Countdown: true, Trigger: jobTrigger(job)})
 	default:
 		err = fmt.Errorf("unknown job type: %s", job.Type)
 	}
@@ -258,29 +259,17 @@ func (m *Manager) runJob(job *Job) {
 	}
 }
 
-func (m *Manager) runRestart(instanceID string) error {
-	// First, try to send warning via RCON
-	msg := "⚠️ 서버 정기 재시작이 1분 후에 진행됩니다!"
-	_, _ = m.instanceMgr.SendRconCommand(instanceID, fmt.Sprintf("#say %s", msg))
-
-	// Wait 10 seconds (in real world maybe longer, but block thread? better perform separate goroutine?)
-	// For simplicity, we just notify and restart immediately or maybe wait small amount?
-	// Blocking cron worker is fine as long as execution time is reasonable.
-	// But 1 minute block is too long.
-	// Let's just say "Restarting now"
-
-	if err := m.instanceMgr.Stop(instanceID); err != nil {
-		logs.GlobalLogs.Warn(fmt.Sprintf("[Scheduler] 중지 실패 (계속 진행): %v", err))
-	}
-
-	time.Sleep(3 * time.Second)
+// jobTrigger attributes the actions of a job to the scheduler
+func jobTrigger(job *Job) server.Trigger {
+	return server.Trigger{Source: server.SourceScheduler, Name: job.Name}
+}
 
-	// Fetch default args logic?
-	// The instance manager start should handle args if empty.
-	return m.instanceMgr.Start(instanceID, []string{"-server"})
+func (m *Manager) runRestart(instanceID string, by server.Trigger) error {
+	// Players are warned over RCON following the instance's shutdown policy (10/5/1 minutes by default)
+	return m.instanceMgr.Restart(instanceID, server.ShutdownOptions{Reason: "정기 재시작", Countdown: true, Trigger: by})
 }
 
-func (m *Manager) runChangeMap(instanceID, target string) error {
+func (m *Manager) runChangeMap(instanceID, target string, by server.Trigger) error {
 	// target can be slot number (e.g. "1") or scenarioId
 	// We'll try to parse as slot first
 	var slot int
@@ -288,11 +277,11 @@ func (m *Manager) runChangeMap(instanceID, target string) error {
 
 	if err == nil && slot > 0 {
 		// It's a slot
-		return m.mapService.ChangeMapBySlot(instanceID, slot, "Scheduler")
+		return m.mapService.ChangeMapBySlot(instanceID, slot, by)
 	}
 
 	// It's a scenario ID
-	return m.mapService.ChangeMap(instanceID, target, "Scheduled Map", "Scheduler")
+	return m.mapService.ChangeMap(instanceID, target, "Scheduled Map", by)
 }
 
 // Persistence
@@ -0,0 +1,516 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/astral/kg-server-web-gui/internal/battleye"
+	"github.com/astral/kg-server-web-gui/internal/logs"
+	"github.com/google/uuid"
+)
+
+// Ban record states
+const (
+	BanActive  = "active"
+	BanExpired = "expired"
+	BanLifted  = "lifted" // Removed before it expired
+)
+
+// banExpiryInterval is how often expired bans are lifted on the servers
+const banExpiryInterval = time.Minute
+
+// BanRecord is a ban issued through the panel, kept in data/bans.json
+type BanRecord struct {
+	ID         string     `json:"id"`
+	InstanceID string     `json:"instanceId"` // Server the ban was issued on (empty = the global registry)
+	Global     bool       `json:"global"`     // On the shared list of all servers that take part
+	Kind       string     `json:"kind"`       // battleye.BanGUID or battleye.BanIP
+	Target     string     `json:"target"`
+	PlayerName string     `json:"playerName,omitempty"`
+	Reason     string     `json:"reason,omitempty"`
+	Admin      string     `json:"admin"` // Panel user or source that issued the ban
+	CreatedAt  time.Time  `json:"createdAt"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"` // nil = permanent
+	LiftedAt   *time.Time `json:"liftedAt,omitempty"`
+	LiftedBy   string     `json:"liftedBy,omitempty"`
+	Unapplied  bool       `json:"unapplied,omitempty"` // Lifted while the server was down, removed from it on the next start
+	Status     string     `json:"status"`              // Filled in when read
+}
+
+// state returns BanActive, BanExpired or BanLifted
+func (r *BanRecord) state(now time.Time) string {
+	switch {
+	case r.LiftedAt != nil && r.ExpiresAt != nil && !r.LiftedAt.Before(*r.ExpiresAt):
+		return BanExpired
+	case r.LiftedAt != nil:
+		return BanLifted
+	case r.ExpiresAt != nil && !now.Before(*r.ExpiresAt):
+		return BanExpired
+	}
+	return BanActive
+}
+
+func (r *BanRecord) view(now time.Time) BanRecord {
+	v := *r
+	v.Status = r.state(now)
+	return v
+}
+
+// BanRequest adds a ban
+type BanRequest struct {
+	Target  string `json:"target"`  // Connected player number, BE GUID or IP address
+	Minutes int    `json:"minutes"` // 0 = permanent
+	Reason  string `json:"reason"`
+}
+
+// ServerBan is a ban in the server's BattlEye list with the panel record behind it, if any
+type ServerBan struct {
+	battleye.Ban
+	Record *BanRecord `json:"record,omitempty"`
+}
+
+// BanQuery filters SearchBans; empty fields match everything
+type BanQuery struct {
+	InstanceID string
+	Text       string // Substring of target, player name, reason or admin
+	Status     string // BanActive, BanExpired or BanLifted
+	Kind       string // battleye.BanGUID or battleye.BanIP
+	Global     bool   // Only bans on the global list
+	Limit      int
+}
+
+// BanSearchResult holds matching records, newest first, and the counts per state over all matches
+type BanSearchResult struct {
+	Bans    []BanRecord    `json:"bans"`
+	Total   int            `json:"total"`
+	ByState map[string]int `json:"byState"`
+}
+
+func (im *InstanceManager) bansPath() string {
+	return filepath.Join(im.dataPath, "bans.json")
+}
+
+// loadBanRecords reads data/bans.json
+func (im *InstanceManager) loadBanRecords() {
+	data, err := os.ReadFile(im.bansPath())
+	if err != nil {
+		return
+	}
+	var records []*BanRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		logs.GlobalLogs.Warn(fmt.Sprintf("차단 기록 로드 실패: %v", err))
+		return
+	}
+	im.banMu.Lock()
+	im.banRecords = records
+	im.banMu.Unlock()
+}
+
+// saveBanRecordsLocked writes data/bans.json; the caller holds im.banMu
+func (im *InstanceManager) saveBanRecordsLocked() error {
+	data, err := json.MarshalIndent(im.banRecords, "", "  ")
+	if err != nil {
+		return err
+	}
+	os.MkdirAll(im.dataPath, 0755)
+	return os.WriteFile(im.bansPath(), data, 0644)
+}
+
+// sharesBans reports whether an instance takes part in the global ban list
+func (im *InstanceManager) sharesBans(id string) bool {
+	im.mu.RLock()
+	defer im.mu.RUnlock()
+	inst, ok := im.instances[id]
+	return ok && (inst.SharedBans == nil || *inst.SharedBans)
+}
+
+// ServerBans returns the BattlEye ban list of a running server, matched with the panel's records
+func (im *InstanceManager) ServerBans(id string) ([]ServerBan, error) {
+	if im.Get(id) == nil {
+		return nil, fmt.Errorf("서버를 찾을 수 없습니다: %s", id)
+	}
+	bans, err := im.Commands(id).Bans()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	shared := im.sharesBans(id)
+	im.banMu.Lock()
+	defer im.banMu.Unlock()
+	list := make([]ServerBan, len(bans))
+	for i, b := range bans {
+		list[i].Ban = b
+		if r := im.activeBanLocked(id, shared, b.Target); r != nil {
+			v := r.view(now)
+			list[i].Record = &v
+		}
+	}
+	return list, nil
+}
+
+// appliesTo reports whether a record bans on an instance: its own bans, and the global ones if it shares them
+func (r *BanRecord) appliesTo(id string, shared bool) bool {
+	if r.Global {
+		return shared
+	}
+	return r.InstanceID == id
+}
+
+// activeBanLocked returns the unlifted record banning target on an instance; the caller holds im.banMu
+func (im *InstanceManager) activeBanLocked(id string, shared bool, target string) *BanRecord {
+	for i := len(im.banRecords) - 1; i >= 0; i-- {
+		r := im.banRecords[i]
+		if r.appliesTo(id, shared) && r.LiftedAt == nil && strings.EqualFold(r.Target, target) {
+			return r
+		}
+	}
+	return nil
+}
+
+// AddBan bans a player by number, or a BE GUID or IP address, and records who issued it.
+// A connected player is kicked right away; bans by GUID or IP also hold for players who are offline.
+// On an instance that shares bans the ban goes on the global list and out to the other servers.
+func (im *InstanceManager) AddBan(id string, req BanRequest, by Trigger) (*BanRecord, error) {
+	if im.Get(id) == nil {
+		return nil, fmt.Errorf("서버를 찾을 수 없습니다: %s", id)
+	}
+	if req.Minutes < 0 {
+		return nil, fmt.Errorf("차단 시간은 0(영구) 이상이어야 합니다: %d", req.Minutes)
+	}
+	req.Target = strings.TrimSpace(req.Target)
+
+	commands := im.Commands(id)
+	players, playersErr := commands.Players()
+	record := &BanRecord{
+		ID:         uuid.New().String(),
+		InstanceID: id,
+		Global:     im.sharesBans(id),
+		Reason:     strings.TrimSpace(req.Reason),
+		Admin:      by.Name,
+		CreatedAt:  time.Now(),
+	}
+	if record.Admin == "" {
+		record.Admin = by.Source
+	}
+	if req.Minutes > 0 {
+		end := record.CreatedAt.Add(time.Duration(req.Minutes) * time.Minute)
+		record.ExpiresAt = &end
+	}
+
+	// A connected player is banned by number so BattlEye kicks them too
+	online := -1
+	if index, err := strconv.Atoi(req.Target); err == nil {
+		if playersErr != nil {
+			return nil, playersErr
+		}
+		for _, p := range players {
+			if p.Index == index {
+				online = index
+				record.PlayerName = p.Name
+				record.Kind, record.Target = battleye.BanGUID, p.GUID
+				if p.GUID == "" {
+					record.Kind, record.Target = battleye.BanIP, p.IP
+				}
+			}
+		}
+		if online < 0 {
+			return nil, fmt.Errorf("접속 중인 플레이어가 아닙니다: #%d", index)
+		}
+	} else {
+		kind, err := battleye.BanKind(req.Target)
+		if err != nil {
+			return nil, err
+		}
+		record.Kind, record.Target = kind, req.Target
+		if kind == battleye.BanGUID {
+			record.Target = strings.ToLower(req.Target)
+		}
+		for _, p := range players {
+			if (kind == battleye.BanGUID && p.GUID == record.Target) || (kind == battleye.BanIP && p.IP == record.Target) {
+				online, record.PlayerName = p.Index, p.Name
+			}
+		}
+	}
+
+	var err error
+	if online >= 0 {
+		err = commands.BanPlayer(online, req.Minutes, record.Reason)
+	} else {
+		err = commands.Ban(record.Target, req.Minutes, record.Reason)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	im.banMu.Lock()
+	im.banRecords = append(im.banRecords, record)
+	if err := im.saveBanRecordsLocked(); err != nil {
+		logs.GlobalLogs.Warn(fmt.Sprintf("차단 기록 저장 실패: %v", err))
+	}
+	v := record.view(time.Now())
+	im.banMu.Unlock()
+
+	logs.GlobalLogs.Info(fmt.Sprintf("[%s] 차단: %s %s (%s, 설정: %s)", id, record.Kind, record.Target, banLength(req.Minutes), record.Admin))
+	if record.Global {
+		im.requestBanSync(id)
+	}
+	return &v, nil
+}
+
+// RemoveBan lifts the ban on a BE GUID or IP address. On a server that is not running only the
+// records are lifted; the ban is removed from the server when it starts.
+func (im *InstanceManager) RemoveBan(id, target string, by Trigger) error {
+	inst := im.Get(id)
+	if inst == nil {
+		return fmt.Errorf("서버를 찾을 수 없습니다: %s", id)
+	}
+	target = strings.TrimSpace(target)
+	if im.currentStatus(inst) != StatusRunning {
+		return im.liftWhileStopped(id, target, by)
+	}
+	commands := im.Commands(id)
+	bans, err := commands.Bans()
+	if err != nil {
+		return err
+	}
+	// Ban numbers shift after every removal, the list is read right before removing
+	found := false
+	for _, b := range bans {
+		if strings.EqualFold(b.Target, target) {
+			if err := commands.RemoveBan(b.Index); err != nil {
+				return err
+			}
+			found = true
+			break
+		}
+	}
+
+	lifted, global := im.liftBanRecords(id, im.sharesBans(id), []string{target}, by.Name)
+	if !found && len(lifted) == 0 {
+		return fmt.Errorf("차단 목록에 없습니다: %s", target)
+	}
+	logs.GlobalLogs.Info(fmt.Sprintf("[%s] 차단 해제: %s (%s)", id, target, by.Name))
+	if global {
+		im.requestBanSync(id)
+	}
+	return nil
+}
+
+// liftWhileStopped lifts the records of a target on a server that is not running. Its own records are
+// marked unapplied for the next start; global ones are caught up by the shared list sync.
+func (im *InstanceManager) liftWhileStopped(id, target string, by Trigger) error {
+	lifted, global := im.liftBanRecords(id, im.sharesBans(id), []string{target}, by.Name)
+	if len(lifted) == 0 {
+		return fmt.Errorf("차단 목록에 없습니다: %s", target)
+	}
+
+	im.banMu.Lock()
+	for _, r := range lifted {
+		r.Unapplied = !r.Global
+	}
+	if err := im.saveBanRecordsLocked(); err != nil {
+		logs.GlobalLogs.Warn(fmt.Sprintf("차단 기록 저장 실패: %v", err))
+	}
+	im.banMu.Unlock()
+
+	logs.GlobalLogs.Info(fmt.Sprintf("[%s] 차단 해제: %s (%s), 서버가 꺼져 있어 다음 시작 때 서버에서도 해제합니다", id, target, by.Name))
+	if global {
+		im.requestBanSync(id)
+	}
+	return nil
+}
+
+// applyUnappliedLifts removes the bans lifted while a server was down from the now running server
+func (im *InstanceManager) applyUnappliedLifts(id string) error {
+	im.banMu.Lock()
+	var pending []*BanRecord
+	var targets []string
+	for _, r := range im.banRecords {
+		if r.Unapplied && r.InstanceID == id {
+			pending = append(pending, r)
+			targets = append(targets, r.Target)
+		}
+	}
+	im.banMu.Unlock()
+	if len(pending) == 0 {
+		return nil
+	}
+
+	if err := im.removeServerBans(id, targets); err != nil {
+		return err
+	}
+	im.banMu.Lock()
+	for _, r := range pending {
+		r.Unapplied = false
+	}
+	if err := im.saveBanRecordsLocked(); err != nil {
+		logs.GlobalLogs.Warn(fmt.Sprintf("차단 기록 저장 실패: %v", err))
+	}
+	im.banMu.Unlock()
+	logs.GlobalLogs.Info(fmt.Sprintf("[%s] 꺼져 있는 동안 해제한 차단 %d건을 서버에 반영했습니다", id, len(pending)))
+	return nil
+}
+
+// liftBanRecords marks the active records of the targets on an instance as lifted. It returns them
+// and whether one of them was on the global list.
+func (im *InstanceManager) liftBanRecords(id string, shared bool, targets []string, liftedBy string) (lifted []*BanRecord, global bool) {
+	now := time.Now()
+	im.banMu.Lock()
+	defer im.banMu.Unlock()
+	for _, r := range im.banRecords {
+		if !r.appliesTo(id, shared) || r.LiftedAt != nil {
+			continue
+		}
+		for _, t := range targets {
+			if strings.EqualFold(r.Target, t) {
+				at := now
+				r.LiftedAt, r.LiftedBy = &at, liftedBy
+				lifted = append(lifted, r)
+				global = global || r.Global
+				break
+			}
+		}
+	}
+	if len(lifted) > 0 {
+		if err := im.saveBanRecordsLocked(); err != nil {
+			logs.GlobalLogs.Warn(fmt.Sprintf("차단 기록 저장 실패: %v", err))
+		}
+	}
+	return lifted, global
+}
+
+// ReloadBans makes the server read its bans.txt again
+func (im *InstanceManager) ReloadBans(id string) error {
+	if im.Get(id) == nil {
+		return fmt.Errorf("서버를 찾을 수 없습니다: %s", id)
+	}
+	return im.Commands(id).LoadBans()
+}
+
+// WriteBans makes the server save its ban list to bans.txt
+func (im *InstanceManager) WriteBans(id string) error {
+	if im.Get(id) == nil {
+		return fmt.Errorf("서버를 찾을 수 없습니다: %s", id)
+	}
+	return im.Commands(id).WriteBans()
+}
+
+// SearchBans searches the panel's ban records
+func (im *InstanceManager) SearchBans(q BanQuery) BanSearchResult {
+	now := time.Now()
+	text := strings.ToLower(strings.TrimSpace(q.Text))
+	res := BanSearchResult{Bans: []BanRecord{}, ByState: map[string]int{BanActive: 0, BanExpired: 0, BanLifted: 0}}
+
+	im.banMu.Lock()
+	defer im.banMu.Unlock()
+	for i := len(im.banRecords) - 1; i >= 0; i-- {
+		r := im.banRecords[i].view(now)
+		if (q.InstanceID != "" && r.InstanceID != q.InstanceID) || (q.Kind != "" && r.Kind != q.Kind) || (q.Global && !r.Global) {
+			continue
+		}
+		if text != "" && !strings.Contains(strings.ToLower(strings.Join([]string{r.Target, r.PlayerName, r.Reason, r.Admin}, "\n")), text) {
+			continue
+		}
+		res.ByState[r.Status]++
+		if q.Status != "" && r.Status != q.Status {
+			continue
+		}
+		res.Total++
+		if q.Limit <= 0 || len(res.Bans) < q.Limit {
+			res.Bans = append(res.Bans, r)
+		}
+	}
+	return res
+}
+
+// runBanJobs lifts expired timed bans and retries global list updates that did not reach a server
+func (im *InstanceManager) runBanJobs() {
+	ticker := time.NewTicker(banExpiryInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		im.expireBans()
+		im.retryBanSync()
+	}
+}
+
+// expireBans removes expired bans from running servers and closes their records. BattlEye skips
+// expired entries of bans.txt itself, so records of stopped servers are closed right away.
+// Expired global bans are closed and then taken off every server by the global list sync.
+func (im *InstanceManager) expireBans() {
+	now := time.Now()
+	expired := make(map[string][]string)
+	global := false
+	im.banMu.Lock()
+	for _, r := range im.banRecords {
+		if r.LiftedAt == nil && r.state(now) == BanExpired {
+			if r.Global {
+				lifted := now
+				r.LiftedAt, r.LiftedBy = &lifted, SourceSystem
+				global = true
+				continue
+			}
+			expired[r.InstanceID] = append(expired[r.InstanceID], r.Target)
+		}
+	}
+	if global {
+		if err := im.saveBanRecordsLocked(); err != nil {
+			logs.GlobalLogs.Warn(fmt.Sprintf("차단 기록 저장 실패: %v", err))
+		}
+	}
+	im.banMu.Unlock()
+	if global {
+		im.requestBanSync("")
+	}
+
+	for id, targets := range expired {
+		inst := im.Get(id)
+		if inst != nil && inst.Status == StatusRunning {
+			if err := im.removeServerBans(id, targets); err != nil {
+				logs.GlobalLogs.Warn(fmt.Sprintf("[%s] 만료된 차단 해제 실패, 다음에 다시 시도합니다: %v", id, err))
+				continue
+			}
+		}
+		im.liftBanRecords(id, false, targets, SourceSystem)
+		logs.GlobalLogs.Info(fmt.Sprintf("[%s] 만료된 차단 %d건 해제", id, len(targets)))
+	}
+}
+
+// removeServerBans removes the bans on targets from a running server's list
+func (im *InstanceManager) removeServerBans(id string, targets []string) error {
+	commands := im.Commands(id)
+	bans, err := commands.Bans()
+	if err != nil {
+		return err
+	}
+	var indexes []int
+	for _, b := range bans {
+		for _, t := range targets {
+			if strings.EqualFold(b.Target, t) {
+				indexes = append(indexes, b.Index)
+				break
+			}
+		}
+	}
+	// Highest first, so the numbers still to remove do not shift
+	sort.Sort(sort.Reverse(sort.IntSlice(indexes)))
+	for _, i := range indexes {
+		if err := commands.RemoveBan(i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func banLength(minutes int) string {
+	if minutes == 0 {
+		return "영구"
+	}
+	return fmt.Sprintf("%d분", minutes)
+}
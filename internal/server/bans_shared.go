@@ -0,0 +1,341 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/astral/kg-server-web-gui/internal/battleye"
+	"github.com/astral/kg-server-web-gui/internal/logs"
+	"github.com/google/uuid"
+)
+
+// BanImportResult counts what ImportBans did with the lines of a bans.txt
+type BanImportResult struct {
+	Added      int   `json:"added"`
+	Duplicates int   `json:"duplicates"` // Already on the global list
+	Expired    int   `json:"expired"`
+	Skipped    []int `json:"skipped,omitempty"` // Line numbers that are not a ban
+}
+
+// AddGlobalBan puts a BE GUID or IP address on the global list and bans it on every server that shares bans
+func (im *InstanceManager) AddGlobalBan(req BanRequest, by Trigger) (*BanRecord, error) {
+	req.Target = strings.TrimSpace(req.Target)
+	kind, err := battleye.BanKind(req.Target)
+	if err != nil {
+		return nil, err
+	}
+	if req.Minutes < 0 {
+		return nil, fmt.Errorf("차단 시간은 0(영구) 이상이어야 합니다: %d", req.Minutes)
+	}
+	if kind == battleye.BanGUID {
+		req.Target = strings.ToLower(req.Target)
+	}
+
+	now := time.Now()
+	record := &BanRecord{
+		ID:        uuid.New().String(),
+		Global:    true,
+		Kind:      kind,
+		Target:    req.Target,
+		Reason:    strings.TrimSpace(req.Reason),
+		Admin:     by.Name,
+		CreatedAt: now,
+	}
+	if record.Admin == "" {
+		record.Admin = by.Source
+	}
+	if req.Minutes > 0 {
+		end := now.Add(time.Duration(req.Minutes) * time.Minute)
+		record.ExpiresAt = &end
+	}
+
+	im.banMu.Lock()
+	if im.globalBanLocked(record.Target, now) != nil {
+		im.banMu.Unlock()
+		return nil, fmt.Errorf("이미 전체 차단 목록에 있습니다: %s", record.Target)
+	}
+	im.banRecords = append(im.banRecords, record)
+	if err := im.saveBanRecordsLocked(); err != nil {
+		logs.GlobalLogs.Warn(fmt.Sprintf("차단 기록 저장 실패: %v", err))
+	}
+	v := record.view(now)
+	im.banMu.Unlock()
+
+	logs.GlobalLogs.Info(fmt.Sprintf("전체 차단: %s %s (%s, 설정: %s)", record.Kind, record.Target, banLength(req.Minutes), record.Admin))
+	im.requestBanSync("")
+	return &v, nil
+}
+
+// RemoveGlobalBan takes a target off the global list and lifts it on every server that shares bans
+func (im *InstanceManager) RemoveGlobalBan(target string, by Trigger) error {
+	target = strings.TrimSpace(target)
+	if lifted, _ := im.liftBanRecords("", true, []string{target}, by.Name); len(lifted) == 0 {
+		return fmt.Errorf("전체 차단 목록에 없습니다: %s", target)
+	}
+	logs.GlobalLogs.Info(fmt.Sprintf("전체 차단 해제: %s (%s)", target, by.Name))
+	im.requestBanSync("")
+	return nil
+}
+
+// globalBanLocked returns the active global record of target; the caller holds im.banMu
+func (im *InstanceManager) globalBanLocked(target string, now time.Time) *BanRecord {
+	for _, r := range im.banRecords {
+		if r.Global && r.state(now) == BanActive && strings.EqualFold(r.Target, target) {
+			return r
+		}
+	}
+	return nil
+}
+
+// ExportBans writes the active global bans in the bans.txt format
+func (im *InstanceManager) ExportBans() string {
+	now := time.Now()
+	var bans []battleye.FileBan
+	im.banMu.Lock()
+	for _, r := range im.banRecords {
+		if !r.Global || r.state(now) != BanActive {
+			continue
+		}
+		b := battleye.FileBan{Target: r.Target, Kind: r.Kind, Reason: r.Reason}
+		if r.ExpiresAt != nil {
+			b.Expires = *r.ExpiresAt
+		}
+		bans = append(bans, b)
+	}
+	im.banMu.Unlock()
+	return battleye.FormatBansFile(bans)
+}
+
+// ImportBans adds the bans of a bans.txt to the global list
+func (im *InstanceManager) ImportBans(data string, by Trigger) BanImportResult {
+	bans, skipped := battleye.ParseBansFile(data)
+	res := BanImportResult{Skipped: skipped}
+	admin := by.Name
+	if admin == "" {
+		admin = by.Source
+	}
+
+	now := time.Now()
+	im.banMu.Lock()
+	for _, b := range bans {
+		switch {
+		case !b.Permanent() && !b.Expires.After(now):
+			res.Expired++
+			continue
+		case im.globalBanLocked(b.Target, now) != nil:
+			res.Duplicates++
+			continue
+		}
+		record := &BanRecord{
+			ID:        uuid.New().String(),
+			Global:    true,
+			Kind:      b.Kind,
+			Target:    b.Target,
+			Reason:    b.Reason,
+			Admin:     admin,
+			CreatedAt: now,
+		}
+		if !b.Permanent() {
+			end := b.Expires
+			record.ExpiresAt = &end
+		}
+		im.banRecords = append(im.banRecords, record)
+		res.Added++
+	}
+	if res.Added > 0 {
+		if err := im.saveBanRecordsLocked(); err != nil {
+			logs.GlobalLogs.Warn(fmt.Sprintf("차단 기록 저장 실패: %v", err))
+		}
+	}
+	im.banMu.Unlock()
+
+	logs.GlobalLogs.Info(fmt.Sprintf("bans.txt 가져오기: %d건 추가, 중복 %d, 만료 %d, 잘못된 줄 %d (%s)", res.Added, res.Duplicates, res.Expired, len(skipped), admin))
+	if res.Added > 0 {
+		im.requestBanSync("")
+	}
+	return res
+}
+
+// SetSharedBans lets an instance take part in the global ban list or leave it. Bans already
+// on a server that leaves stay there.
+func (im *InstanceManager) SetSharedBans(id string, shared bool) error {
+	im.mu.Lock()
+	inst, ok := im.instances[id]
+	if !ok {
+		im.mu.Unlock()
+		return fmt.Errorf("서버를 찾을 수 없습니다: %s", id)
+	}
+	inst.SharedBans = &shared
+	err := im.saveLocked()
+	im.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if shared {
+		go im.catchUpBans(id)
+	}
+	return nil
+}
+
+// requestBanSync brings the running servers that share bans, except one, in line with the global list
+func (im *InstanceManager) requestBanSync(except string) {
+	for _, inst := range im.List() {
+		if inst.ID == except || inst.Status != StatusRunning || !im.sharesBans(inst.ID) {
+			continue
+		}
+		im.banMu.Lock()
+		im.banPending[inst.ID] = true
+		im.banMu.Unlock()
+		go im.syncSharedBans(inst.ID)
+	}
+}
+
+// catchUpBans applies the global bans issued while an instance was stopped or out of the list
+func (im *InstanceManager) catchUpBans(id string) {
+	im.banMu.Lock()
+	im.banPending[id] = true
+	im.banMu.Unlock()
+	im.syncSharedBans(id)
+}
+
+// retryBanSync runs the syncs that failed, e.g. because RCON was not up yet
+func (im *InstanceManager) retryBanSync() {
+	im.banMu.Lock()
+	var ids []string
+	for id := range im.banPending {
+		ids = append(ids, id)
+	}
+	im.banMu.Unlock()
+	for _, id := range ids {
+		go im.syncSharedBans(id)
+	}
+}
+
+// syncSharedBans applies the global list to an instance while it is marked pending, one sync at a time
+func (im *InstanceManager) syncSharedBans(id string) {
+	im.banMu.Lock()
+	if im.banSyncing[id] {
+		im.banMu.Unlock()
+		return // The running sync sees the pending mark and goes again
+	}
+	im.banSyncing[id] = true
+	im.banMu.Unlock()
+	defer func() {
+		im.banMu.Lock()
+		delete(im.banSyncing, id)
+		im.banMu.Unlock()
+	}()
+
+	for {
+		im.banMu.Lock()
+		if !im.banPending[id] {
+			im.banMu.Unlock()
+			return
+		}
+		delete(im.banPending, id)
+		im.banMu.Unlock()
+
+		if err := im.applySharedBans(id); err != nil {
+			logs.GlobalLogs.Warn(fmt.Sprintf("[%s] 전체 차단 목록 적용 실패, 다음에 다시 시도합니다: %v", id, err))
+			if im.Get(id) != nil {
+				im.banMu.Lock()
+				im.banPending[id] = true
+				im.banMu.Unlock()
+			}
+			return
+		}
+	}
+}
+
+// applySharedBans bans the active global targets missing on a running server and removes the lifted ones,
+// along with its own bans lifted while it was down
+func (im *InstanceManager) applySharedBans(id string) error {
+	inst := im.Get(id)
+	if inst == nil || inst.Status != StatusRunning {
+		return nil
+	}
+	if err := im.applyUnappliedLifts(id); err != nil {
+		return err
+	}
+	if !im.sharesBans(id) {
+		return nil
+	}
+	commands := im.Commands(id)
+	bans, err := commands.Bans()
+	if err != nil {
+		return err
+	}
+	players, _ := commands.Players()
+	onServer := make(map[string]bool, len(bans))
+	for _, b := range bans {
+		onServer[strings.ToLower(b.Target)] = true
+	}
+
+	now := time.Now()
+	var add []BanRecord
+	var remove []string
+	im.banMu.Lock()
+	active := make(map[string]bool)
+	for _, r := range im.banRecords {
+		if r.Global && r.state(now) == BanActive {
+			target := strings.ToLower(r.Target)
+			if !active[target] && !onServer[target] {
+				add = append(add, *r)
+			}
+			active[target] = true
+		}
+	}
+	for _, r := range im.banRecords {
+		target := strings.ToLower(r.Target)
+		if r.Global && r.LiftedAt != nil && onServer[target] && !active[target] && im.activeBanLocked(id, true, r.Target) == nil {
+			remove = append(remove, target)
+			onServer[target] = false
+		}
+	}
+	im.banMu.Unlock()
+
+	for _, r := range add {
+		minutes := remainingMinutes(r.ExpiresAt, now)
+		if minutes < 0 {
+			continue
+		}
+		// A player on the server is banned by number so BattlEye kicks them too
+		online := -1
+		for _, p := range players {
+			if (r.Kind == battleye.BanGUID && p.GUID == r.Target) || (r.Kind == battleye.BanIP && p.IP == r.Target) {
+				online = p.Index
+			}
+		}
+		if online >= 0 {
+			err = commands.BanPlayer(online, minutes, r.Reason)
+		} else {
+			err = commands.Ban(r.Target, minutes, r.Reason)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if len(remove) > 0 {
+		if err := im.removeServerBans(id, remove); err != nil {
+			return err
+		}
+	}
+	if len(add) > 0 || len(remove) > 0 {
+		logs.GlobalLogs.Info(fmt.Sprintf("[%s] 전체 차단 목록 적용: %d건 추가, %d건 해제", id, len(add), len(remove)))
+	}
+	return nil
+}
+
+// remainingMinutes returns the minutes a ban still has for addBan: 0 = permanent, -1 = already over
+func remainingMinutes(expires *time.Time, now time.Time) int {
+	if expires == nil {
+		return 0
+	}
+	left := expires.Sub(now)
+	if left <= 0 {
+		return -1
+	}
+	return int(math.Ceil(left.Minutes()))
+}
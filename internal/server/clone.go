@@ -0,0 +1,235 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/astral/kg-server-web-gui/internal/config"
+	"github.com/astral/kg-server-web-gui/internal/logs"
+)
+
+// CloneOptions configures a clone of a server instance
+type CloneOptions struct {
+	ID           string  `json:"id"`           // New instance ID (default: <source>-2, -3, ...)
+	Name         string  `json:"name"`         // New display name (default: "<source> (복제)")
+	IncludeSaves bool    `json:"includeSaves"` // Copy the source profile's saves as well
+	Trigger      Trigger `json:"-"`
+}
+
+// CloneResult describes a newly created clone
+type CloneResult struct {
+	Instance   *ServerInstance `json:"instance"`
+	ConfigPath string          `json:"configPath"`
+	Ports      PortBlock       `json:"ports"`
+	SavesFiles int             `json:"savesFiles"` // Files copied from the source's saves
+}
+
+// Clone creates a new instance (and workspace) from the config, settings and policies of an existing one.
+// Its ports are moved to the next free set and it gets its own RCON password.
+// Map slot mappings are panel-wide, so the clone shares them with the source.
+func (im *InstanceManager) Clone(srcID string, opts CloneOptions) (*CloneResult, error) {
+	src := im.Get(srcID)
+	if src == nil {
+		return nil, fmt.Errorf("서버를 찾을 수 없습니다: %s", srcID)
+	}
+
+	id := opts.ID
+	if id == "" {
+		id = im.nextCloneID(srcID)
+	}
+	if id != filepath.Base(id) || id == "." || id == ".." {
+		return nil, fmt.Errorf("잘못된 서버 ID입니다: %s", id)
+	}
+	if im.Get(id) != nil {
+		return nil, fmt.Errorf("서버 ID가 이미 존재합니다: %s", id)
+	}
+
+	data, err := os.ReadFile(im.ConfigPath(srcID))
+	if err != nil {
+		return nil, fmt.Errorf("원본 설정 파일 로드 실패: %w", err)
+	}
+	// Generic map so fields the schema does not know survive the copy
+	var cfg map[string]any
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("원본 설정 파일 파싱 실패: %w", err)
+	}
+
+	im.mu.RLock()
+	srcAdv := src.Advanced
+	im.mu.RUnlock()
+	ports, err := im.nextFreePorts(cfg, srcAdv)
+	if err != nil {
+		return nil, err
+	}
+	password, err := randomPassword()
+	if err != nil {
+		return nil, err
+	}
+	applyClonePorts(cfg, ports, password)
+
+	// Settings and policies are copied through JSON so the clone shares no pointers with the source
+	im.mu.RLock()
+	raw, err := json.Marshal(src)
+	im.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+	inst := &ServerInstance{}
+	if err := json.Unmarshal(raw, inst); err != nil {
+		return nil, err
+	}
+	inst.ID = id
+	inst.Name = opts.Name
+	if inst.Name == "" {
+		inst.Name = src.Name + " (복제)"
+	}
+	inst.PID = 0
+	inst.LastStarted = nil
+	inst.Maintenance = nil
+
+	inst.ConfigPath = "" // The clone's config lives in its own workspace
+	if overridesPort(inst.Advanced) {
+		inst.Advanced.Port = ports.BindPort // -bindPort would otherwise keep the source's port
+	}
+
+	if err := im.Create(inst); err != nil {
+		return nil, err
+	}
+	ws := im.Workspace(id)
+	out, _ := json.MarshalIndent(cfg, "", "  ")
+	if err := os.WriteFile(ws.ConfigPath, out, 0644); err != nil {
+		im.Delete(id)
+		return nil, err
+	}
+
+	result := &CloneResult{Instance: inst, ConfigPath: ws.ConfigPath, Ports: ports}
+	if opts.IncludeSaves {
+		n, err := copyTree(im.Workspace(srcID).SavesDir, ws.SavesDir)
+		if err != nil {
+			logs.GlobalLogs.Warn(fmt.Sprintf("[%s] 저장 파일 복사 실패: %v", id, err))
+		}
+		result.SavesFiles = n
+	}
+
+	im.RecordEvent(id, EventCloned, fmt.Sprintf("%s에서 복제 (포트 %d/%d/%d)", src.Name, ports.BindPort, ports.A2SPort, ports.RconPort), opts.Trigger)
+	logs.GlobalLogs.Info(fmt.Sprintf("[%s] %s 서버를 복제했습니다 (포트 %d, A2S %d, RCON %d)", id, srcID, ports.BindPort, ports.A2SPort, ports.RconPort))
+	return result, nil
+}
+
+// nextCloneID returns the first free "<id>-N" for a clone
+func (im *InstanceManager) nextCloneID(srcID string) string {
+	for i := 2; ; i++ {
+		id := fmt.Sprintf("%s-%d", srcID, i)
+		if im.Get(id) == nil {
+			return id
+		}
+	}
+}
+
+// nextFreePorts shifts the ports of a config to the first block no instance and no open socket uses.
+// The game port starts from the advanced port override when one is set, as that is the port the server binds.
+func (im *InstanceManager) nextFreePorts(cfg map[string]any, adv *config.AdvancedSettings) (PortBlock, error) {
+	base := PortBlock{
+		BindPort: intField(cfg, defaultBindPort, "bindPort"),
+		A2SPort:  intField(cfg, defaultA2SPort, "a2s", "port"),
+		RconPort: intField(cfg, defaultRconPort, "rcon", "port"),
+	}
+	if overridesPort(adv) {
+		base.BindPort = adv.Port
+	}
+	blocks := im.SuggestPorts(base, 1)
+	if len(blocks) == 0 {
+		return PortBlock{}, fmt.Errorf("사용 가능한 포트를 찾지 못했습니다 (%d개 조합 확인)", maxPortOffset+1)
+	}
+	return blocks[0], nil
+}
+
+// applyClonePorts writes the new ports and RCON password into a server.json map
+func applyClonePorts(cfg map[string]any, ports PortBlock, password string) {
+	cfg["bindPort"] = ports.BindPort
+	if _, ok := cfg["publicPort"]; ok {
+		cfg["publicPort"] = ports.BindPort
+	}
+
+	a2s, _ := cfg["a2s"].(map[string]any)
+	if a2s == nil {
+		a2s = map[string]any{"address": "0.0.0.0"}
+		cfg["a2s"] = a2s
+	}
+	a2s["port"] = ports.A2SPort
+
+	rcon, _ := cfg["rcon"].(map[string]any)
+	if rcon == nil {
+		rcon = map[string]any{"address": "127.0.0.1", "permission": "admin"}
+		cfg["rcon"] = rcon
+	}
+	rcon["port"] = ports.RconPort
+	rcon["password"] = password
+
+	// Legacy fields some configs still carry
+	if game, ok := cfg["game"].(map[string]any); ok {
+		if _, ok := game["rconPort"]; ok {
+			game["rconPort"] = ports.RconPort
+		}
+		if _, ok := game["rconPassword"]; ok {
+			game["rconPassword"] = password
+		}
+	}
+}
+
+// intField reads a number from a nested JSON map
+func intField(m map[string]any, def int, path ...string) int {
+	var node any = m
+	for _, key := range path {
+		obj, ok := node.(map[string]any)
+		if !ok {
+			return def
+		}
+		node = obj[key]
+	}
+	if n, ok := node.(float64); ok && n > 0 {
+		return int(n)
+	}
+	return def
+}
+
+// randomPassword returns a password accepted by BattlEye RCON (no spaces)
+func randomPassword() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// copyTree copies a directory recursively and returns the number of files copied
+func copyTree(from, to string) (int, error) {
+	if _, err := os.Stat(from); os.IsNotExist(err) {
+		return 0, nil
+	}
+	count := 0
+	err := filepath.WalkDir(from, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(from, path)
+		if err != nil {
+			return err
+		}
+		dst := filepath.Join(to, rel)
+		if d.IsDir() {
+			return os.MkdirAll(dst, 0755)
+		}
+		if err := copyFile(path, dst); err != nil {
+			return err
+		}
+		count++
+		return nil
+	})
+	return count, err
+}
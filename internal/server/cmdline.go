@@ -0,0 +1,110 @@
+package server
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/astral/kg-server-web-gui/internal/agent"
+	"github.com/astral/kg-server-web-gui/internal/config"
+)
+
+// CommandLine is what a start of an instance runs
+type CommandLine struct {
+	Executable string   `json:"executable"`
+	Args       []string `json:"args"`
+	Line       string   `json:"commandLine"`
+	Node       string   `json:"node,omitempty"` // Runs on this node, which adds its own -config/-profile/addon paths
+}
+
+// serverExecutable returns the server binary Start runs; for a node it is the server directory there (empty = its SteamCMD install)
+func (im *InstanceManager) serverExecutable(inst *ServerInstance) (string, error) {
+	if inst.Node != "" {
+		return inst.Path, nil
+	}
+	path := inst.Path
+	if path == "" && im.settingsMgr != nil {
+		path = im.settingsMgr.Get().ServerPath // Fallback to global settings
+	}
+	if path == "" {
+		return "", fmt.Errorf("서버 경로가 설정되지 않았습니다. 환경 설정에서 경로를 지정해주세요")
+	}
+	return filepath.Join(path, agent.ServerBinaryName), nil
+}
+
+// CommandLine previews the command Start would run for an instance with the given extra arguments
+func (im *InstanceManager) CommandLine(id string, userArgs []string) (*CommandLine, error) {
+	inst := im.Get(id)
+	if inst == nil {
+		return nil, fmt.Errorf("서버를 찾을 수 없습니다: %s", id)
+	}
+	exe, err := im.serverExecutable(inst)
+	if err != nil {
+		return nil, err
+	}
+	args := im.ResolveServerArgs(id, userArgs)
+
+	parts := []string{quoteArg(exe)}
+	if inst.Node != "" {
+		parts[0] = fmt.Sprintf("[%s] %s", inst.Node, quoteArg(filepath.Join(exe, agent.ServerBinaryName)))
+	}
+	for _, a := range args {
+		parts = append(parts, quoteArg(a))
+	}
+	return &CommandLine{Executable: exe, Args: args, Line: strings.Join(parts, " "), Node: inst.Node}, nil
+}
+
+// AdvancedSettings returns the launch parameters of an instance (nil if never set)
+func (im *InstanceManager) AdvancedSettings(id string) (*config.AdvancedSettings, error) {
+	inst := im.Get(id)
+	if inst == nil {
+		return nil, fmt.Errorf("서버를 찾을 수 없습니다: %s", id)
+	}
+	return inst.Advanced, nil
+}
+
+// SetAdvancedSettings validates and stores the launch parameters of an instance, applied on its next start
+func (im *InstanceManager) SetAdvancedSettings(id string, adv *config.AdvancedSettings) error {
+	if err := adv.Validate(); err != nil {
+		return err
+	}
+	if err := adv.CheckSupported(); err != nil {
+		return err
+	}
+	im.mu.Lock()
+	defer im.mu.Unlock()
+	inst, ok := im.instances[id]
+	if !ok {
+		return fmt.Errorf("서버를 찾을 수 없습니다: %s", id)
+	}
+	inst.Advanced = adv
+	return im.saveLocked()
+}
+
+// appendMissingArgs appends each parameter of extra (a flag and its values) unless args already carries the flag
+func appendMissingArgs(args, extra []string) []string {
+	has := make(map[string]bool)
+	for _, a := range args {
+		if strings.HasPrefix(a, "-") {
+			has[strings.ToLower(a)] = true
+		}
+	}
+	skip := false
+	for _, a := range extra {
+		if strings.HasPrefix(a, "-") {
+			skip = has[strings.ToLower(a)]
+		}
+		if !skip {
+			args = append(args, a)
+		}
+	}
+	return args
+}
+
+// quoteArg quotes an argument containing spaces for display
+func quoteArg(a string) string {
+	if a == "" || strings.ContainsAny(a, " \t\"") {
+		return `"` + strings.ReplaceAll(a, `"`, `\"`) + `"`
+	}
+	return a
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/astral/kg-server-web-gui/internal/node"
+)
+
+// crashFiles reads the files of a crash bundle where the server ran: the panel's disk, or a node through its agent
+type crashFiles interface {
+	ReadFile(path string) ([]byte, error)
+	// CopyFile copies a file into the bundle directory on the panel
+	CopyFile(path, dst string) error
+	// Walk calls fn for every file below root; rel uses forward slashes
+	Walk(root string, fn func(path, rel string, size int64, modified time.Time))
+}
+
+// crashFiles returns where the files of an instance's crashes are read from
+func (im *InstanceManager) crashFiles(id string) (crashFiles, error) {
+	inst := im.Get(id)
+	if inst == nil || inst.Node == "" {
+		return localCrashFiles{}, nil
+	}
+	client, err := im.NodeClient(inst.Node)
+	if err != nil {
+		return nil, err
+	}
+	return nodeCrashFiles{client: client}, nil
+}
+
+type localCrashFiles struct{}
+
+func (localCrashFiles) ReadFile(path string) ([]byte, error) { return os.ReadFile(path) }
+
+func (localCrashFiles) CopyFile(path, dst string) error { return copyFile(path, dst) }
+
+func (localCrashFiles) Walk(root string, fn func(path, rel string, size int64, modified time.Time)) {
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return nil
+		}
+		fn(path, filepath.ToSlash(rel), info.Size(), info.ModTime())
+		return nil
+	})
+}
+
+// nodeCrashFiles reads through the agent's file API; paths are the node's own
+type nodeCrashFiles struct {
+	client *node.Client
+}
+
+func (n nodeCrashFiles) ReadFile(path string) ([]byte, error) {
+	data, _, err := n.client.GetFile(path)
+	return data, err
+}
+
+func (n nodeCrashFiles) CopyFile(path, dst string) error {
+	data, err := n.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dst, data, 0644)
+}
+
+func (n nodeCrashFiles) Walk(root string, fn func(path, rel string, size int64, modified time.Time)) {
+	var walk func(dir, prefix string)
+	walk = func(dir, prefix string) {
+		entries, err := n.client.ListDir(dir)
+		if err != nil {
+			return
+		}
+		for _, e := range entries {
+			// The node may use either separator; the agent accepts forward slashes on every OS
+			path := strings.TrimRight(dir, `/\`) + "/" + e.Name
+			if e.IsDir {
+				walk(path, prefix+e.Name+"/")
+				continue
+			}
+			fn(path, prefix+e.Name, e.Size, e.Modified)
+		}
+	}
+	walk(root, "")
+}
@@ -0,0 +1,343 @@
+package server
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/astral/kg-server-web-gui/internal/agent"
+	"github.com/astral/kg-server-web-gui/internal/config"
+	"github.com/astral/kg-server-web-gui/internal/logs"
+)
+
+const (
+	crashBundleFile      = "crash.json"
+	crashConsoleLines    = 500               // Console lines kept in a bundle
+	maxCrashBundles      = 20                // Bundles kept per instance
+	maxCrashFileSize     = 256 * 1024 * 1024 // Larger dumps/logs are listed as skipped
+	crashProfileLookback = time.Hour         // Profile files considered new when the start time is unknown
+)
+
+// crashProfileExts are the profile files (engine logs, dumps) collected into a bundle
+var crashProfileExts = map[string]bool{".log": true, ".dmp": true, ".mdmp": true}
+
+// CrashBundle describes the forensics captured for one crash
+type CrashBundle struct {
+	Name        string               `json:"name"`
+	InstanceID  string               `json:"instanceId"`
+	Time        time.Time            `json:"time"`
+	Reason      string               `json:"reason"`
+	ExitCode    *int                 `json:"exitCode,omitempty"`
+	PID         int                  `json:"pid,omitempty"`
+	StartedAt   *time.Time           `json:"startedAt,omitempty"`
+	Args        []string             `json:"args,omitempty"`
+	ConfigPath  string               `json:"configPath,omitempty"`
+	ProfilePath string               `json:"profilePath,omitempty"`
+	Mods        []config.ModEntry    `json:"mods,omitempty"`
+	Resources   []agent.ResourceData `json:"resources,omitempty"` // Last samples before the crash
+	Files       []string             `json:"files"`
+	Skipped     []string             `json:"skipped,omitempty"` // Profile files over the size limit
+	Size        int64                `json:"size"`
+}
+
+// crashesDir is the directory holding the bundles of an instance
+func (im *InstanceManager) crashesDir(id string) string {
+	return filepath.Join(im.dataPath, "crashes", id)
+}
+
+// captureCrash writes a forensics bundle for a crash recorded by the watchdog and returns its name
+func (im *InstanceManager) captureCrash(event agent.CrashEvent, exit *agent.ExitInfo) string {
+	monitor := im.GetMonitor(event.InstanceID)
+	if monitor == nil {
+		return ""
+	}
+	if exit == nil {
+		exit = monitor.LastExit()
+	}
+
+	name := event.ID
+	dir := filepath.Join(im.crashesDir(event.InstanceID), name)
+	for i := 2; ; i++ {
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			break
+		}
+		name = fmt.Sprintf("%s-%d", event.ID, i)
+		dir = filepath.Join(im.crashesDir(event.InstanceID), name)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		logs.GlobalLogs.Error(fmt.Sprintf("[%s] 크래시 기록 폴더 생성 실패: %v", event.InstanceID, err))
+		return ""
+	}
+
+	bundle := &CrashBundle{
+		Name:       name,
+		InstanceID: event.InstanceID,
+		Time:       event.Timestamp,
+		Reason:     event.Reason,
+		ExitCode:   event.ExitCode,
+		Files:      []string{},
+	}
+
+	// Arguments of the process that crashed, or those the instance would start with
+	args := im.ResolveServerArgs(event.InstanceID, nil)
+	if exit != nil && exit.Record != nil {
+		args = exit.Record.Args
+		bundle.PID = exit.Record.PID
+		if exit.Record.StartTime > 0 {
+			started := time.UnixMilli(exit.Record.StartTime)
+			bundle.StartedAt = &started
+		}
+	}
+	bundle.Args = args
+	bundle.ConfigPath = argValue(args, "-config")
+	bundle.ProfilePath = argValue(args, "-profile")
+
+	if lines, err := monitor.TailLogFile("", crashConsoleLines); err == nil {
+		bundle.addFile(dir, agent.ConsoleLogName, []byte(strings.Join(lines, "\n")+"\n"))
+	}
+
+	// -config and -profile are paths where the server ran, which is the node for a remote server
+	files, err := im.crashFiles(event.InstanceID)
+	if err != nil {
+		logs.GlobalLogs.Warn(fmt.Sprintf("[%s] 크래시 파일을 가져올 수 없습니다: %v", event.InstanceID, err))
+		files = nil
+	}
+
+	if bundle.ConfigPath != "" && files != nil {
+		if data, err := files.ReadFile(bundle.ConfigPath); err == nil {
+			var cfg config.ServerConfig
+			if json.Unmarshal(data, &cfg) == nil {
+				bundle.Mods = cfg.Game.Mods
+			}
+			bundle.addFile(dir, "server.json", redactConfig(data))
+		}
+	}
+
+	history := monitor.GetResourceHistory()
+	if len(history) > 30 {
+		history = history[len(history)-30:]
+	}
+	bundle.Resources = history
+
+	if bundle.ProfilePath != "" && files != nil {
+		since := event.Timestamp.Add(-crashProfileLookback)
+		if bundle.StartedAt != nil {
+			since = *bundle.StartedAt
+		}
+		bundle.collectProfile(files, dir, since)
+	}
+
+	data, _ := json.MarshalIndent(bundle, "", "  ")
+	if err := os.WriteFile(filepath.Join(dir, crashBundleFile), data, 0644); err != nil {
+		logs.GlobalLogs.Error(fmt.Sprintf("[%s] 크래시 기록 저장 실패: %v", event.InstanceID, err))
+		return ""
+	}
+
+	im.pruneCrashBundles(event.InstanceID)
+	logs.GlobalLogs.Info(fmt.Sprintf("[%s] 크래시 기록 저장됨: %s (%d개 파일)", event.InstanceID, name, len(bundle.Files)))
+	return name
+}
+
+// addFile writes one file into the bundle directory
+func (b *CrashBundle) addFile(dir, name string, data []byte) {
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	os.MkdirAll(filepath.Dir(path), 0755)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return
+	}
+	b.Files = append(b.Files, name)
+	b.Size += int64(len(data))
+}
+
+// collectProfile copies the logs and dumps written to the profile directory since the server started
+func (b *CrashBundle) collectProfile(files crashFiles, dir string, since time.Time) {
+	files.Walk(b.ProfilePath, func(path, rel string, size int64, modified time.Time) {
+		if !crashProfileExts[strings.ToLower(filepath.Ext(rel))] || modified.Before(since) {
+			return
+		}
+		name := "profile/" + rel
+		if size > maxCrashFileSize {
+			b.Skipped = append(b.Skipped, name)
+			return
+		}
+
+		dst := filepath.Join(dir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(dst), 0755)
+		if err := files.CopyFile(path, dst); err != nil {
+			return
+		}
+		b.Files = append(b.Files, name)
+		b.Size += size
+	})
+}
+
+// redactConfig masks passwords in a server.json before it is stored in a bundle
+func redactConfig(data []byte) []byte {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return data
+	}
+	var walk func(any)
+	walk = func(node any) {
+		switch n := node.(type) {
+		case map[string]any:
+			for k, child := range n {
+				if s, ok := child.(string); ok && s != "" && strings.Contains(strings.ToLower(k), "password") {
+					n[k] = "***"
+					continue
+				}
+				walk(child)
+			}
+		case []any:
+			for _, child := range n {
+				walk(child)
+			}
+		}
+	}
+	walk(v)
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return data
+	}
+	return out
+}
+
+// ListCrashBundles returns the forensics bundles of an instance, newest first
+func (im *InstanceManager) ListCrashBundles(id string) ([]CrashBundle, error) {
+	if im.Get(id) == nil {
+		return nil, fmt.Errorf("서버를 찾을 수 없습니다: %s", id)
+	}
+
+	bundles := []CrashBundle{}
+	entries, err := os.ReadDir(im.crashesDir(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return bundles, nil
+		}
+		return nil, err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(im.crashesDir(id), entry.Name(), crashBundleFile))
+		if err != nil {
+			continue
+		}
+		var b CrashBundle
+		if json.Unmarshal(data, &b) == nil {
+			bundles = append(bundles, b)
+		}
+	}
+	sort.Slice(bundles, func(i, j int) bool { return bundles[i].Time.After(bundles[j].Time) })
+	return bundles, nil
+}
+
+// crashBundleDir resolves a bundle name to its directory, rejecting anything outside the instance's crashes
+func (im *InstanceManager) crashBundleDir(id, name string) (string, error) {
+	if name == "" || name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		return "", fmt.Errorf("잘못된 크래시 기록 이름입니다: %s", name)
+	}
+	dir := filepath.Join(im.crashesDir(id), name)
+	if _, err := os.Stat(filepath.Join(dir, crashBundleFile)); err != nil {
+		return "", fmt.Errorf("크래시 기록을 찾을 수 없습니다: %s", name)
+	}
+	return dir, nil
+}
+
+// WriteCrashBundleZip streams a bundle as a zip archive
+func (im *InstanceManager) WriteCrashBundleZip(id, name string, w io.Writer) error {
+	dir, err := im.crashBundleDir(id, name)
+	if err != nil {
+		return err
+	}
+
+	zw := zip.NewWriter(w)
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		out, err := zw.Create(name + "/" + filepath.ToSlash(rel))
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(out, f)
+		return err
+	})
+	if err != nil {
+		zw.Close()
+		return err
+	}
+	return zw.Close()
+}
+
+// CrashBundleExists reports whether a bundle can be downloaded
+func (im *InstanceManager) CrashBundleExists(id, name string) bool {
+	_, err := im.crashBundleDir(id, name)
+	return err == nil
+}
+
+// pruneCrashBundles keeps the newest bundles of an instance
+func (im *InstanceManager) pruneCrashBundles(id string) {
+	entries, err := os.ReadDir(im.crashesDir(id))
+	if err != nil {
+		return
+	}
+	var dirs []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			dirs = append(dirs, entry.Name())
+		}
+	}
+	// Names start with the crash time, so they sort chronologically
+	sort.Strings(dirs)
+	for len(dirs) > maxCrashBundles {
+		os.RemoveAll(filepath.Join(im.crashesDir(id), dirs[0]))
+		dirs = dirs[1:]
+	}
+}
+
+// argValue returns the value following flag in a server command line
+func argValue(args []string, flag string) string {
+	for i := 0; i < len(args)-1; i++ {
+		if args[i] == flag {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
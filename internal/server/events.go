@@ -0,0 +1,304 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/astral/kg-server-web-gui/internal/logs"
+)
+
+// Trigger sources
+const (
+	SourceUser      = "user"
+	SourceScheduler = "scheduler"
+	SourceDiscord   = "discord"
+	SourceChat      = "chat"
+	SourceWatchdog  = "watchdog"
+	SourceGuard     = "guard" // Resource guard
+	SourceSystem    = "system"
+)
+
+// Trigger identifies who or what caused a lifecycle event
+type Trigger struct {
+	Source string `json:"source"`
+	Name   string `json:"name,omitempty"` // Username, job name, player name...
+	Admin  bool   `json:"-"`              // Panel admin (may override maintenance locks)
+}
+
+// String renders the trigger for messages (e.g. "Discord (name)")
+func (t Trigger) String() string {
+	label := map[string]string{
+		SourceUser:      "Web UI",
+		SourceScheduler: "Scheduler",
+		SourceDiscord:   "Discord",
+		SourceChat:      "게임내",
+		SourceWatchdog:  "Watchdog",
+		SourceGuard:     "Resource Guard",
+		SourceSystem:    "System",
+	}[t.Source]
+	if label == "" {
+		label = t.Source
+	}
+	if t.Name == "" {
+		return label
+	}
+	return fmt.Sprintf("%s (%s)", label, t.Name)
+}
+
+// ByUser returns the trigger of a request made by a panel user
+func ByUser(username string) Trigger {
+	return Trigger{Source: SourceUser, Name: username}
+}
+
+// EventsFileName is the append-only lifecycle history kept in each instance's data directory
+const EventsFileName = "events.jsonl"
+
+// maxEventsFileSize triggers compaction of the history to its newest half
+const maxEventsFileSize = 4 * 1024 * 1024
+
+// EventPage is one page of an instance's history, newest first
+type EventPage struct {
+	Events []InstanceEvent `json:"events"`
+	Total  int             `json:"total"`
+	Page   int             `json:"page"`
+	Limit  int             `json:"limit"`
+}
+
+// UptimeStats summarizes how long an instance was ready for players within a window
+type UptimeStats struct {
+	From          time.Time `json:"from"`
+	To            time.Time `json:"to"`
+	UptimePercent float64   `json:"uptimePercent"`
+	UpSeconds     int64     `json:"upSeconds"`
+	CurrentUptime int64     `json:"currentUptime"` // Seconds since the server last became ready (0 if not running)
+	Starts        int       `json:"starts"`
+	Crashes       int       `json:"crashes"`
+}
+
+func (im *InstanceManager) eventsPath(id string) string {
+	return filepath.Join(im.InstanceDataDir(id), EventsFileName)
+}
+
+// RecordEvent adds a non-transition event (map change, update, ...) to an instance's history
+func (im *InstanceManager) RecordEvent(id, eventType, message string, by Trigger) {
+	im.mu.RLock()
+	inst, ok := im.instances[id]
+	status := ""
+	if ok {
+		status = inst.Status
+	}
+	im.mu.RUnlock()
+	if !ok {
+		return
+	}
+
+	im.publish(InstanceEvent{
+		InstanceID: id,
+		Type:       eventType,
+		Status:     status,
+		Message:    message,
+		Trigger:    &by,
+	})
+}
+
+// appendEvent persists an event to the instance's history file
+func (im *InstanceManager) appendEvent(event InstanceEvent) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+
+	im.historyMu.Lock()
+	defer im.historyMu.Unlock()
+
+	path := im.eventsPath(event.InstanceID)
+	os.MkdirAll(filepath.Dir(path), 0755)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		logs.GlobalLogs.Warn(fmt.Sprintf("[%s] 이벤트 기록 실패: %v", event.InstanceID, err))
+		return
+	}
+	f.Write(append(data, '\n'))
+	info, _ := f.Stat()
+	f.Close()
+
+	if info != nil && info.Size() > maxEventsFileSize {
+		compactEvents(path)
+	}
+}
+
+// compactEvents drops the oldest half of a history file
+func compactEvents(path string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	cut := bytes.IndexByte(data[len(data)/2:], '\n')
+	if cut < 0 {
+		return
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data[len(data)/2+cut+1:], 0644); err != nil {
+		return
+	}
+	os.Rename(tmp, path)
+}
+
+// readEvents loads an instance's history, oldest first
+func (im *InstanceManager) readEvents(id string) ([]InstanceEvent, error) {
+	im.historyMu.Lock()
+	defer im.historyMu.Unlock()
+
+	f, err := os.Open(im.eventsPath(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []InstanceEvent{}, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var events []InstanceEvent
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e InstanceEvent
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue // Skip a line torn by a crash
+		}
+		events = append(events, e)
+	}
+	return events, scanner.Err()
+}
+
+// ListEvents returns one page of an instance's history, newest first.
+// types optionally restricts the result to the given event types.
+func (im *InstanceManager) ListEvents(id string, page, limit int, types []string) (*EventPage, error) {
+	if im.Get(id) == nil {
+		return nil, fmt.Errorf("서버를 찾을 수 없습니다: %s", id)
+	}
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 500 {
+		limit = 50
+	}
+
+	events, err := im.readEvents(id)
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[string]bool, len(types))
+	for _, t := range types {
+		if t != "" {
+			wanted[t] = true
+		}
+	}
+
+	filtered := make([]InstanceEvent, 0, len(events))
+	for i := len(events) - 1; i >= 0; i-- {
+		if len(wanted) == 0 || wanted[events[i].Type] {
+			filtered = append(filtered, events[i])
+		}
+	}
+
+	result := &EventPage{Events: []InstanceEvent{}, Total: len(filtered), Page: page, Limit: limit}
+	start := (page - 1) * limit
+	if start < len(filtered) {
+		end := min(start+limit, len(filtered))
+		result.Events = filtered[start:end]
+	}
+	return result, nil
+}
+
+// lastEvent returns the newest recorded event of an instance that changed its status
+func (im *InstanceManager) lastEvent(id string) *InstanceEvent {
+	events, err := im.readEvents(id)
+	if err != nil {
+		return nil
+	}
+	for i := len(events) - 1; i >= 0; i-- {
+		if events[i].Status != "" {
+			return &events[i]
+		}
+	}
+	return nil
+}
+
+// Uptime computes the share of [from, now] during which the instance was ready (running)
+func (im *InstanceManager) Uptime(id string, from time.Time) (*UptimeStats, error) {
+	if im.Get(id) == nil {
+		return nil, fmt.Errorf("서버를 찾을 수 없습니다: %s", id)
+	}
+	events, err := im.readEvents(id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	stats := &UptimeStats{From: from, To: now}
+
+	up := false
+	var upSince time.Time
+	var upTime time.Duration
+	for _, e := range events {
+		if e.Time.After(now) {
+			break
+		}
+		if !e.Time.Before(from) {
+			switch e.Type {
+			case EventStarting:
+				stats.Starts++
+			case EventCrashed, EventStartFailed:
+				stats.Crashes++
+			}
+		}
+		if e.Status == "" {
+			continue
+		}
+
+		running := e.Status == StatusRunning
+		switch {
+		case running && !up:
+			up = true
+			upSince = e.Time
+		case !running && up:
+			up = false
+			end := e.Time
+			if e.EndedAt != nil && e.EndedAt.Before(end) {
+				end = *e.EndedAt // Don't count the time the panel was down after the server's last sign of life
+			}
+			upTime += overlap(upSince, end, from, now)
+		}
+	}
+	if up {
+		upTime += overlap(upSince, now, from, now)
+		stats.CurrentUptime = int64(now.Sub(upSince).Seconds())
+	}
+
+	stats.UpSeconds = int64(upTime.Seconds())
+	if window := now.Sub(from); window > 0 {
+		stats.UptimePercent = float64(upTime) / float64(window) * 100
+	}
+	return stats, nil
+}
+
+// overlap returns how much of [a, b] lies within [from, to]
+func overlap(a, b, from, to time.Time) time.Duration {
+	if a.Before(from) {
+		a = from
+	}
+	if b.After(to) {
+		b = to
+	}
+	if !b.After(a) {
+		return 0
+	}
+	return b.Sub(a)
+}
@@ -9,31 +9,88 @@ import (
 	"time"
 
 	"github.com/astral/kg-server-web-gui/internal/agent"
+	"github.com/astral/kg-server-web-gui/internal/config"
 	"github.com/astral/kg-server-web-gui/internal/logs"
+	"github.com/astral/kg-server-web-gui/internal/node"
 	"github.com/astral/kg-server-web-gui/internal/settings"
 )
 
 // ServerInstance represents a single server instance
 type ServerInstance struct {
-	ID          string            `json:"id"`
-	Name        string            `json:"name"`
-	Path        string            `json:"path"`       // Path to server directory
-	ConfigPath  string            `json:"configPath"` // Path to server.json
-	Status      string            `json:"status"`     // running, stopped, error
-	PID         int               `json:"pid"`
-	CreatedAt   time.Time         `json:"createdAt"`
-	LastStarted *time.Time        `json:"lastStarted,omitempty"`
-	Settings    map[string]string `json:"settings"` // Additional settings
+	ID            string                   `json:"id"`
+	Name          string                   `json:"name"`
+	Path          string                   `json:"path"`       // Path to server directory
+	ConfigPath    string                   `json:"configPath"` // Path to server.json (empty = server.json in the workspace)
+	Status        string                   `json:"status"`     // stopped, starting, running, stopping, crashed, updating
+	PID           int                      `json:"pid"`
+	CreatedAt     time.Time                `json:"createdAt"`
+	LastStarted   *time.Time               `json:"lastStarted,omitempty"`
+	Settings      map[string]string        `json:"settings"`                // Additional settings
+	Shutdown      *ShutdownPolicy          `json:"shutdown,omitempty"`      // Graceful shutdown sequence (nil = defaults)
+	Readiness     *ReadinessProbe          `json:"readiness,omitempty"`     // When a started server counts as running (nil = defaults)
+	Liveness      *LivenessConfig          `json:"liveness,omitempty"`      // Hang detection while running (nil = off)
+	Restart       *agent.RestartPolicy     `json:"restartPolicy,omitempty"` // Watchdog restarts after a crash (nil = defaults)
+	ResourceGuard *ResourceGuard           `json:"resourceGuard,omitempty"` // Restart on sustained memory/CPU use (nil = off)
+	Maintenance   *MaintenanceLock         `json:"maintenance,omitempty"`   // Set while the server is being worked on
+	Node          string                   `json:"node,omitempty"`          // Agent node running the server (empty = this host)
+	Advanced      *config.AdvancedSettings `json:"advanced,omitempty"`      // Engine launch parameters (nil = the ad-hoc keys in Settings)
+	SharedBans    *bool                    `json:"sharedBans,omitempty"`    // Takes part in the global ban list (nil = yes)
 }
 
 // InstanceManager manages multiple server instances
 type InstanceManager struct {
 	mu          sync.RWMutex
 	instances   map[string]*ServerInstance
-	monitors    map[string]*agent.ProcessMonitor
+	monitors    map[string]agent.ProcessController
 	dataPath    string
 	settingsMgr *settings.SettingsManager
 
+	// Directory holding the instance workspaces, fixed at startup
+	workspaceRoot string
+
+	// Graceful shutdown sequences, one per instance
+	shutdownMu sync.Mutex
+	shutdowns  map[string]*shutdownTask
+
+	// Readiness probes of starting instances
+	readyMu   sync.Mutex
+	readiness map[string]*readinessWatch
+
+	// Lifecycle events: subscribers, persisted history and who started the current operation
+	eventMu     sync.Mutex
+	subscribers []func(InstanceEvent)
+	historyMu   sync.Mutex
+	opTriggers  map[string]Trigger
+
+	// Last-known-good snapshots, taken once an instance ran healthy for a while
+	snapMu  sync.Mutex
+	healthy map[string]*time.Timer
+
+	// Resource guard state of running instances
+	guardMu sync.Mutex
+	guards  map[string]*guardState
+
+	// Persistent RCON sessions of local instances
+	rconMu       sync.Mutex
+	rconSessions map[string]*rconEntry
+	rconSubs     map[int]func(RconEvent)
+	rconNextSub  int
+	rconHistory  map[string][]RconEvent
+
+	// RCON commands sent by panel users, per user (data/rcon_history.json)
+	cmdHistMu  sync.Mutex
+	cmdHistory map[string][]RconCommandRecord
+
+	// Bans issued through the panel (data/bans.json)
+	banMu      sync.Mutex
+	banRecords []*BanRecord
+	banPending map[string]bool // Instances whose server may differ from the global list
+	banSyncing map[string]bool
+
+	// Agent nodes instances can be bound to
+	nodesMu sync.RWMutex
+	nodes   map[string]*Node
+
 	// Integrations
 	watchdog *agent.Watchdog
 	discord  *agent.DiscordClient
@@ -46,14 +103,48 @@ func NewInstanceManager(
 	discord *agent.DiscordClient,
 ) *InstanceManager {
 	im := &InstanceManager{
-		instances:   make(map[string]*ServerInstance),
-		monitors:    make(map[string]*agent.ProcessMonitor),
-		dataPath:    dataPath,
-		settingsMgr: sm,
-		watchdog:    wd,
-		discord:     discord,
-	}
+		instances:    make(map[string]*ServerInstance),
+		monitors:     make(map[string]agent.ProcessController),
+		shutdowns:    make(map[string]*shutdownTask),
+		readiness:    make(map[string]*readinessWatch),
+		opTriggers:   make(map[string]Trigger),
+		healthy:      make(map[string]*time.Timer),
+		guards:       make(map[string]*guardState),
+		nodes:        make(map[string]*Node),
+		cmdHistory:   make(map[string][]RconCommandRecord),
+		banPending:   make(map[string]bool),
+		banSyncing:   make(map[string]bool),
+		rconSessions: make(map[string]*rconEntry),
+		rconSubs:     make(map[int]func(RconEvent)),
+		rconHistory:  make(map[string][]RconEvent),
+		dataPath:     dataPath,
+		settingsMgr:  sm,
+		watchdog:     wd,
+		discord:      discord,
+	}
+	im.workspaceRoot = im.resolveWorkspaceRoot()
+	im.loadNodes()
 	im.Load()
+	im.loadBanRecords()
+	im.loadRconCommands()
+	go im.runResourceGuard()
+	go im.runBanJobs()
+
+	// Watchdog restarts go through Start so they get readiness tracking and events
+	if wd != nil {
+		wd.SetRestartHandler(func(id string) error {
+			if err := im.maintenanceHold(id); err != nil {
+				return err
+			}
+			im.RecordEvent(id, EventRestarted, "크래시 후 자동 재시작", Trigger{Source: SourceWatchdog})
+			return im.startInstance(id, nil, Trigger{Source: SourceWatchdog}, false)
+		})
+		wd.SetHangHandler(im.handleHang)
+		wd.SetFallbackHandler(im.startSafeConfig)
+		wd.SetGiveUpHandler(im.handleGiveUp)
+		wd.SetCrashHandler(im.captureCrash)
+		wd.SetRollbackHandler(im.rollbackConfig)
+	}
 	return im
 }
 
@@ -61,10 +152,42 @@ func (im *InstanceManager) GetDataPath() string {
 	return im.dataPath
 }
 
+// newMonitor creates the process controller owning the server process of an instance
+func (im *InstanceManager) newMonitor(inst *ServerInstance) agent.ProcessController {
+	if inst.Node != "" {
+		return im.newRemoteProcess(inst)
+	}
+	id := inst.ID
+	monitor := agent.NewProcessMonitor(agent.ServerBinaryName, im.InstanceDataDir(id))
+	monitor.Label = id
+	monitor.SetLogRotation(im.logRotation())
+	monitor.SetExitHandler(func(rec *agent.ProcessRecord, err error) {
+		im.handleExit(id, err)
+	})
+	return monitor
+}
+
+// logRotation returns the console log limits from the global settings
+func (im *InstanceManager) logRotation() agent.LogRotation {
+	if im.settingsMgr == nil {
+		return agent.DefaultLogRotation()
+	}
+	s := im.settingsMgr.Get()
+	return agent.LogRotation{
+		MaxSizeMB:  s.ConsoleLogMaxSizeMB,
+		MaxAgeDays: s.ConsoleLogMaxAgeDays,
+		MaxFiles:   s.ConsoleLogMaxFiles,
+	}
+}
+
 func (im *InstanceManager) Save() error {
 	im.mu.RLock()
 	defer im.mu.RUnlock()
+	return im.saveLocked()
+}
 
+// saveLocked writes servers.json; the caller holds im.mu
+func (im *InstanceManager) saveLocked() error {
 	var instances []*ServerInstance
 	for _, inst := range im.instances {
 		instances = append(instances, inst)
@@ -87,14 +210,7 @@ func (im *InstanceManager) List() []*ServerInstance {
 	for _, inst := range im.instances {
 		// Update status from monitor
 		if monitor, ok := im.monitors[inst.ID]; ok {
-			running, pid, _ := monitor.IsRunning()
-			if running {
-				inst.Status = "running"
-				inst.PID = pid
-			} else {
-				inst.Status = "stopped"
-				inst.PID = 0
-			}
+			syncProcessState(inst, monitor)
 		}
 		list = append(list, inst)
 	}
@@ -111,21 +227,37 @@ func (im *InstanceManager) Get(id string) *ServerInstance {
 	im.mu.RUnlock()
 
 	// Update status from monitor
+	im.mu.Lock()
 	if monitor, ok := im.monitors[id]; ok {
-		running, pid, _ := monitor.IsRunning()
-		if running {
-			inst.Status = "running"
-			inst.PID = pid
-		} else {
-			inst.Status = "stopped"
-			inst.PID = 0
-		}
+		syncProcessState(inst, monitor)
 	}
+	im.mu.Unlock()
 	return inst
 }
 
+// syncProcessState refreshes the PID and fixes up states the process contradicts.
+// Transitions driven by readiness, shutdown and exit handling are left alone.
+func syncProcessState(inst *ServerInstance, monitor agent.ProcessController) {
+	running, pid, err := monitor.IsRunning()
+	if err != nil {
+		return // Status of a node not known yet or unreachable: keep the last state
+	}
+	if running {
+		inst.PID = pid
+		if inst.Status == "" || inst.Status == StatusStopped {
+			inst.Status = StatusRunning
+		}
+		return
+	}
+
+	inst.PID = 0
+	if inst.Status == "" {
+		inst.Status = StatusStopped
+	}
+}
+
 // GetMonitor returns the process monitor for the given instance ID
-func (im *InstanceManager) GetMonitor(id string) *agent.ProcessMonitor {
+func (im *InstanceManager) GetMonitor(id string) agent.ProcessController {
 	im.mu.RLock()
 	defer im.mu.RUnlock()
 	return im.monitors[id]
@@ -139,18 +271,31 @@ func (im *InstanceManager) Create(inst *ServerInstance) error {
 		return fmt.Errorf("서버 ID가 이미 존재합니다: %s", inst.ID)
 	}
 
+	if inst.ID == "" || inst.ID != filepath.Base(inst.ID) || inst.ID == "." || inst.ID == ".." {
+		return fmt.Errorf("잘못된 서버 ID입니다: %s", inst.ID)
+	}
+	if inst.Node != "" {
+		if _, err := im.NodeClient(inst.Node); err != nil {
+			return err
+		}
+	}
+	if err := ensureWorkspace(im.workspaceFor(inst.ID, inst.ConfigPath)); err != nil {
+		return fmt.Errorf("작업 폴더 생성 실패: %w", err)
+	}
+
 	inst.CreatedAt = time.Now()
-	inst.Status = "stopped"
+	inst.Status = StatusStopped
 	if inst.Settings == nil {
 		inst.Settings = make(map[string]string)
 	}
 
 	im.instances[inst.ID] = inst
-	im.monitors[inst.ID] = agent.NewProcessMonitor("ArmaReforgerServer.exe")
+	im.monitors[inst.ID] = im.newMonitor(inst)
 
-	return im.Save()
+	return im.saveLocked()
 }
 
+// Delete removes a stopped instance and moves its workspace to data/archive
 func (im *InstanceManager) Delete(id string) error {
 	im.mu.Lock()
 	defer im.mu.Unlock()
@@ -158,11 +303,39 @@ func (im *InstanceManager) Delete(id string) error {
 	if id == "default" {
 		return fmt.Errorf("기본 서버는 삭제할 수 없습니다")
 	}
+	inst, ok := im.instances[id]
+	if !ok {
+		return fmt.Errorf("서버를 찾을 수 없습니다: %s", id)
+	}
+	if monitor := im.monitors[id]; monitor != nil {
+		running, _, err := monitor.IsRunning()
+		if err != nil {
+			// A node that cannot be reached may still run the server
+			return fmt.Errorf("서버 상태를 확인할 수 없어 삭제할 수 없습니다: %w", err)
+		}
+		if running {
+			return fmt.Errorf("실행 중인 서버는 삭제할 수 없습니다")
+		}
+	}
 
+	if remote, ok := im.monitors[id].(*node.RemoteProcess); ok {
+		remote.Close()
+	}
 	delete(im.instances, id)
 	delete(im.monitors, id)
+	im.closeRconSession(id)
+	if im.watchdog != nil {
+		im.watchdog.UnregisterInstance(id)
+	}
+	im.cancelHealthy(id)
+
+	if archived, err := im.archiveWorkspace(id); err != nil {
+		logs.GlobalLogs.Warn(fmt.Sprintf("[%s] 작업 폴더 보관 실패: %v", inst.Name, err))
+	} else if archived != "" {
+		logs.GlobalLogs.Info(fmt.Sprintf("[%s] 서버 삭제, 작업 폴더 보관: %s", inst.Name, archived))
+	}
 
-	return im.Save()
+	return im.saveLocked()
 }
 
 // ... existing Load, Save, List, Get, Create, Delete ...
@@ -172,32 +345,19 @@ func (im *InstanceManager) Load() error {
 	defer im.mu.Unlock()
 
 	path := filepath.Join(im.dataPath, "servers.json")
+	var instances []*ServerInstance
 	data, err := os.ReadFile(path)
 	if err != nil {
-		if os.IsNotExist(err) {
-			// Create default instance
-			im.instances["default"] = &ServerInstance{
-				ID:        "default",
-				Name:      "기본 서버",
-				Status:    "stopped",
-				CreatedAt: time.Now(),
-				Settings:  make(map[string]string),
-			}
-			// Fix: Initialize monitor for default instance
-			im.monitors["default"] = agent.NewProcessMonitor("ArmaReforgerServer.exe")
-			return nil
+		if !os.IsNotExist(err) {
+			return err
 		}
-		return err
-	}
-
-	var instances []*ServerInstance
-	if err := json.Unmarshal(data, &instances); err != nil {
+		// No servers.json yet: only the default instance below
+	} else if err := json.Unmarshal(data, &instances); err != nil {
 		return err
 	}
 
 	for _, inst := range instances {
 		im.instances[inst.ID] = inst
-		im.monitors[inst.ID] = agent.NewProcessMonitor("ArmaReforgerServer.exe")
 	}
 
 	// Fix #23: Ensure default instance always exists
@@ -205,17 +365,90 @@ func (im *InstanceManager) Load() error {
 		im.instances["default"] = &ServerInstance{
 			ID:        "default",
 			Name:      "기본 서버",
-			Status:    "stopped",
+			Status:    StatusStopped,
 			CreatedAt: time.Now(),
 			Settings:  make(map[string]string),
 		}
-		im.monitors["default"] = agent.NewProcessMonitor("ArmaReforgerServer.exe")
+	}
+
+	// Move the pre-workspace layout before the monitors look for PID files and logs
+	if im.migrateWorkspaces() {
+		im.saveLocked()
+	}
+	for id, inst := range im.instances {
+		im.monitors[id] = im.newMonitor(inst)
+	}
+
+	// Reattach to servers that kept running while the panel was down
+	for id, inst := range im.instances {
+		im.reattach(inst, im.monitors[id])
 	}
 
 	return nil
 }
 
+// reattach adopts a still-running server from its persisted PID record and hands it back to the watchdog
+func (im *InstanceManager) reattach(inst *ServerInstance, monitor agent.ProcessController) {
+	rec, err := monitor.Adopt()
+	if err != nil {
+		logs.GlobalLogs.Warn(fmt.Sprintf("[%s] PID 기록 읽기 실패: %v", inst.Name, err))
+		return
+	}
+	if rec == nil {
+		// The history may still say the server was up when the panel went down
+		if last := im.lastEvent(inst.ID); last != nil && last.Status != StatusStopped && last.Status != StatusCrashed && last.Status != StatusUpdating {
+			// The exit itself went unseen; its last console output is the best guess of when it happened
+			ended := last.Time
+			if out, err := monitor.LastOutput(); err == nil && out.After(ended) && out.Before(time.Now()) {
+				ended = out
+			}
+			im.publish(InstanceEvent{
+				InstanceID: inst.ID,
+				Type:       EventExited,
+				Status:     StatusStopped,
+				Message:    "패널이 중지된 동안 서버가 종료되었습니다",
+				Trigger:    &Trigger{Source: SourceSystem},
+				EndedAt:    &ended,
+			})
+		}
+		return
+	}
+
+	started := time.UnixMilli(rec.StartTime)
+	inst.Status = StatusRunning // It was serving players before the panel went down
+	inst.PID = rec.PID
+	inst.LastStarted = &started
+	go im.OpenRcon(inst.ID) // Waits for Load to release the lock
+
+	if im.watchdog != nil {
+		im.watchdog.RegisterInstance(inst.ID, rec.Exe, rec.Args, monitor)
+		im.watchdog.SetRestartPolicy(inst.ID, inst.Restart)
+		im.installLiveness(inst.ID, inst.Liveness)
+	}
+	im.scheduleSnapshot(inst.ID, time.Duration(inst.Restart.WithDefaults().HealthyAfter)*time.Second-time.Since(started))
+
+	im.publish(InstanceEvent{
+		InstanceID: inst.ID,
+		Type:       EventReattached,
+		Status:     StatusRunning,
+		Message:    fmt.Sprintf("PID %d", rec.PID),
+		Trigger:    &Trigger{Source: SourceSystem},
+	})
+	logs.GlobalLogs.Info(fmt.Sprintf("[%s] 실행 중인 서버에 다시 연결했습니다 (PID %d)", inst.Name, rec.PID))
+}
+
+// Start launches an instance on behalf of a panel user; see StartBy
 func (im *InstanceManager) Start(id string, args []string) error {
+	return im.StartBy(id, args, Trigger{Source: SourceUser})
+}
+
+// StartBy launches an instance; it is reported as starting until its readiness probe succeeds
+func (im *InstanceManager) StartBy(id string, args []string, by Trigger) error {
+	return im.startInstance(id, args, by, true)
+}
+
+// startInstance launches an instance. Watchdog restarts pass resumeWatchdog=false to keep its restart counters.
+func (im *InstanceManager) startInstance(id string, args []string, by Trigger, resumeWatchdog bool) error {
 	// Resolve full arguments based on id and user input
 	fullArgs := im.ResolveServerArgs(id, args)
 
@@ -226,37 +459,53 @@ func (im *InstanceManager) Start(id string, args []string) error {
 		return fmt.Errorf("서버를 찾을 수 없습니다: %s", id)
 	}
 	im.mu.Unlock()
+	if err := im.CheckMaintenance(id, by); err != nil {
+		return err
+	}
 
 	monitor := im.monitors[id]
 	if monitor == nil {
 		return fmt.Errorf("서버 모니터를 찾을 수 없습니다: %s", id)
 	}
 
-	if inst.Path == "" {
-		// Fallback to global settings
-		if im.settingsMgr != nil {
-			settings := im.settingsMgr.Get()
-			if settings.ServerPath != "" {
-				inst.Path = settings.ServerPath
-			}
-		}
+	serverExe, err := im.serverExecutable(inst)
+	if err != nil {
+		return err
 	}
-
-	if inst.Path == "" {
-		return fmt.Errorf("서버 경로가 설정되지 않았습니다. 환경 설정에서 경로를 지정해주세요")
+	monitor.SetLogRotation(im.logRotation()) // Pick up settings changes
+	if inst.Node != "" {
+		logs.GlobalLogs.Info(fmt.Sprintf("[%s] 노드 %s에서 서버 시작 중", inst.Name, inst.Node))
+	} else {
+		logs.GlobalLogs.Info(fmt.Sprintf("[%s] 서버 시작 중: %s", inst.Name, serverExe))
 	}
 
-	serverExe := filepath.Join(inst.Path, "ArmaReforgerServer.exe")
-	logs.GlobalLogs.Info(fmt.Sprintf("[%s] 서버 시작 중: %s", inst.Name, serverExe))
+	if running, _, _ := monitor.IsRunning(); running {
+		return fmt.Errorf("서버가 이미 실행 중입니다")
+	}
+	if im.currentStatus(inst) == StatusUpdating {
+		return fmt.Errorf("서버 업데이트가 진행 중입니다")
+	}
+	if err := im.checkStartPorts(id); err != nil {
+		logs.GlobalLogs.Error(fmt.Sprintf("[%s] %v", inst.Name, err))
+		return err
+	}
 
 	// Register with Watchdog before starting or resume
 	if im.watchdog != nil {
 		im.watchdog.RegisterInstance(id, serverExe, fullArgs, monitor)
-		im.watchdog.ResumeMonitoring(id)
+		im.watchdog.SetRestartPolicy(id, inst.Restart)
+		if resumeWatchdog {
+			im.watchdog.ResumeMonitoring(id)
+		}
 	}
 
+	prevStatus := im.currentStatus(inst)
+	im.beginOperation(inst, EventStartRequested, "", by)
+	im.setStatus(inst, StatusStarting, EventStarting, "")
+
 	if err := monitor.Start(serverExe, fullArgs); err != nil {
 		logs.GlobalLogs.Error(fmt.Sprintf("[%s] 서버 시작 실패: %v", inst.Name, err))
+		im.setStatus(inst, prevStatus, EventStartFailed, err.Error())
 		if im.discord != nil {
 			im.discord.SendMessage("❌ Start Failed", fmt.Sprintf("Failed to start server %s: %v", inst.Name, err), agent.ColorRed)
 		}
@@ -264,14 +513,13 @@ func (im *InstanceManager) Start(id string, args []string) error {
 	}
 
 	now := time.Now()
+	im.mu.Lock()
 	inst.LastStarted = &now
-	inst.Status = "running"
-	logs.GlobalLogs.Info(fmt.Sprintf("[%s] 서버가 시작되었습니다", inst.Name))
-
-	if im.discord != nil {
-		im.discord.SendMessage("✅ Server Started", fmt.Sprintf("Server **%s** is now online.", inst.Name), agent.ColorGreen)
-	}
+	im.mu.Unlock()
+	logs.GlobalLogs.Info(fmt.Sprintf("[%s] 서버 프로세스가 시작되었습니다. 준비 상태를 확인합니다", inst.Name))
 
+	// Discord "online" is sent once the readiness probe succeeds
+	im.watchReadiness(inst, monitor)
 	return nil
 }
 
@@ -310,7 +558,10 @@ func (im *InstanceManager) ResolveServerArgs(id string, userArgs []string) []str
 	inst, ok := im.instances[id]
 	im.mu.RUnlock()
 
-	if ok && inst.Settings != nil {
+	if ok && inst.Advanced != nil {
+		args = appendMissingArgs(args, inst.Advanced.LaunchArgs())
+	} else if ok && inst.Settings != nil {
+		// Ad-hoc keys of instances without advanced settings
 		if fps, ok := inst.Settings["maxFPS"]; ok && fps != "" {
 			args = append(args, "-maxFPS", fps)
 		}
@@ -322,32 +573,23 @@ func (im *InstanceManager) ResolveServerArgs(id string, userArgs []string) []str
 		}
 	}
 
+	if ok && inst.Node != "" {
+		return args // The agent fills in paths on its own host
+	}
+
 	workDir, _ := os.Getwd()
 
+	ws := im.Workspace(id)
+
 	// 1. Config Path
 	if !hasConfig {
-		configPath := "server.json"
-		if id != "default" {
-			im.mu.RLock()
-			if inst, ok := im.instances[id]; ok && inst.ConfigPath != "" {
-				configPath = inst.ConfigPath
-			}
-			im.mu.RUnlock()
-		}
-		absConfig, _ := filepath.Abs(configPath)
-		args = append(args, "-config", absConfig)
+		args = append(args, "-config", ws.ConfigPath)
 	}
 
 	// 2. Profile Path
 	if !hasProfile {
-		profileRoot := filepath.Join(workDir, "profile")
-		profilePath := profileRoot
-		if id != "default" {
-			profilePath = filepath.Join(profileRoot, id)
-		}
-		absProfile, _ := filepath.Abs(profilePath)
-		os.MkdirAll(absProfile, 0755)
-		args = append(args, "-profile", absProfile)
+		os.MkdirAll(ws.ProfileDir, 0755)
+		args = append(args, "-profile", ws.ProfileDir)
 	}
 
 	// 3. Addons Path
@@ -373,39 +615,9 @@ func (im *InstanceManager) ResolveServerArgs(id string, userArgs []string) []str
 	return args
 }
 
+// Stop shuts the server down right away (no countdown), escalating from the RCON shutdown command to a kill
 func (im *InstanceManager) Stop(id string) error {
-	im.mu.RLock()
-	inst, exists := im.instances[id]
-	if !exists {
-		im.mu.RUnlock()
-		return fmt.Errorf("서버를 찾을 수 없습니다: %s", id)
-	}
-	im.mu.RUnlock()
-
-	monitor := im.monitors[id]
-	if monitor == nil {
-		return fmt.Errorf("서버 모니터를 찾을 수 없습니다: %s", id)
-	}
-
-	logs.GlobalLogs.Info(fmt.Sprintf("[%s] 서버 중지 중...", inst.Name))
-
-	// Pause Watchdog for this instance so it doesn't restart automatically
-	if im.watchdog != nil {
-		im.watchdog.PauseMonitoring(id)
-	}
-
-	if err := monitor.Stop(); err != nil {
-		return err
-	}
-
-	inst.Status = "stopped"
-	logs.GlobalLogs.Info(fmt.Sprintf("[%s] 서버가 중지되었습니다", inst.Name))
-
-	if im.discord != nil {
-		im.discord.SendMessage("🛑 Server Stopped", fmt.Sprintf("Server **%s** has been stopped manually.", inst.Name), agent.ColorYellow)
-	}
-
-	return nil
+	return im.GracefulStop(id, ShutdownOptions{Trigger: Trigger{Source: SourceUser}})
 }
 
 func (im *InstanceManager) Update(id string, updates *ServerInstance) error {
@@ -429,6 +641,48 @@ func (im *InstanceManager) Update(id string, updates *ServerInstance) error {
 	if updates.Settings != nil {
 		inst.Settings = updates.Settings
 	}
+	if updates.Shutdown != nil {
+		inst.Shutdown = updates.Shutdown
+	}
+	if updates.Readiness != nil {
+		if err := updates.Readiness.Validate(); err != nil {
+			return err
+		}
+		inst.Readiness = updates.Readiness
+	}
+	if updates.Liveness != nil {
+		if err := updates.Liveness.Validate(); err != nil {
+			return err
+		}
+		inst.Liveness = updates.Liveness
+		if inst.Status == StatusRunning {
+			im.installLiveness(id, inst.Liveness)
+		}
+	}
+	if updates.ResourceGuard != nil {
+		if err := updates.ResourceGuard.Validate(); err != nil {
+			return err
+		}
+		inst.ResourceGuard = updates.ResourceGuard
+	}
+	if updates.Advanced != nil {
+		if err := updates.Advanced.Validate(); err != nil {
+			return err
+		}
+		if err := updates.Advanced.CheckSupported(); err != nil {
+			return err
+		}
+		inst.Advanced = updates.Advanced
+	}
+	if updates.Restart != nil {
+		if err := updates.Restart.Validate(); err != nil {
+			return err
+		}
+		inst.Restart = updates.Restart
+		if im.watchdog != nil {
+			im.watchdog.SetRestartPolicy(id, inst.Restart)
+		}
+	}
 
-	return im.Save()
+	return im.saveLocked()
 }
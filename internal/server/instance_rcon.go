@@ -4,50 +4,62 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"path/filepath"
-	"regexp"
-	"strconv"
-	"strings"
 
+	"github.com/astral/kg-server-web-gui/internal/battleye"
 	"github.com/astral/kg-server-web-gui/internal/config"
-	"github.com/multiplay/go-battleye"
+	"github.com/astral/kg-server-web-gui/internal/node"
 )
 
+// SendRconCommand runs a command over the instance's persistent RCON session
 func (im *InstanceManager) SendRconCommand(id string, command string) (string, error) {
-	var configPath string
-
-	im.mu.RLock()
-	inst, exists := im.instances[id]
-	if exists {
-		configPath = inst.ConfigPath
+	// Servers on a node are only reachable from the node, its agent runs the command
+	if inst := im.Get(id); inst != nil && inst.Node != "" {
+		address, rconPass, err := im.rconEndpoint(id)
+		if err != nil {
+			return "", err
+		}
+		client, err := im.NodeClient(inst.Node)
+		if err != nil {
+			return "", err
+		}
+		return client.Rcon(node.RconRequest{Address: address, Password: rconPass, Command: command})
 	}
-	im.mu.RUnlock()
 
-	if !exists {
-		return "", fmt.Errorf("instance not found: %s", id)
+	session, err := im.rconSession(id)
+	if err != nil {
+		return "", err
 	}
-
-	if configPath == "" {
-		if id == "default" && im.settingsMgr != nil {
-			s := im.settingsMgr.Get()
-			if s.ServerPath != "" {
-				configPath = filepath.Join(s.ServerPath, "server.json")
-			}
-		}
+	resp, err := session.Exec(command)
+	if err != nil {
+		return "", fmt.Errorf("failed to execute command: %w", err)
 	}
+	return resp, nil
+}
 
-	if configPath == "" {
-		return "", fmt.Errorf("configuration file not specified for instance")
+// loadServerConfig reads the server.json an instance runs with
+func (im *InstanceManager) loadServerConfig(id string) (*config.ServerConfig, error) {
+	if im.Get(id) == nil {
+		return nil, fmt.Errorf("instance not found: %s", id)
 	}
+	configPath := im.ConfigPath(id)
 
 	data, err := os.ReadFile(configPath)
 	if err != nil {
-		return "", fmt.Errorf("failed to read config file (%s): %w", configPath, err)
+		return nil, fmt.Errorf("failed to read config file (%s): %w", configPath, err)
 	}
 
 	var cfg config.ServerConfig
 	if err := json.Unmarshal(data, &cfg); err != nil {
-		return "", fmt.Errorf("failed to parse config file: %w", err)
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	return &cfg, nil
+}
+
+// rconEndpoint returns the BattlEye RCON address and password of an instance
+func (im *InstanceManager) rconEndpoint(id string) (string, string, error) {
+	cfg, err := im.loadServerConfig(id)
+	if err != nil {
+		return "", "", err
 	}
 
 	// BattlEye RCON uses different config fields usually?
@@ -64,9 +76,6 @@ func (im *InstanceManager) SendRconCommand(id string, command string) (string, e
 		rconPass = cfg.Rcon.Password
 	}
 
-	// Defensive: A2S is separate, but just ensuring we don't crash if accessed elsewhere.
-	// Current function only needs RCON. Proceed.
-
 	if rconHost == "" {
 		rconHost = "127.0.0.1"
 	}
@@ -78,27 +87,38 @@ func (im *InstanceManager) SendRconCommand(id string, command string) (string, e
 	}
 
 	if rconPass == "" {
-		return "", fmt.Errorf("RCON password not set in config")
+		return "", "", fmt.Errorf("RCON password not set in config")
 	}
 	if rconPort == 0 {
-		return "", fmt.Errorf("RCON port not set in config")
+		return "", "", fmt.Errorf("RCON port not set in config")
 	}
 
-	address := fmt.Sprintf("%s:%d", rconHost, rconPort)
+	return fmt.Sprintf("%s:%d", rconHost, rconPort), rconPass, nil
+}
 
-	// BattlEye RCON Connection
-	c, err := battleye.NewClient(address, rconPass)
+// a2sEndpoint returns the address answering Steam server queries for an instance
+func (im *InstanceManager) a2sEndpoint(id string) (string, error) {
+	cfg, err := im.loadServerConfig(id)
 	if err != nil {
-		return "", fmt.Errorf("failed to connect to BattlEye RCON: %w", err)
+		return "", err
 	}
-	defer c.Close()
-
-	resp, err := c.Exec(command)
-	if err != nil {
-		return "", fmt.Errorf("failed to execute command: %w", err)
+	if cfg.A2S == nil || cfg.A2S.Port == 0 {
+		return "", fmt.Errorf("A2S port not set in config")
 	}
 
-	return resp, nil
+	host := cfg.A2S.Address
+	if host == "" || host == "0.0.0.0" || host == "127.0.0.1" {
+		host = "127.0.0.1"
+		// A server on a node answers on the node's address
+		if inst := im.Get(id); inst != nil && inst.Node != "" {
+			client, err := im.NodeClient(inst.Node)
+			if err != nil {
+				return "", err
+			}
+			host = client.Host()
+		}
+	}
+	return fmt.Sprintf("%s:%d", host, cfg.A2S.Port), nil
 }
 
 type ServerMetrics struct {
@@ -107,116 +127,41 @@ type ServerMetrics struct {
 	Players     []string `json:"players"`
 }
 
-type Player struct {
-	Index  int    `json:"index"`
-	Name   string `json:"name"`
-	IP     string `json:"ip"`
-	UID    string `json:"uid"`
-	BEGUID string `json:"beguid"`
+// Commands returns typed BattlEye commands for an instance; they go through SendRconCommand,
+// so servers on a node are reached through their agent
+func (im *InstanceManager) Commands(id string) *battleye.Commands {
+	return battleye.NewCommands(battleye.ExecFunc(func(cmd string) (string, error) {
+		return im.SendRconCommand(id, cmd)
+	}))
 }
 
-var playersRegex = regexp.MustCompile(`^(\d+)\s+(.*?)\s+([0-9\.:]+)\s+(\d+)\s+([A-Za-z0-9]+)$`)
-
-func (im *InstanceManager) GetPlayers(id string) ([]Player, error) {
-	resp, err := im.SendRconCommand(id, "players")
-	if err != nil {
-		return nil, err
-	}
-
-	var players []Player
-	lines := strings.Split(resp, "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" || strings.HasPrefix(line, "Index") || strings.HasPrefix(line, "---") {
-			continue
-		}
-
-		// Simple space split might fail if name has spaces. Use Regex or handle carefully.
-		// Arma Reforger output format might be:
-		// Index   Name            IP              UID               BEGUID
-		// 0       User Name       127.0.0.1:0     123456789         BE123...
-		// Let's assume standard whitespace separation for now, but name is tricky.
-		// Using fields is risky if name has spaces. But usually Index is first.
-
-		// Attempt to assume last 3 fields are IP, UID, BEGUID.
-		// Rest in middle is Name. First is Index.
-		fields := strings.Fields(line)
-		if len(fields) < 5 {
-			continue
-		}
-
-		idx, _ := strconv.Atoi(fields[0])
-		beguid := fields[len(fields)-1]
-		uid := fields[len(fields)-2]
-		ip := fields[len(fields)-3]
-
-		// Name is everything between index and IP
-		// Reconstruct name from fields[1 : len-3]
-		nameParts := fields[1 : len(fields)-3]
-		name := strings.Join(nameParts, " ")
-
-		players = append(players, Player{
-			Index:  idx,
-			Name:   name,
-			IP:     ip,
-			UID:    uid,
-			BEGUID: beguid,
-		})
-	}
-
-	return players, nil
+// GetPlayers lists the players connected to an instance
+func (im *InstanceManager) GetPlayers(id string) ([]battleye.Player, error) {
+	return im.Commands(id).Players()
 }
 
+// KickPlayer kicks a player by BE player number
 func (im *InstanceManager) KickPlayer(id string, playerIndex int, reason string) error {
-	cmd := fmt.Sprintf("kick %d %s", playerIndex, reason)
-	_, err := im.SendRconCommand(id, cmd)
-	return err
-}
-
-func (im *InstanceManager) BanPlayer(id string, input string, reason string) error {
-	// input can be index, name, or uid? Usually BEGUID or Index for ban
-	// Reforger console: ban <player> [reason] [duration]
-	cmd := fmt.Sprintf("ban %s %s", input, reason)
-	_, err := im.SendRconCommand(id, cmd)
-	return err
+	return im.Commands(id).Kick(playerIndex, reason)
 }
 
 func (im *InstanceManager) GetServerMetrics(id string) (*ServerMetrics, error) {
-	// Get players using the new helper
-	players, err := im.GetPlayers(id)
+	commands := im.Commands(id)
+	players, err := commands.Players()
 	if err != nil {
-		// Log error but continue with empty players?
-		// For metrics, we might want to fail or return partial
-		players = []Player{}
-	}
-
-	// Parsing FPS from 'status'
-	// Output: "Load: 10%  FPS: 59.9  Players: 5/64" (Example)
-	fps := 0.0
-	statusResp, err := im.SendRconCommand(id, "status")
-	if err == nil {
-		// Parse FPS
-		// Simple regex for FPS: [\d\.]+
-		// Need robust parsing based on actual output
-		// Let's assume it contains "FPS: X"
-		if strings.Contains(statusResp, "FPS:") {
-			parts := strings.Split(statusResp, "FPS:")
-			if len(parts) > 1 {
-				fmt.Sscanf(parts[1], "%f", &fps)
-			}
-		}
+		// Metrics stay partial while RCON is not ready
+		players = []battleye.Player{}
 	}
+	status, _ := commands.Status()
 
 	playerNames := make([]string, len(players))
 	for i, p := range players {
 		playerNames[i] = p.Name
 	}
 
-	metrics := &ServerMetrics{
-		FPS:         fps,
+	return &ServerMetrics{
+		FPS:         status.FPS,
 		PlayerCount: len(players),
 		Players:     playerNames,
-	}
-
-	return metrics, nil
+	}, nil
 }
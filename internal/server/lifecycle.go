@@ -0,0 +1,473 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/astral/kg-server-web-gui/internal/a2s"
+	"github.com/astral/kg-server-web-gui/internal/agent"
+	"github.com/astral/kg-server-web-gui/internal/logs"
+)
+
+// Instance lifecycle states
+const (
+	StatusStopped  = "stopped"
+	StatusStarting = "starting" // Process launched, waiting for the readiness probe
+	StatusRunning  = "running"  // Ready for players
+	StatusStopping = "stopping"
+	StatusCrashed  = "crashed" // Exited unexpectedly or never became ready
+	StatusUpdating = "updating"
+)
+
+// Instance event types
+const (
+	EventStartRequested     = "start_requested"
+	EventStarting           = "starting"
+	EventReady              = "ready"
+	EventStartFailed        = "start_failed"
+	EventStopRequested      = "stop_requested"
+	EventRestartRequested   = "restart_requested"
+	EventStopping           = "stopping"
+	EventStopped            = "stopped"
+	EventExited             = "exited" // Process ended; carries the exit code when known
+	EventCrashed            = "crashed"
+	EventRestarted          = "restarted"       // Watchdog restart after a crash
+	EventRestartGaveUp      = "restart_gave_up" // Watchdog used up its restart attempts
+	EventRestartsReset      = "restarts_reset"  // Watchdog attempts cleared from the panel
+	EventConfigSnapshot     = "config_snapshot" // Config kept as last-known-good after running healthy
+	EventRolledBack         = "rolled_back"     // Last-known-good config restored after a crash loop
+	EventResourceGuard      = "resource_guard"  // Memory/CPU stayed over the guard's threshold
+	EventCloned             = "cloned"          // Instance created as a copy of another
+	EventMaintenanceSet     = "maintenance_set"
+	EventMaintenanceCleared = "maintenance_cleared"
+	EventReattached         = "reattached"
+	EventUpdating           = "updating"
+	EventUpdated            = "updated"
+	EventMapChanged         = "map_changed"
+)
+
+// InstanceEvent is published (and recorded) on every lifecycle transition of an instance
+type InstanceEvent struct {
+	InstanceID string     `json:"instanceId"`
+	Type       string     `json:"type"`
+	Status     string     `json:"status,omitempty"` // State after the event; empty if unchanged
+	Message    string     `json:"message,omitempty"`
+	Trigger    *Trigger   `json:"trigger,omitempty"`
+	ExitCode   *int       `json:"exitCode,omitempty"`
+	EndedAt    *time.Time `json:"endedAt,omitempty"` // When the previous state really ended, if that was not seen (exit while the panel was down)
+	Time       time.Time  `json:"time"`
+}
+
+// Readiness probe types
+const (
+	ProbeLog  = "log"  // A console line matches Pattern
+	ProbeA2S  = "a2s"  // The server answers a Steam A2S_INFO query
+	ProbeRcon = "rcon" // A BattlEye RCON login succeeds
+)
+
+// ReadinessProbe decides when a started server is ready for players
+type ReadinessProbe struct {
+	Type     string `json:"type"`     // log, a2s or rcon
+	Pattern  string `json:"pattern"`  // Regular expression matched against console lines (log)
+	Timeout  int    `json:"timeout"`  // Seconds before the start is considered failed
+	Interval int    `json:"interval"` // Seconds between a2s/rcon attempts
+}
+
+// DefaultReadinessProbe returns the probe used when an instance has none configured
+func DefaultReadinessProbe() ReadinessProbe {
+	return ReadinessProbe{
+		Type:     ProbeLog,
+		Pattern:  `Game successfully created|Server registered with address`,
+		Timeout:  600, // First starts may download mods
+		Interval: 5,
+	}
+}
+
+// withDefaults fills unset fields from the default probe
+func (p *ReadinessProbe) withDefaults() ReadinessProbe {
+	def := DefaultReadinessProbe()
+	if p == nil {
+		return def
+	}
+	probe := *p
+	if probe.Type == "" {
+		probe.Type = def.Type
+	}
+	if probe.Type == ProbeLog && probe.Pattern == "" {
+		probe.Pattern = def.Pattern
+	}
+	if probe.Timeout <= 0 {
+		probe.Timeout = def.Timeout
+	}
+	if probe.Interval <= 0 {
+		probe.Interval = def.Interval
+	}
+	return probe
+}
+
+// Validate checks the probe type and pattern
+func (p *ReadinessProbe) Validate() error {
+	switch p.Type {
+	case "", ProbeA2S, ProbeRcon:
+	case ProbeLog:
+		if p.Pattern != "" {
+			if _, err := regexp.Compile(p.Pattern); err != nil {
+				return fmt.Errorf("준비 상태 로그 패턴이 올바르지 않습니다: %w", err)
+			}
+		}
+	default:
+		return fmt.Errorf("알 수 없는 준비 상태 검사 방식입니다: %s", p.Type)
+	}
+	return nil
+}
+
+// Subscribe registers a callback receiving every instance event
+func (im *InstanceManager) Subscribe(fn func(InstanceEvent)) {
+	im.eventMu.Lock()
+	defer im.eventMu.Unlock()
+	im.subscribers = append(im.subscribers, fn)
+}
+
+func (im *InstanceManager) publish(event InstanceEvent) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	im.appendEvent(event)
+
+	im.eventMu.Lock()
+	subs := append([]func(InstanceEvent){}, im.subscribers...)
+	im.eventMu.Unlock()
+
+	for _, fn := range subs {
+		fn(event)
+	}
+}
+
+// setStatus moves an instance to a new state and publishes the transition,
+// attributed to whoever started the current operation
+func (im *InstanceManager) setStatus(inst *ServerInstance, status, eventType, message string) {
+	im.mu.Lock()
+	inst.Status = status
+	by, ok := im.opTriggers[inst.ID]
+	im.mu.Unlock()
+
+	event := InstanceEvent{
+		InstanceID: inst.ID,
+		Type:       eventType,
+		Status:     status,
+		Message:    message,
+	}
+	if ok {
+		event.Trigger = &by
+	}
+	im.publish(event)
+	switch status {
+	case StatusRunning:
+		go im.OpenRcon(inst.ID)    // Stream server messages (chat, joins, kicks) right away
+		go im.catchUpBans(inst.ID) // Global bans issued while it was down
+	case StatusStopped, StatusCrashed:
+		im.closeRconSession(inst.ID)
+	}
+	im.applyLiveness(inst, status)
+	im.watchHealthy(inst, status)
+}
+
+// beginOperation records who requested a start/stop/update and publishes the request
+func (im *InstanceManager) beginOperation(inst *ServerInstance, eventType, message string, by Trigger) {
+	im.mu.Lock()
+	im.opTriggers[inst.ID] = by
+	im.mu.Unlock()
+
+	im.publish(InstanceEvent{
+		InstanceID: inst.ID,
+		Type:       eventType,
+		Message:    message,
+		Trigger:    &by,
+	})
+}
+
+// systemStatus records a transition the panel did not ask for (crash, failed start)
+func (im *InstanceManager) systemStatus(inst *ServerInstance, status, eventType, message string) {
+	im.mu.Lock()
+	im.opTriggers[inst.ID] = Trigger{Source: SourceSystem}
+	im.mu.Unlock()
+	im.setStatus(inst, status, eventType, message)
+}
+
+// WaitRunning blocks until a started instance passed its readiness probe.
+// It fails when the start fails, the server exits or the probe's timeout passes.
+func (im *InstanceManager) WaitRunning(ctx context.Context, id string) error {
+	im.mu.RLock()
+	inst, ok := im.instances[id]
+	var probe ReadinessProbe
+	if ok {
+		probe = inst.Readiness.withDefaults()
+	}
+	im.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("서버를 찾을 수 없습니다: %s", id)
+	}
+
+	deadline := time.After(time.Duration(probe.Timeout)*time.Second + 10*time.Second)
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		switch im.currentStatus(inst) {
+		case StatusRunning:
+			return nil
+		case StatusStopped, StatusCrashed:
+			if last := im.lastEvent(id); last != nil && last.Message != "" {
+				return fmt.Errorf("서버가 시작되지 않았습니다: %s", last.Message)
+			}
+			return fmt.Errorf("서버가 시작되지 않았습니다")
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-deadline:
+			return fmt.Errorf("%d초 안에 준비되지 않았습니다", probe.Timeout)
+		case <-ticker.C:
+		}
+	}
+}
+
+// currentStatus reads an instance's state under the lock
+func (im *InstanceManager) currentStatus(inst *ServerInstance) string {
+	im.mu.RLock()
+	defer im.mu.RUnlock()
+	return inst.Status
+}
+
+// SetUpdating marks an instance as being updated (true) or back to stopped (false)
+func (im *InstanceManager) SetUpdating(id string, updating bool, by Trigger) {
+	im.mu.RLock()
+	inst, ok := im.instances[id]
+	im.mu.RUnlock()
+	if !ok {
+		return
+	}
+
+	if updating {
+		im.mu.Lock()
+		im.opTriggers[id] = by
+		im.mu.Unlock()
+		im.setStatus(inst, StatusUpdating, EventUpdating, "")
+	} else if im.currentStatus(inst) == StatusUpdating {
+		im.setStatus(inst, StatusStopped, EventUpdated, "업데이트 완료")
+	}
+}
+
+// handleExit is called by the process monitor whenever the server process ends
+func (im *InstanceManager) handleExit(id string, err error) {
+	im.cancelReadiness(id)
+
+	im.mu.RLock()
+	inst, ok := im.instances[id]
+	im.mu.RUnlock()
+	if !ok {
+		return
+	}
+
+	exited := InstanceEvent{InstanceID: id, Type: EventExited, Trigger: &Trigger{Source: SourceSystem}}
+	var exitErr interface{ ExitCode() int } // *exec.ExitError, or the exit of a process on a remote node
+	switch {
+	case err == nil:
+		code := 0
+		exited.ExitCode = &code
+	case errors.As(err, &exitErr):
+		code := exitErr.ExitCode() // -1 when killed by a signal
+		exited.ExitCode = &code
+		exited.Message = err.Error()
+	default:
+		exited.Message = err.Error()
+	}
+	im.publish(exited)
+
+	switch im.currentStatus(inst) {
+	case StatusStopping, StatusStopped, StatusCrashed, StatusUpdating:
+		// Expected exit, or already handled
+		return
+	case StatusStarting:
+		msg := "준비 완료 전에 서버 프로세스가 종료되었습니다"
+		if err != nil {
+			msg = fmt.Sprintf("%s: %v", msg, err)
+		}
+		im.systemStatus(inst, StatusCrashed, EventStartFailed, msg)
+	default:
+		msg := "서버 프로세스가 예기치 않게 종료되었습니다"
+		if err != nil {
+			msg = fmt.Sprintf("%s: %v", msg, err)
+		}
+		im.systemStatus(inst, StatusCrashed, EventCrashed, msg)
+	}
+	logs.GlobalLogs.Warn(fmt.Sprintf("[%s] 서버 비정상 종료 감지", inst.Name))
+}
+
+// readinessWatch is a pending readiness probe of one start
+type readinessWatch struct {
+	cancel context.CancelFunc
+}
+
+// cancelReadiness stops a pending readiness probe of an instance
+func (im *InstanceManager) cancelReadiness(id string) {
+	im.readyMu.Lock()
+	defer im.readyMu.Unlock()
+	if w, ok := im.readiness[id]; ok {
+		w.cancel()
+		delete(im.readiness, id)
+	}
+}
+
+// finishReadiness forgets a probe unless a newer start replaced it; reports whether it was still current
+func (im *InstanceManager) finishReadiness(id string, w *readinessWatch) bool {
+	im.readyMu.Lock()
+	defer im.readyMu.Unlock()
+	if im.readiness[id] != w {
+		return false
+	}
+	delete(im.readiness, id)
+	return true
+}
+
+// watchReadiness probes a freshly started server in the background and promotes it to running
+func (im *InstanceManager) watchReadiness(inst *ServerInstance, monitor agent.ProcessController) {
+	im.mu.RLock()
+	probe := inst.Readiness.withDefaults()
+	im.mu.RUnlock()
+
+	im.cancelReadiness(inst.ID)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(probe.Timeout)*time.Second)
+	w := &readinessWatch{cancel: cancel}
+	im.readyMu.Lock()
+	im.readiness[inst.ID] = w
+	im.readyMu.Unlock()
+
+	go func() {
+		defer cancel()
+		err := im.waitReady(ctx, inst.ID, monitor, probe)
+		if errors.Is(err, context.Canceled) || !im.finishReadiness(inst.ID, w) {
+			// Stopped, exited or restarted meanwhile; shutdown/handleExit own the state
+			return
+		}
+
+		switch {
+		case err == nil:
+			im.markReady(inst)
+		case errors.Is(err, context.DeadlineExceeded):
+			im.failStart(inst, monitor, fmt.Sprintf("%d초 안에 준비되지 않았습니다 (%s 검사)", probe.Timeout, probe.Type))
+		default:
+			im.failStart(inst, monitor, err.Error())
+		}
+	}()
+}
+
+// waitReady blocks until the probe succeeds or ctx ends
+func (im *InstanceManager) waitReady(ctx context.Context, id string, monitor agent.ProcessController, probe ReadinessProbe) error {
+	if probe.Type == ProbeLog {
+		re, err := regexp.Compile(probe.Pattern)
+		if err != nil {
+			return fmt.Errorf("준비 상태 로그 패턴 오류: %w", err)
+		}
+
+		matched := make(chan struct{}, 1)
+		unsubscribe := monitor.SubscribeLines(func(line string) {
+			if re.MatchString(line) {
+				select {
+				case matched <- struct{}{}:
+				default:
+				}
+			}
+		})
+		defer unsubscribe()
+
+		// Lines written before the subscription are already in the console log
+		if lines, err := monitor.TailLogFile("", 1000); err == nil {
+			for _, line := range lines {
+				if re.MatchString(line) {
+					return nil
+				}
+			}
+		}
+
+		select {
+		case <-matched:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	var check func() error
+	switch probe.Type {
+	case ProbeA2S:
+		addr, err := im.a2sEndpoint(id)
+		if err != nil {
+			return err
+		}
+		check = func() error {
+			_, err := a2s.QueryInfo(addr, 3*time.Second)
+			return err
+		}
+	case ProbeRcon:
+		if _, _, err := im.rconEndpoint(id); err != nil {
+			return err
+		}
+		check = func() error {
+			return im.probeRcon(id, "", 3*time.Second)
+		}
+	default:
+		return fmt.Errorf("알 수 없는 준비 상태 검사 방식입니다: %s", probe.Type)
+	}
+
+	ticker := time.NewTicker(time.Duration(probe.Interval) * time.Second)
+	defer ticker.Stop()
+	for {
+		if check() == nil {
+			return nil
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// markReady promotes a starting instance to running
+func (im *InstanceManager) markReady(inst *ServerInstance) {
+	if im.currentStatus(inst) != StatusStarting {
+		return
+	}
+	im.setStatus(inst, StatusRunning, EventReady, "")
+	logs.GlobalLogs.Info(fmt.Sprintf("[%s] 서버가 준비되었습니다", inst.Name))
+
+	if im.discord != nil {
+		im.discord.SendMessage("✅ Server Started", fmt.Sprintf("Server **%s** is now online.", inst.Name), agent.ColorGreen)
+	}
+}
+
+// failStart kills a server that never became ready and keeps the watchdog from restarting it
+func (im *InstanceManager) failStart(inst *ServerInstance, monitor agent.ProcessController, reason string) {
+	if im.currentStatus(inst) != StatusStarting {
+		return
+	}
+
+	// Set the state first so the exit handler treats the kill as expected
+	im.systemStatus(inst, StatusCrashed, EventStartFailed, reason)
+	logs.GlobalLogs.Error(fmt.Sprintf("[%s] 서버 시작 실패: %s", inst.Name, reason))
+
+	if im.watchdog != nil {
+		im.watchdog.PauseMonitoring(inst.ID)
+	}
+	if err := monitor.Kill(); err != nil {
+		logs.GlobalLogs.Error(fmt.Sprintf("[%s] 서버 강제 종료 실패: %v", inst.Name, err))
+	}
+
+	if im.discord != nil {
+		im.discord.SendMessage("❌ Start Failed", fmt.Sprintf("Server **%s** did not become ready: %s", inst.Name, reason), agent.ColorRed)
+	}
+}
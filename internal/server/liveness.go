@@ -0,0 +1,207 @@
+package server
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/astral/kg-server-web-gui/internal/a2s"
+	"github.com/astral/kg-server-web-gui/internal/agent"
+	"github.com/astral/kg-server-web-gui/internal/logs"
+	"github.com/multiplay/go-battleye"
+)
+
+// Liveness probe types
+const (
+	LivenessRcon = "rcon" // RCON command round trip
+	LivenessA2S  = "a2s"  // A2S_INFO query answered
+	LivenessFPS  = "fps"  // Server FPS (RCON status) above a floor
+	LivenessLog  = "log"  // Console log written recently
+)
+
+// LivenessConfig detects a running server that stopped responding (deadlock, 0 FPS).
+// A hung server is recorded as crashed, killed and restarted by the watchdog.
+type LivenessConfig struct {
+	Interval int             `json:"interval"` // Seconds between probe rounds (default 30)
+	Probes   []LivenessProbe `json:"probes"`
+}
+
+// LivenessProbe is one health check of a running server
+type LivenessProbe struct {
+	Type       string  `json:"type"`                 // rcon, a2s, fps or log
+	Timeout    int     `json:"timeout,omitempty"`    // Seconds to wait for a reply (rcon, a2s; default 5)
+	MinFPS     float64 `json:"minFps,omitempty"`     // FPS floor (fps; default 1)
+	MaxSilence int     `json:"maxSilence,omitempty"` // Seconds without console output (log; default 300)
+	Failures   int     `json:"failures,omitempty"`   // Consecutive failures before the server counts as hung (default 3)
+}
+
+// Validate checks the probe types and values
+func (c *LivenessConfig) Validate() error {
+	if c.Interval < 0 {
+		return fmt.Errorf("생존 검사 주기가 올바르지 않습니다: %d", c.Interval)
+	}
+	for _, p := range c.Probes {
+		switch p.Type {
+		case LivenessRcon, LivenessA2S, LivenessFPS, LivenessLog:
+		default:
+			return fmt.Errorf("알 수 없는 생존 검사 방식입니다: %s", p.Type)
+		}
+		if p.Timeout < 0 || p.MinFPS < 0 || p.MaxSilence < 0 || p.Failures < 0 {
+			return fmt.Errorf("생존 검사 값이 올바르지 않습니다 (%s)", p.Type)
+		}
+	}
+	return nil
+}
+
+// withDefaults fills unset probe values
+func (p LivenessProbe) withDefaults() LivenessProbe {
+	if p.Timeout <= 0 {
+		p.Timeout = 5
+	}
+	if p.MinFPS <= 0 {
+		p.MinFPS = 1
+	}
+	if p.MaxSilence <= 0 {
+		p.MaxSilence = 300
+	}
+	if p.Failures <= 0 {
+		p.Failures = 3
+	}
+	return p
+}
+
+// applyLiveness installs the instance's liveness probes in the watchdog while it is running
+func (im *InstanceManager) applyLiveness(inst *ServerInstance, status string) {
+	if im.watchdog == nil {
+		return
+	}
+	if status != StatusRunning {
+		im.watchdog.ClearLivenessChecks(inst.ID)
+		return
+	}
+
+	im.mu.RLock()
+	cfg := inst.Liveness
+	im.mu.RUnlock()
+	im.installLiveness(inst.ID, cfg)
+}
+
+// installLiveness hands the probes of a running instance to the watchdog (no instance lock taken)
+func (im *InstanceManager) installLiveness(id string, cfg *LivenessConfig) {
+	if im.watchdog == nil {
+		return
+	}
+	if cfg == nil || len(cfg.Probes) == 0 {
+		im.watchdog.ClearLivenessChecks(id)
+		return
+	}
+
+	interval := time.Duration(cfg.Interval) * time.Second
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+
+	checks := make([]*agent.LivenessCheck, 0, len(cfg.Probes))
+	for _, p := range cfg.Probes {
+		p = p.withDefaults()
+		checks = append(checks, &agent.LivenessCheck{
+			Name:      p.Type,
+			Threshold: p.Failures,
+			Check:     im.livenessCheck(id, p),
+		})
+	}
+	im.watchdog.SetLivenessChecks(id, interval, checks)
+}
+
+// livenessCheck builds the check function of one probe
+func (im *InstanceManager) livenessCheck(id string, p LivenessProbe) func() error {
+	timeout := time.Duration(p.Timeout) * time.Second
+
+	switch p.Type {
+	case LivenessRcon:
+		return func() error {
+			return im.probeRcon(id, "players", timeout)
+		}
+	case LivenessA2S:
+		return func() error {
+			addr, err := im.a2sEndpoint(id)
+			if err != nil {
+				return err
+			}
+			_, err = a2s.QueryInfo(addr, timeout)
+			return err
+		}
+	case LivenessFPS:
+		return func() error {
+			// An RCON failure or a reply without FPS fails the probe as an error, not as FPS 0
+			status, err := im.Commands(id).Status()
+			if err != nil {
+				return err
+			}
+			if status.FPS < p.MinFPS {
+				return fmt.Errorf("FPS %.1f below %.1f", status.FPS, p.MinFPS)
+			}
+			return nil
+		}
+	case LivenessLog:
+		return func() error {
+			monitor := im.GetMonitor(id)
+			if monitor == nil {
+				return fmt.Errorf("instance not found: %s", id)
+			}
+			last, err := monitor.LastOutput()
+			if err != nil {
+				return err
+			}
+			if silence := time.Since(last); silence > time.Duration(p.MaxSilence)*time.Second {
+				return fmt.Errorf("no console output for %s", silence.Round(time.Second))
+			}
+			return nil
+		}
+	}
+	return func() error { return fmt.Errorf("unknown liveness probe %s", p.Type) }
+}
+
+// handleHang records a server the watchdog found hung; the watchdog kills and restarts it afterwards
+// unless maintenance holds it back
+func (im *InstanceManager) handleHang(id, reason string) error {
+	im.mu.RLock()
+	inst, ok := im.instances[id]
+	im.mu.RUnlock()
+	if !ok || im.currentStatus(inst) != StatusRunning {
+		return nil
+	}
+	if err := im.maintenanceHold(id); err != nil {
+		return err
+	}
+
+	// Set the state first so the exit handler treats the kill as expected
+	im.systemStatus(inst, StatusCrashed, EventCrashed, "서버 응답 없음: "+reason)
+	logs.GlobalLogs.Error(fmt.Sprintf("[%s] 서버 응답 없음 감지: %s", inst.Name, reason))
+	return nil
+}
+
+// probeRcon logs in to the instance's RCON and runs command, if any. Servers on a node are
+// only reachable from the node, so its agent runs the probe
+func (im *InstanceManager) probeRcon(id, command string, timeout time.Duration) error {
+	if inst := im.Get(id); inst != nil && inst.Node != "" {
+		if command == "" {
+			command = "players"
+		}
+		_, err := im.SendRconCommand(id, command)
+		return err
+	}
+
+	addr, pass, err := im.rconEndpoint(id)
+	if err != nil {
+		return err
+	}
+	c, err := battleye.NewClient(addr, pass, battleye.Timeout(timeout))
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	if command != "" {
+		_, err = c.Exec(command)
+	}
+	return err
+}
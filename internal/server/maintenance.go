@@ -0,0 +1,169 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/astral/kg-server-web-gui/internal/logs"
+)
+
+// MaintenanceLock keeps automation and remote commands away from an instance while it is worked on
+type MaintenanceLock struct {
+	Reason    string     `json:"reason"`
+	Owner     string     `json:"owner"` // Panel user who set the lock
+	Since     time.Time  `json:"since"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"` // nil = until cleared
+}
+
+// MaintenanceRequest sets a maintenance lock
+type MaintenanceRequest struct {
+	Reason    string     `json:"reason"`
+	Minutes   int        `json:"minutes"`             // Lock duration (0 = until cleared)
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"` // Explicit end, takes precedence over Minutes
+}
+
+// MaintenanceError is returned when an action is refused because of a maintenance lock
+type MaintenanceError struct {
+	InstanceID string
+	Lock       MaintenanceLock
+}
+
+func (e *MaintenanceError) Error() string {
+	msg := fmt.Sprintf("서버 %s 점검 중입니다 (설정: %s", e.InstanceID, e.Lock.Owner)
+	if e.Lock.ExpiresAt != nil {
+		msg += ", 해제 예정: " + e.Lock.ExpiresAt.Local().Format("01-02 15:04")
+	}
+	msg += ")"
+	if e.Lock.Reason != "" {
+		msg += ": " + e.Lock.Reason
+	}
+	return msg
+}
+
+// IsMaintenance reports whether err is a refusal because of a maintenance lock
+func IsMaintenance(err error) bool {
+	var me *MaintenanceError
+	return errors.As(err, &me)
+}
+
+// active reports whether the lock is still in force
+func (l *MaintenanceLock) active(now time.Time) bool {
+	return l != nil && (l.ExpiresAt == nil || now.Before(*l.ExpiresAt))
+}
+
+// CanOverride reports whether a trigger may act despite the lock: only the owner (from the panel) or an admin
+func (l *MaintenanceLock) CanOverride(by Trigger) bool {
+	return by.Admin || (by.Source == SourceUser && by.Name != "" && by.Name == l.Owner)
+}
+
+// Maintenance returns the active maintenance lock of an instance (nil if none); an expired lock is cleared
+func (im *InstanceManager) Maintenance(id string) *MaintenanceLock {
+	im.mu.Lock()
+	inst, ok := im.instances[id]
+	if !ok || inst.Maintenance == nil {
+		im.mu.Unlock()
+		return nil
+	}
+	if inst.Maintenance.active(time.Now()) {
+		lock := *inst.Maintenance
+		im.mu.Unlock()
+		return &lock
+	}
+	expired := inst.Maintenance
+	inst.Maintenance = nil
+	im.saveLocked()
+	im.mu.Unlock()
+
+	im.RecordEvent(id, EventMaintenanceCleared, strings.TrimSuffix("점검 기간 만료: "+expired.Reason, ": "), Trigger{Source: SourceSystem})
+	logs.GlobalLogs.Info(fmt.Sprintf("[%s] 점검 기간이 끝나 잠금을 해제했습니다", id))
+	return nil
+}
+
+// CheckMaintenance refuses an action on a locked instance unless the trigger may override the lock
+func (im *InstanceManager) CheckMaintenance(id string, by Trigger) error {
+	lock := im.Maintenance(id)
+	if lock == nil || lock.CanOverride(by) {
+		return nil
+	}
+	return &MaintenanceError{InstanceID: id, Lock: *lock}
+}
+
+// SetMaintenance locks an instance; an existing lock can only be replaced by its owner or an admin
+func (im *InstanceManager) SetMaintenance(id string, req MaintenanceRequest, by Trigger) (*MaintenanceLock, error) {
+	if im.Get(id) == nil {
+		return nil, fmt.Errorf("서버를 찾을 수 없습니다: %s", id)
+	}
+	if err := im.CheckMaintenance(id, by); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	lock := &MaintenanceLock{Reason: req.Reason, Owner: by.Name, Since: now}
+	switch {
+	case req.ExpiresAt != nil:
+		if !req.ExpiresAt.After(now) {
+			return nil, fmt.Errorf("해제 시각은 현재 이후여야 합니다")
+		}
+		lock.ExpiresAt = req.ExpiresAt
+	case req.Minutes > 0:
+		end := now.Add(time.Duration(req.Minutes) * time.Minute)
+		lock.ExpiresAt = &end
+	case req.Minutes < 0:
+		return nil, fmt.Errorf("점검 시간은 0 이상이어야 합니다")
+	}
+
+	im.mu.Lock()
+	inst, ok := im.instances[id]
+	if !ok {
+		im.mu.Unlock()
+		return nil, fmt.Errorf("서버를 찾을 수 없습니다: %s", id)
+	}
+	inst.Maintenance = lock
+	err := im.saveLocked()
+	im.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	im.RecordEvent(id, EventMaintenanceSet, req.Reason, by)
+	logs.GlobalLogs.Info(fmt.Sprintf("[%s] 점검 모드 설정 (%s): %s", id, by, req.Reason))
+	result := *lock
+	return &result, nil
+}
+
+// ClearMaintenance lifts the lock of an instance; only its owner or an admin may do so
+func (im *InstanceManager) ClearMaintenance(id string, by Trigger) error {
+	lock := im.Maintenance(id)
+	if lock == nil {
+		return fmt.Errorf("점검 중인 서버가 아닙니다: %s", id)
+	}
+	if !lock.CanOverride(by) {
+		return &MaintenanceError{InstanceID: id, Lock: *lock}
+	}
+
+	im.mu.Lock()
+	if inst, ok := im.instances[id]; ok {
+		inst.Maintenance = nil
+	}
+	err := im.saveLocked()
+	im.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	im.RecordEvent(id, EventMaintenanceCleared, lock.Reason, by)
+	logs.GlobalLogs.Info(fmt.Sprintf("[%s] 점검 모드 해제 (%s)", id, by))
+	return nil
+}
+
+// maintenanceHold stops the watchdog from acting on a locked instance; it pauses monitoring until the next start
+func (im *InstanceManager) maintenanceHold(id string) error {
+	err := im.CheckMaintenance(id, Trigger{Source: SourceWatchdog})
+	if err != nil && im.watchdog != nil {
+		im.watchdog.PauseMonitoring(id)
+		logs.GlobalLogs.Warn(fmt.Sprintf("[%s] 워치독 조치 보류: %v", id, err))
+	}
+	return err
+}
@@ -0,0 +1,261 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/astral/kg-server-web-gui/internal/agent"
+	"github.com/astral/kg-server-web-gui/internal/logs"
+	"github.com/astral/kg-server-web-gui/internal/node"
+)
+
+// Node is a remote host running this binary in agent mode (-mode agent)
+type Node struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	URL       string    `json:"url"` // Agent address, e.g. http://10.0.0.5:3100
+	Token     string    `json:"token,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// NodeStatus is a node with the result of asking its agent for its info
+type NodeStatus struct {
+	Node
+	Online    bool       `json:"online"`
+	Error     string     `json:"error,omitempty"`
+	Info      *node.Info `json:"info,omitempty"`
+	Instances []string   `json:"instances"` // Instances bound to the node
+}
+
+func (im *InstanceManager) nodesPath() string {
+	return filepath.Join(im.dataPath, "nodes.json")
+}
+
+// loadNodes reads data/nodes.json
+func (im *InstanceManager) loadNodes() {
+	data, err := os.ReadFile(im.nodesPath())
+	if err != nil {
+		return
+	}
+	var nodes []*Node
+	if err := json.Unmarshal(data, &nodes); err != nil {
+		logs.GlobalLogs.Warn(fmt.Sprintf("노드 목록 로드 실패: %v", err))
+		return
+	}
+	im.nodesMu.Lock()
+	defer im.nodesMu.Unlock()
+	for _, n := range nodes {
+		im.nodes[n.ID] = n
+	}
+}
+
+// saveNodesLocked writes data/nodes.json; the caller holds im.nodesMu
+func (im *InstanceManager) saveNodesLocked() error {
+	nodes := make([]*Node, 0, len(im.nodes))
+	for _, n := range im.nodes {
+		nodes = append(nodes, n)
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+	data, err := json.MarshalIndent(nodes, "", "  ")
+	if err != nil {
+		return err
+	}
+	os.MkdirAll(im.dataPath, 0755)
+	return os.WriteFile(im.nodesPath(), data, 0600) // Holds agent tokens
+}
+
+// ListNodes returns the registered nodes without their tokens
+func (im *InstanceManager) ListNodes() []Node {
+	im.nodesMu.RLock()
+	defer im.nodesMu.RUnlock()
+	nodes := make([]Node, 0, len(im.nodes))
+	for _, n := range im.nodes {
+		view := *n
+		view.Token = ""
+		nodes = append(nodes, view)
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+	return nodes
+}
+
+// AddNode registers an agent node, or updates the address and token of an existing one
+func (im *InstanceManager) AddNode(n Node) (*Node, error) {
+	if n.ID == "" || n.ID != filepath.Base(n.ID) || n.ID == "." || n.ID == ".." {
+		return nil, fmt.Errorf("잘못된 노드 ID입니다: %s", n.ID)
+	}
+	u, err := url.Parse(n.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("잘못된 에이전트 주소입니다: %s (예: http://10.0.0.5:%s)", n.URL, node.DefaultPort)
+	}
+	if n.Name == "" {
+		n.Name = n.ID
+	}
+
+	im.nodesMu.Lock()
+	if prev, ok := im.nodes[n.ID]; ok {
+		n.CreatedAt = prev.CreatedAt
+		if n.Token == "" {
+			n.Token = prev.Token
+		}
+	} else {
+		n.CreatedAt = time.Now()
+	}
+	if n.Token == "" {
+		im.nodesMu.Unlock()
+		return nil, fmt.Errorf("에이전트 토큰이 필요합니다")
+	}
+	im.nodes[n.ID] = &n
+	err = im.saveNodesLocked()
+	im.nodesMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	// Instances already bound to the node pick up the new address
+	client := node.NewClient(n.URL, n.Token)
+	im.mu.RLock()
+	for id, inst := range im.instances {
+		if remote, ok := im.monitors[id].(*node.RemoteProcess); ok && inst.Node == n.ID {
+			remote.SetClient(client)
+		}
+	}
+	im.mu.RUnlock()
+
+	logs.GlobalLogs.Info(fmt.Sprintf("노드 등록: %s (%s)", n.ID, n.URL))
+	view := n
+	view.Token = ""
+	return &view, nil
+}
+
+// DeleteNode removes a node no instance is bound to
+func (im *InstanceManager) DeleteNode(id string) error {
+	for _, inst := range im.List() {
+		if inst.Node == id {
+			return fmt.Errorf("노드 %s에 연결된 서버가 있습니다: %s", id, inst.ID)
+		}
+	}
+	im.nodesMu.Lock()
+	defer im.nodesMu.Unlock()
+	if _, ok := im.nodes[id]; !ok {
+		return fmt.Errorf("노드를 찾을 수 없습니다: %s", id)
+	}
+	delete(im.nodes, id)
+	return im.saveNodesLocked()
+}
+
+// NodeClient returns the client of a registered node
+func (im *InstanceManager) NodeClient(id string) (*node.Client, error) {
+	im.nodesMu.RLock()
+	defer im.nodesMu.RUnlock()
+	n, ok := im.nodes[id]
+	if !ok {
+		return nil, fmt.Errorf("노드를 찾을 수 없습니다: %s", id)
+	}
+	return node.NewClient(n.URL, n.Token), nil
+}
+
+// NodeStatus asks a node's agent for its host info
+func (im *InstanceManager) NodeStatus(id string) (*NodeStatus, error) {
+	var st *NodeStatus
+	for _, n := range im.ListNodes() {
+		if n.ID == id {
+			st = &NodeStatus{Node: n, Instances: []string{}}
+		}
+	}
+	if st == nil {
+		return nil, fmt.Errorf("노드를 찾을 수 없습니다: %s", id)
+	}
+	for _, inst := range im.List() {
+		if inst.Node == id {
+			st.Instances = append(st.Instances, inst.ID)
+		}
+	}
+
+	client, err := im.NodeClient(id)
+	if err != nil {
+		return nil, err
+	}
+	info, err := client.Info()
+	if err != nil {
+		st.Error = err.Error()
+		return st, nil
+	}
+	st.Online, st.Info = true, info
+	return st, nil
+}
+
+// BindNode moves a stopped instance to a node (empty = this host)
+func (im *InstanceManager) BindNode(id, nodeID string) error {
+	if nodeID != "" {
+		if _, err := im.NodeClient(nodeID); err != nil {
+			return err
+		}
+	}
+
+	im.mu.Lock()
+	defer im.mu.Unlock()
+	inst, ok := im.instances[id]
+	if !ok {
+		return fmt.Errorf("서버를 찾을 수 없습니다: %s", id)
+	}
+	if inst.Node == nodeID {
+		return nil
+	}
+	if s := inst.Status; s != StatusStopped && s != StatusCrashed {
+		return fmt.Errorf("실행 중인 서버는 다른 노드로 옮길 수 없습니다")
+	}
+	inst.Node = nodeID
+	im.replaceMonitorLocked(id)
+	logs.GlobalLogs.Info(fmt.Sprintf("[%s] 노드 변경: %s", id, nodeLabel(nodeID)))
+	return im.saveLocked()
+}
+
+// replaceMonitorLocked recreates the process controller of a stopped instance after its node changed; the caller holds im.mu
+func (im *InstanceManager) replaceMonitorLocked(id string) {
+	if old, ok := im.monitors[id].(*node.RemoteProcess); ok {
+		old.Close()
+	}
+	im.monitors[id] = im.newMonitor(im.instances[id])
+	if im.watchdog != nil {
+		im.watchdog.UnregisterInstance(id) // Registered again with the new controller on the next start
+	}
+}
+
+// newRemoteProcess creates the controller of an instance running on a node
+func (im *InstanceManager) newRemoteProcess(inst *ServerInstance) agent.ProcessController {
+	id := inst.ID
+	client, err := im.NodeClient(inst.Node)
+	if err != nil {
+		logs.GlobalLogs.Warn(fmt.Sprintf("[%s] %v", id, err))
+		client = node.NewClient("", "") // Every call fails until the node is registered again
+	}
+	remote := node.NewRemoteProcess(id, client)
+	remote.Config = func() ([]byte, error) {
+		return os.ReadFile(im.ConfigPath(id))
+	}
+	remote.SetLogRotation(im.logRotation())
+	remote.SetExitHandler(func(rec *agent.ProcessRecord, err error) {
+		im.handleExit(id, err)
+	})
+	return remote
+}
+
+// isRemote reports whether an instance runs on a node
+func (im *InstanceManager) isRemote(id string) bool {
+	im.mu.RLock()
+	defer im.mu.RUnlock()
+	inst, ok := im.instances[id]
+	return ok && inst.Node != ""
+}
+
+func nodeLabel(nodeID string) string {
+	if nodeID == "" {
+		return "로컬"
+	}
+	return nodeID
+}
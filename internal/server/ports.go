@@ -0,0 +1,296 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/astral/kg-server-web-gui/internal/config"
+)
+
+// Arma Reforger's default ports, used when a config leaves them unset
+const (
+	defaultBindPort = 2001
+	defaultA2SPort  = 17777
+	defaultRconPort = 19999
+	maxPortOffset   = 100 // Port blocks tried before giving up
+)
+
+// Port kinds of a server.json
+const (
+	PortGame   = "game"   // bindPort
+	PortPublic = "public" // publicPort (only checked against other instances, it is not bound locally)
+	PortA2S    = "a2s"
+	PortRcon   = "rcon"
+)
+
+// PortBinding is one port an instance's server.json claims
+type PortBinding struct {
+	InstanceID string `json:"instanceId"`
+	Name       string `json:"name"`
+	Kind       string `json:"kind"`
+	Port       int    `json:"port"`
+	Running    bool   `json:"running"`
+	Node       string `json:"node,omitempty"` // Host the port is bound on (empty = this host)
+}
+
+// PortConflict is a port an instance cannot use
+type PortConflict struct {
+	InstanceID string `json:"instanceId"`
+	Kind       string `json:"kind"`
+	Port       int    `json:"port"`
+	With       string `json:"with,omitempty"`     // Other instance (empty = taken on the host or by the same config)
+	WithKind   string `json:"withKind,omitempty"` // Port kind on the other side
+	Message    string `json:"message"`
+}
+
+// PortBlock is a set of ports one instance needs
+type PortBlock struct {
+	BindPort int `json:"bindPort"`
+	A2SPort  int `json:"a2sPort"`
+	RconPort int `json:"rconPort"`
+}
+
+// PortMap is the port registry across all instances
+type PortMap struct {
+	Bindings    []PortBinding  `json:"bindings"`
+	Conflicts   []PortConflict `json:"conflicts"`
+	Suggestions []PortBlock    `json:"suggestions"` // Free blocks for a new instance
+}
+
+// overridesPort reports whether advanced settings replace the bindPort of server.json with -bindPort
+func overridesPort(adv *config.AdvancedSettings) bool {
+	return adv != nil && adv.OverridePort && adv.Port != 0
+}
+
+// configPorts lists the ports a server.json claims; an advanced port override (-bindPort) replaces its bindPort
+func configPorts(cfg *config.ServerConfig, adv *config.AdvancedSettings) []PortBinding {
+	bind := cfg.BindPort
+	if overridesPort(adv) {
+		bind = adv.Port
+	}
+	if bind == 0 {
+		bind = defaultBindPort
+	}
+	ports := []PortBinding{{Kind: PortGame, Port: bind}}
+	if cfg.PublicPort != 0 {
+		ports = append(ports, PortBinding{Kind: PortPublic, Port: cfg.PublicPort})
+	}
+	if cfg.A2S != nil && cfg.A2S.Port != 0 {
+		ports = append(ports, PortBinding{Kind: PortA2S, Port: cfg.A2S.Port})
+	}
+	if cfg.Rcon != nil && cfg.Rcon.Port != 0 {
+		ports = append(ports, PortBinding{Kind: PortRcon, Port: cfg.Rcon.Port})
+	} else if cfg.Game.RconPort != 0 {
+		ports = append(ports, PortBinding{Kind: PortRcon, Port: cfg.Game.RconPort})
+	}
+	return ports
+}
+
+// portsClash reports whether two port kinds compete for the same port; public ports only clash with each other
+func portsClash(a, b string) bool {
+	return (a == PortPublic) == (b == PortPublic)
+}
+
+// readServerConfig parses the server.json an instance starts with
+func (im *InstanceManager) readServerConfig(id string) (*config.ServerConfig, error) {
+	data, err := os.ReadFile(im.activeConfigPath(id))
+	if err != nil {
+		return nil, err
+	}
+	var cfg config.ServerConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// InstanceByConfigPath returns the instance that starts with the given server.json (empty if none)
+func (im *InstanceManager) InstanceByConfigPath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return ""
+	}
+	for _, inst := range im.List() {
+		if p, err := filepath.Abs(im.activeConfigPath(inst.ID)); err == nil && p == abs {
+			return inst.ID
+		}
+	}
+	return ""
+}
+
+// portBindings collects the ports of every instance, skipping the given one
+func (im *InstanceManager) portBindings(skip string) []PortBinding {
+	var all []PortBinding
+	for _, inst := range im.List() {
+		if inst.ID == skip {
+			continue
+		}
+		cfg, err := im.readServerConfig(inst.ID)
+		if err != nil {
+			continue
+		}
+		running := im.currentStatus(inst) != StatusStopped && im.currentStatus(inst) != StatusCrashed
+		for _, b := range configPorts(cfg, inst.Advanced) {
+			b.InstanceID, b.Name, b.Running, b.Node = inst.ID, inst.Name, running, inst.Node
+			all = append(all, b)
+		}
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].Port != all[j].Port {
+			return all[i].Port < all[j].Port
+		}
+		return all[i].InstanceID < all[j].InstanceID
+	})
+	return all
+}
+
+// CheckPorts returns the ports of cfg that clash within the config, with other instances on the same host and,
+// when host is set, with sockets already open on this machine
+func (im *InstanceManager) CheckPorts(id string, cfg *config.ServerConfig, host bool) []PortConflict {
+	var adv *config.AdvancedSettings
+	if inst := im.Get(id); inst != nil {
+		adv = inst.Advanced
+	}
+	return im.checkPorts(id, cfg, adv, host)
+}
+
+// CheckAdvancedPorts returns the clashes the port override of advanced settings being saved would cause
+func (im *InstanceManager) CheckAdvancedPorts(id string, adv *config.AdvancedSettings) []PortConflict {
+	inst := im.Get(id)
+	if inst == nil || adv == nil || !adv.OverridePort {
+		return nil
+	}
+	cfg, err := im.readServerConfig(id)
+	if err != nil {
+		cfg = &config.ServerConfig{}
+	}
+	// A running server holds its own ports, so the host is only checked while it is stopped
+	status := im.currentStatus(inst)
+	return im.checkPorts(id, cfg, adv, status == StatusStopped || status == StatusCrashed)
+}
+
+func (im *InstanceManager) checkPorts(id string, cfg *config.ServerConfig, adv *config.AdvancedSettings, host bool) []PortConflict {
+	own := configPorts(cfg, adv)
+	ownNode := ""
+	if inst := im.Get(id); inst != nil {
+		ownNode = inst.Node
+	}
+	host = host && ownNode == "" // Sockets on a node are not visible from here
+	conflicts := []PortConflict{}
+
+	for i, a := range own {
+		for _, b := range own[:i] {
+			if a.Port == b.Port && a.Kind != PortPublic && b.Kind != PortPublic {
+				conflicts = append(conflicts, PortConflict{
+					InstanceID: id, Kind: a.Kind, Port: a.Port, WithKind: b.Kind,
+					Message: fmt.Sprintf("%s 포트와 %s 포트가 같습니다 (%d)", a.Kind, b.Kind, a.Port),
+				})
+			}
+		}
+	}
+
+	others := im.portBindings(id)
+	for _, a := range own {
+		for _, b := range others {
+			if b.Node != ownNode {
+				continue
+			}
+			if a.Port == b.Port && portsClash(a.Kind, b.Kind) {
+				conflicts = append(conflicts, PortConflict{
+					InstanceID: id, Kind: a.Kind, Port: a.Port, With: b.InstanceID, WithKind: b.Kind,
+					Message: fmt.Sprintf("%s 포트 %d: 서버 %s(%s)의 %s 포트와 겹칩니다", a.Kind, a.Port, b.Name, b.InstanceID, b.Kind),
+				})
+			}
+		}
+		if host && a.Kind != PortPublic && !udpPortFree(a.Port) {
+			conflicts = append(conflicts, PortConflict{
+				InstanceID: id, Kind: a.Kind, Port: a.Port,
+				Message: fmt.Sprintf("%s 포트 %d: 이 컴퓨터에서 이미 사용 중입니다", a.Kind, a.Port),
+			})
+		}
+	}
+	return conflicts
+}
+
+// checkStartPorts refuses to start an instance whose ports are taken
+func (im *InstanceManager) checkStartPorts(id string) error {
+	cfg, err := im.readServerConfig(id)
+	if err != nil {
+		return nil // A missing or broken config is reported by the server itself
+	}
+	conflicts := im.CheckPorts(id, cfg, true)
+	if len(conflicts) == 0 {
+		return nil
+	}
+	msgs := make([]string, len(conflicts))
+	for i, c := range conflicts {
+		msgs[i] = c.Message
+	}
+	return fmt.Errorf("포트 충돌로 시작할 수 없습니다: %s", strings.Join(msgs, ", "))
+}
+
+// Ports returns the port registry: every instance's ports, the clashes between them and free blocks
+func (im *InstanceManager) Ports() *PortMap {
+	m := &PortMap{
+		Bindings:  im.portBindings(""),
+		Conflicts: []PortConflict{},
+	}
+	if m.Bindings == nil {
+		m.Bindings = []PortBinding{}
+	}
+
+	for _, inst := range im.List() {
+		cfg, err := im.readServerConfig(inst.ID)
+		if err != nil {
+			continue
+		}
+		for _, c := range im.CheckPorts(inst.ID, cfg, false) {
+			// Each clash between two instances is found from both sides, keep one
+			if c.With == "" || c.InstanceID < c.With {
+				m.Conflicts = append(m.Conflicts, c)
+			}
+		}
+	}
+
+	m.Suggestions = im.SuggestPorts(PortBlock{defaultBindPort, defaultA2SPort, defaultRconPort}, 3)
+	return m
+}
+
+// SuggestPorts returns up to count free port blocks at increasing offsets from base
+func (im *InstanceManager) SuggestPorts(base PortBlock, count int) []PortBlock {
+	used := make(map[int]bool)
+	for _, b := range im.portBindings("") {
+		used[b.Port] = true
+	}
+
+	blocks := []PortBlock{}
+	for offset := 0; offset <= maxPortOffset && len(blocks) < count; offset++ {
+		p := PortBlock{base.BindPort + offset, base.A2SPort + offset, base.RconPort + offset}
+		free := true
+		for _, port := range []int{p.BindPort, p.A2SPort, p.RconPort} {
+			if used[port] || !udpPortFree(port) {
+				free = false
+				break
+			}
+		}
+		if free {
+			blocks = append(blocks, p)
+		}
+	}
+	return blocks
+}
+
+// udpPortFree reports whether nothing on this host is bound to a UDP port
+func udpPortFree(port int) bool {
+	conn, err := net.ListenPacket("udp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
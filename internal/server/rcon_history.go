@@ -0,0 +1,180 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/astral/kg-server-web-gui/internal/logs"
+)
+
+const (
+	// rconHistoryPerUser is how many commands are kept for each panel user
+	rconHistoryPerUser = 500
+	// maxStoredResponse cuts long responses (players, bans) in the history file
+	maxStoredResponse = 8 << 10
+)
+
+// ErrRconForbidden is returned for a command the user's role may not send
+var ErrRconForbidden = errors.New("이 RCON 명령을 실행할 권한이 없습니다")
+
+// rconUserCommands are the RCON commands panel users without the admin role may send;
+// everything else (removeBan, loadBans, #shutdown, RConPassword...) is admin only
+var rconUserCommands = map[string]bool{
+	"players": true,
+	"admins":  true,
+	"bans":    true,
+	"status":  true,
+	"say":     true,
+	"kick":    true,
+	"ban":     true,
+	"addban":  true,
+}
+
+// rconBanCommands go through AddBan instead of straight to the server, so console bans are
+// recorded, expire and reach the global list like bans set in the panel
+var rconBanCommands = map[string]bool{
+	"ban":    true,
+	"addban": true,
+}
+
+// RconCommandRecord is a command a panel user sent to a server over RCON
+type RconCommandRecord struct {
+	User       string    `json:"user"`
+	InstanceID string    `json:"instanceId"`
+	Command    string    `json:"command"`
+	Response   string    `json:"response,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	Time       time.Time `json:"time"`
+}
+
+// CheckRconCommand refuses a command the trigger's role may not send
+func CheckRconCommand(command string, by Trigger) error {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return fmt.Errorf("명령이 비어 있습니다")
+	}
+	name := strings.ToLower(fields[0])
+	if by.Admin || rconUserCommands[name] {
+		return nil
+	}
+	return fmt.Errorf("%w: %s (관리자 전용)", ErrRconForbidden, fields[0])
+}
+
+// RunRconCommand checks the permission for a command, sends it and records it in the user's history
+func (im *InstanceManager) RunRconCommand(id, command string, by Trigger) (string, error) {
+	if im.Get(id) == nil {
+		return "", fmt.Errorf("서버를 찾을 수 없습니다: %s", id)
+	}
+	command = strings.TrimSpace(command)
+	err := CheckRconCommand(command, by)
+	var resp string
+	if err == nil {
+		if fields := strings.Fields(command); rconBanCommands[strings.ToLower(fields[0])] {
+			resp, err = im.rconBan(id, fields, by)
+		} else {
+			resp, err = im.SendRconCommand(id, command)
+		}
+	} else {
+		logs.GlobalLogs.Warn(fmt.Sprintf("[%s] RCON 명령 거부 (%s): %s", id, by.Name, command))
+	}
+
+	rec := RconCommandRecord{User: by.Name, InstanceID: id, Command: command, Response: resp, Time: time.Now()}
+	if len(rec.Response) > maxStoredResponse {
+		rec.Response = rec.Response[:maxStoredResponse]
+	}
+	if err != nil {
+		rec.Error = err.Error()
+	}
+	im.recordRconCommand(rec)
+	return resp, err
+}
+
+// rconBan runs "ban <player#> [minutes] [reason]" or "addBan <GUID|IP> [minutes] [reason]" through AddBan
+func (im *InstanceManager) rconBan(id string, fields []string, by Trigger) (string, error) {
+	if len(fields) < 2 {
+		return "", fmt.Errorf("사용법: %s <대상> [분] [사유]", fields[0])
+	}
+	target := fields[1]
+	if _, err := strconv.Atoi(target); err != nil && strings.EqualFold(fields[0], "ban") {
+		return "", fmt.Errorf("ban은 플레이어 번호가 필요합니다 (GUID/IP는 addBan): %s", target)
+	} else if err == nil && strings.EqualFold(fields[0], "addban") {
+		return "", fmt.Errorf("addBan은 BE GUID 또는 IP 주소가 필요합니다 (플레이어 번호는 ban): %s", target)
+	}
+	req := BanRequest{Target: target}
+	if len(fields) > 2 {
+		minutes, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return "", fmt.Errorf("잘못된 차단 시간입니다: %s", fields[2])
+		}
+		req.Minutes = minutes
+		req.Reason = strings.Join(fields[3:], " ")
+	}
+	record, err := im.AddBan(id, req, by)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("차단했습니다: %s %s (%s)", record.Kind, record.Target, banLength(req.Minutes)), nil
+}
+
+func (im *InstanceManager) rconHistoryPath() string {
+	return filepath.Join(im.dataPath, "rcon_history.json")
+}
+
+// loadRconCommands reads data/rcon_history.json
+func (im *InstanceManager) loadRconCommands() {
+	data, err := os.ReadFile(im.rconHistoryPath())
+	if err != nil {
+		return
+	}
+	var history map[string][]RconCommandRecord
+	if err := json.Unmarshal(data, &history); err != nil {
+		logs.GlobalLogs.Warn(fmt.Sprintf("RCON 명령 기록 로드 실패: %v", err))
+		return
+	}
+	im.cmdHistMu.Lock()
+	im.cmdHistory = history
+	im.cmdHistMu.Unlock()
+}
+
+// recordRconCommand adds a command to the front of its user's history
+func (im *InstanceManager) recordRconCommand(rec RconCommandRecord) {
+	im.cmdHistMu.Lock()
+	defer im.cmdHistMu.Unlock()
+	history := append([]RconCommandRecord{rec}, im.cmdHistory[rec.User]...)
+	if len(history) > rconHistoryPerUser {
+		history = history[:rconHistoryPerUser]
+	}
+	im.cmdHistory[rec.User] = history
+
+	data, err := json.MarshalIndent(im.cmdHistory, "", "  ")
+	if err == nil {
+		os.MkdirAll(im.dataPath, 0755)
+		err = os.WriteFile(im.rconHistoryPath(), data, 0644)
+	}
+	if err != nil {
+		logs.GlobalLogs.Warn(fmt.Sprintf("RCON 명령 기록 저장 실패: %v", err))
+	}
+}
+
+// RconCommandHistory returns a user's commands, newest first, optionally for one instance only
+func (im *InstanceManager) RconCommandHistory(user, instanceID string, limit int) []RconCommandRecord {
+	im.cmdHistMu.Lock()
+	defer im.cmdHistMu.Unlock()
+	list := []RconCommandRecord{}
+	for _, rec := range im.cmdHistory[user] {
+		if instanceID != "" && rec.InstanceID != instanceID {
+			continue
+		}
+		list = append(list, rec)
+		if limit > 0 && len(list) >= limit {
+			break
+		}
+	}
+	return list
+}
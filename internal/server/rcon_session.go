@@ -0,0 +1,174 @@
+package server
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/astral/kg-server-web-gui/internal/battleye"
+	"github.com/astral/kg-server-web-gui/internal/logs"
+	"github.com/astral/kg-server-web-gui/internal/node"
+)
+
+// rconConn is a persistent RCON session: a battleye.Session for a local server, or the session
+// the agent keeps for a server on its node
+type rconConn interface {
+	Exec(cmd string) (string, error)
+	Subscribe(fn func(msg string)) func()
+	Status() battleye.Status
+	Matches(address, password string) bool
+	Close()
+}
+
+var (
+	_ rconConn = (*battleye.Session)(nil)
+	_ rconConn = (*node.RemoteRcon)(nil)
+)
+
+// rconEntry is the persistent RCON session of an instance and the server.json it was opened from
+type rconEntry struct {
+	session   rconConn
+	configMod time.Time
+	node      string // Node the session goes through (empty = local)
+}
+
+// rconSession returns the RCON session of an instance, opening it on first use and
+// replacing it when the credentials in server.json or the node of the instance changed
+func (im *InstanceManager) rconSession(id string) (rconConn, error) {
+	var mod time.Time
+	if fi, err := os.Stat(im.ConfigPath(id)); err == nil {
+		mod = fi.ModTime()
+	}
+	nodeID := ""
+	if inst := im.Get(id); inst != nil {
+		nodeID = inst.Node
+	}
+	im.rconMu.Lock()
+	if e := im.rconSessions[id]; e != nil && !mod.IsZero() && e.configMod.Equal(mod) && e.node == nodeID {
+		im.rconMu.Unlock()
+		return e.session, nil
+	}
+	im.rconMu.Unlock()
+
+	// server.json is new or changed: compare the credentials
+	address, password, err := im.rconEndpoint(id)
+	if err != nil {
+		return nil, err
+	}
+	var remote *node.RemoteProcess
+	if nodeID != "" {
+		// Only the node reaches the server: its agent keeps the session
+		var ok bool
+		if remote, ok = im.GetMonitor(id).(*node.RemoteProcess); !ok {
+			return nil, fmt.Errorf("노드 연결을 찾을 수 없습니다: %s", nodeID)
+		}
+	}
+
+	im.rconMu.Lock()
+	defer im.rconMu.Unlock()
+	if e := im.rconSessions[id]; e != nil {
+		if e.node == nodeID && e.session.Matches(address, password) {
+			e.configMod = mod
+			return e.session, nil
+		}
+		e.session.Close()
+		logs.GlobalLogs.Info(fmt.Sprintf("[%s] RCON 설정이 변경되어 다시 연결합니다: %s", id, address))
+	}
+	var s rconConn
+	if remote != nil {
+		s = remote.OpenRcon(address, password)
+	} else {
+		s = battleye.NewSession(address, password, battleye.Options{Label: id})
+	}
+	im.watchRcon(id, s)
+	im.rconSessions[id] = &rconEntry{session: s, configMod: mod, node: nodeID}
+	return s, nil
+}
+
+// closeRconSession ends the RCON session of an instance, if any
+func (im *InstanceManager) closeRconSession(id string) {
+	im.rconMu.Lock()
+	defer im.rconMu.Unlock()
+	if e := im.rconSessions[id]; e != nil {
+		e.session.Close()
+		delete(im.rconSessions, id)
+	}
+}
+
+// RconStatus returns the state of an instance's RCON session (nil while none is open)
+func (im *InstanceManager) RconStatus(id string) *battleye.Status {
+	im.rconMu.Lock()
+	defer im.rconMu.Unlock()
+	e := im.rconSessions[id]
+	if e == nil {
+		return nil
+	}
+	st := e.session.Status()
+	return &st
+}
+
+// rconEventHistory is how many decoded server messages are kept per instance
+const rconEventHistory = 200
+
+// RconEvent is a decoded BattlEye server message of an instance
+type RconEvent struct {
+	InstanceID string `json:"instanceId"`
+	battleye.Event
+}
+
+// OpenRcon makes sure an instance has an RCON session, so its server messages stream in
+// (through the agent for a server on a node)
+func (im *InstanceManager) OpenRcon(id string) error {
+	_, err := im.rconSession(id)
+	return err
+}
+
+// SubscribeRcon registers fn for the server messages of every instance; call the returned func to unsubscribe.
+// fn runs on the session's reader: anything sending RCON commands must run in its own goroutine.
+func (im *InstanceManager) SubscribeRcon(fn func(RconEvent)) func() {
+	im.rconMu.Lock()
+	defer im.rconMu.Unlock()
+	sub := im.rconNextSub
+	im.rconNextSub++
+	im.rconSubs[sub] = fn
+	return func() {
+		im.rconMu.Lock()
+		defer im.rconMu.Unlock()
+		delete(im.rconSubs, sub)
+	}
+}
+
+// RconEvents returns the latest server messages of an instance, oldest first
+func (im *InstanceManager) RconEvents(id string, limit int) []RconEvent {
+	im.rconMu.Lock()
+	defer im.rconMu.Unlock()
+	events := im.rconHistory[id]
+	if limit > 0 && len(events) > limit {
+		events = events[len(events)-limit:]
+	}
+	return append([]RconEvent{}, events...)
+}
+
+// watchRcon decodes the server messages of a new session and hands them to the subscribers
+func (im *InstanceManager) watchRcon(id string, s rconConn) {
+	decoder := battleye.NewDecoder()
+	s.Subscribe(func(msg string) {
+		ev := RconEvent{InstanceID: id, Event: decoder.Decode(msg)}
+
+		im.rconMu.Lock()
+		history := append(im.rconHistory[id], ev)
+		if len(history) > rconEventHistory {
+			history = history[len(history)-rconEventHistory:]
+		}
+		im.rconHistory[id] = history
+		subs := make([]func(RconEvent), 0, len(im.rconSubs))
+		for _, fn := range im.rconSubs {
+			subs = append(subs, fn)
+		}
+		im.rconMu.Unlock()
+
+		for _, fn := range subs {
+			fn(ev)
+		}
+	})
+}
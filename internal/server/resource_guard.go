@@ -0,0 +1,176 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/astral/kg-server-web-gui/internal/agent"
+	"github.com/astral/kg-server-web-gui/internal/logs"
+)
+
+// guardInterval is how often running instances are compared against their resource guard
+const guardInterval = 10 * time.Second
+
+// ResourceGuard restarts a server whose memory or CPU use stays above a threshold (e.g. a slow leak)
+type ResourceGuard struct {
+	MaxMemoryMB  float64 `json:"maxMemoryMb"`  // RSS threshold in MB (0 = off)
+	MaxCPU       float64 `json:"maxCpu"`       // CPU threshold in percent of one core (0 = off)
+	Sustain      int     `json:"sustain"`      // Seconds a threshold must be exceeded before acting (default 600)
+	GracePeriod  int     `json:"gracePeriod"`  // Seconds to wait for the server to empty before restarting anyway (default 1800)
+	WarnInterval int     `json:"warnInterval"` // Seconds between RCON warnings while waiting (default 300)
+	Message      string  `json:"message"`      // Warning text, {reason} and {time} are replaced
+}
+
+// withDefaults fills unset fields
+func (g ResourceGuard) withDefaults() ResourceGuard {
+	if g.Sustain <= 0 {
+		g.Sustain = 600
+	}
+	if g.GracePeriod <= 0 {
+		g.GracePeriod = 1800
+	}
+	if g.WarnInterval <= 0 {
+		g.WarnInterval = 300
+	}
+	if g.Message == "" {
+		g.Message = "⚠️ 서버 점검을 위해 {time} 안에 재시작됩니다. {reason}"
+	}
+	return g
+}
+
+// Validate checks the thresholds
+func (g *ResourceGuard) Validate() error {
+	if g.MaxMemoryMB < 0 || g.MaxCPU < 0 || g.Sustain < 0 || g.GracePeriod < 0 || g.WarnInterval < 0 {
+		return fmt.Errorf("리소스 감시 값은 음수일 수 없습니다")
+	}
+	return nil
+}
+
+// guardState tracks one instance's time over its thresholds and a pending restart
+type guardState struct {
+	overSince  time.Time // First sample above a threshold (zero = within limits)
+	reason     string
+	deadline   time.Time // Restart regardless of players after this (zero = not triggered)
+	lastWarn   time.Time
+	restarting bool
+}
+
+// runResourceGuard evaluates the guards of all running instances until the manager is dropped
+func (im *InstanceManager) runResourceGuard() {
+	ticker := time.NewTicker(guardInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		for _, inst := range im.List() {
+			im.checkResourceGuard(inst.ID)
+		}
+	}
+}
+
+// checkResourceGuard compares the latest resource sample of an instance against its guard
+func (im *InstanceManager) checkResourceGuard(id string) {
+	im.mu.RLock()
+	inst, ok := im.instances[id]
+	var guard *ResourceGuard
+	if ok {
+		guard = inst.ResourceGuard
+	}
+	monitor := im.monitors[id]
+	im.mu.RUnlock()
+
+	if !ok || monitor == nil || guard == nil || (guard.MaxMemoryMB <= 0 && guard.MaxCPU <= 0) || im.currentStatus(inst) != StatusRunning || im.Maintenance(id) != nil {
+		im.guardMu.Lock()
+		delete(im.guards, id)
+		im.guardMu.Unlock()
+		return
+	}
+	g := guard.withDefaults()
+
+	history := monitor.GetResourceHistory()
+	if len(history) == 0 {
+		return
+	}
+	sample := history[len(history)-1]
+
+	var over []string
+	if g.MaxMemoryMB > 0 && sample.MemoryMB > g.MaxMemoryMB {
+		over = append(over, fmt.Sprintf("메모리 %.0fMB > %.0fMB", sample.MemoryMB, g.MaxMemoryMB))
+	}
+	if g.MaxCPU > 0 && sample.CPU > g.MaxCPU {
+		over = append(over, fmt.Sprintf("CPU %.0f%% > %.0f%%", sample.CPU, g.MaxCPU))
+	}
+
+	now := time.Now()
+	im.guardMu.Lock()
+	st, ok := im.guards[id]
+	if !ok {
+		st = &guardState{}
+		im.guards[id] = st
+	}
+	if st.restarting {
+		im.guardMu.Unlock()
+		return
+	}
+
+	triggered := false
+	if st.deadline.IsZero() {
+		if len(over) == 0 {
+			st.overSince = time.Time{}
+			im.guardMu.Unlock()
+			return
+		}
+		if st.overSince.IsZero() {
+			st.overSince = now
+		}
+		if now.Sub(st.overSince) < time.Duration(g.Sustain)*time.Second {
+			im.guardMu.Unlock()
+			return
+		}
+		// Sustained: from now on wait for the server to empty, at most the grace period
+		st.reason = fmt.Sprintf("%s (%s 이상 지속)", strings.Join(over, ", "), formatCountdown(g.Sustain))
+		st.deadline = now.Add(time.Duration(g.GracePeriod) * time.Second)
+		triggered = true
+	}
+	reason, deadline, lastWarn := st.reason, st.deadline, st.lastWarn
+	im.guardMu.Unlock()
+
+	if triggered {
+		im.RecordEvent(id, EventResourceGuard, reason, Trigger{Source: SourceGuard})
+		logs.GlobalLogs.Warn(fmt.Sprintf("[%s] 리소스 임계치 초과: %s", inst.Name, reason))
+		if im.discord != nil {
+			im.discord.SendMessage("📈 Resource Guard", fmt.Sprintf("Server **%s** exceeded its resource limits: %s\nRestarting when empty or at %s.", inst.Name, reason, deadline.Format("15:04")), agent.ColorYellow)
+		}
+	}
+
+	players, err := im.GetPlayers(id)
+	empty := err == nil && len(players) == 0
+	if !empty && now.Before(deadline) {
+		if now.Sub(lastWarn) >= time.Duration(g.WarnInterval)*time.Second {
+			msg := strings.NewReplacer("{time}", formatCountdown(int(time.Until(deadline).Seconds())), "{reason}", "(리소스 사용량 초과)").Replace(g.Message)
+			im.SendRconCommand(id, fmt.Sprintf("#say %s", strings.TrimSpace(msg)))
+			im.guardMu.Lock()
+			st.lastWarn = now
+			im.guardMu.Unlock()
+		}
+		return
+	}
+
+	im.guardMu.Lock()
+	st.restarting = true
+	im.guardMu.Unlock()
+
+	opts := ShutdownOptions{
+		Reason:    "리소스 사용량 초과로 재시작",
+		Countdown: !empty, // Players still on after the grace period get the usual final countdown
+		Restart:   true,
+		Trigger:   Trigger{Source: SourceGuard},
+	}
+	go func() {
+		if err := im.Restart(id, opts); err != nil {
+			logs.GlobalLogs.Error(fmt.Sprintf("[%s] 리소스 감시 재시작 실패: %v", inst.Name, err))
+		}
+		im.guardMu.Lock()
+		delete(im.guards, id)
+		im.guardMu.Unlock()
+	}()
+}
@@ -0,0 +1,80 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/astral/kg-server-web-gui/internal/agent"
+	"github.com/astral/kg-server-web-gui/internal/logs"
+)
+
+// RestartStatus returns the watchdog restart policy state of an instance.
+// Instances the watchdog has not seen yet report their configured policy only.
+func (im *InstanceManager) RestartStatus(id string) (*agent.RestartStatus, error) {
+	im.mu.RLock()
+	inst, ok := im.instances[id]
+	var policy *agent.RestartPolicy
+	if ok {
+		policy = inst.Restart
+	}
+	im.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("서버를 찾을 수 없습니다: %s", id)
+	}
+
+	if im.watchdog != nil {
+		if status := im.watchdog.GetRestartStatus(id); status != nil {
+			return status, nil
+		}
+	}
+	p := policy.WithDefaults()
+	return &agent.RestartStatus{InstanceID: id, Policy: p, MaxAttempts: p.MaxAttempts}, nil
+}
+
+// SetRestartPolicy replaces the restart policy of an instance (nil restores the defaults)
+func (im *InstanceManager) SetRestartPolicy(id string, policy *agent.RestartPolicy) error {
+	if policy != nil {
+		if err := policy.Validate(); err != nil {
+			return err
+		}
+	}
+
+	im.mu.Lock()
+	defer im.mu.Unlock()
+	inst, ok := im.instances[id]
+	if !ok {
+		return fmt.Errorf("서버를 찾을 수 없습니다: %s", id)
+	}
+	inst.Restart = policy
+	if im.watchdog != nil {
+		im.watchdog.SetRestartPolicy(id, policy)
+	}
+	return im.saveLocked()
+}
+
+// ResetRestarts clears the watchdog's attempts of an instance so it is restarted again after the next crash
+func (im *InstanceManager) ResetRestarts(id string, by Trigger) error {
+	if im.Get(id) == nil {
+		return fmt.Errorf("서버를 찾을 수 없습니다: %s", id)
+	}
+	if im.watchdog != nil {
+		im.watchdog.ResetRestarts(id)
+	}
+	im.RecordEvent(id, EventRestartsReset, "", by)
+	return nil
+}
+
+// startSafeConfig is the watchdog's safe_config final action: start the instance with a fallback server.json
+func (im *InstanceManager) startSafeConfig(id, configPath string) error {
+	if err := im.maintenanceHold(id); err != nil {
+		return err
+	}
+	im.RecordEvent(id, EventRestarted, "안전 설정으로 재시작: "+configPath, Trigger{Source: SourceWatchdog})
+	logs.GlobalLogs.Warn(fmt.Sprintf("[%s] 재시작 한도 초과, 안전 설정으로 시작합니다: %s", id, configPath))
+	return im.startInstance(id, []string{"-config", configPath}, Trigger{Source: SourceWatchdog}, false)
+}
+
+// handleGiveUp records that the watchdog stopped restarting an instance
+func (im *InstanceManager) handleGiveUp(id, reason string) {
+	im.RecordEvent(id, EventRestartGaveUp, reason, Trigger{Source: SourceWatchdog})
+	logs.GlobalLogs.Error(fmt.Sprintf("[%s] 워치독 재시작 한도 초과: %s", id, reason))
+}
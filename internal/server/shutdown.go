@@ -0,0 +1,419 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/astral/kg-server-web-gui/internal/agent"
+	"github.com/astral/kg-server-web-gui/internal/logs"
+)
+
+// ShutdownPolicy configures how an instance is brought down
+type ShutdownPolicy struct {
+	Warnings         []int  `json:"warnings"`         // Seconds before shutdown at which players are warned (e.g. 600, 300, 60)
+	Message          string `json:"message"`          // Warning text, {time} and {reason} are replaced
+	SaveCommand      string `json:"saveCommand"`      // Optional RCON command that triggers a persistence save
+	SaveWait         int    `json:"saveWait"`         // Seconds to wait for the save before shutting down (0 = skip)
+	Command          string `json:"command"`          // Clean shutdown command sent over RCON
+	GracefulTimeout  int    `json:"gracefulTimeout"`  // Seconds to wait for the server to exit after the command
+	TerminateTimeout int    `json:"terminateTimeout"` // Seconds to wait after terminate before killing
+}
+
+// DefaultShutdownPolicy returns the policy used when an instance has none configured
+func DefaultShutdownPolicy() ShutdownPolicy {
+	return ShutdownPolicy{
+		Warnings:         []int{600, 300, 60},
+		Message:          "⚠️ 서버가 {time} 후 종료됩니다. {reason}",
+		Command:          "#shutdown",
+		GracefulTimeout:  30,
+		TerminateTimeout: 10,
+	}
+}
+
+// withDefaults fills unset fields from the default policy
+func (p *ShutdownPolicy) withDefaults() ShutdownPolicy {
+	def := DefaultShutdownPolicy()
+	if p == nil {
+		return def
+	}
+	policy := *p
+	if policy.Warnings == nil {
+		policy.Warnings = def.Warnings
+	}
+	if policy.Message == "" {
+		policy.Message = def.Message
+	}
+	if policy.Command == "" {
+		policy.Command = def.Command
+	}
+	if policy.GracefulTimeout <= 0 {
+		policy.GracefulTimeout = def.GracefulTimeout
+	}
+	if policy.TerminateTimeout <= 0 {
+		policy.TerminateTimeout = def.TerminateTimeout
+	}
+	return policy
+}
+
+// ShutdownOptions describes a single shutdown request
+type ShutdownOptions struct {
+	Reason    string  `json:"reason"`    // Shown to players and in logs
+	Countdown bool    `json:"countdown"` // Broadcast the policy's warnings before shutting down
+	Restart   bool    `json:"restart"`   // Start the server again once it is down
+	Trigger   Trigger `json:"-"`         // Who asked for the shutdown (set by the caller, not the request body)
+}
+
+// Shutdown phases
+const (
+	PhaseCountdown = "countdown"
+	PhaseSaving    = "saving"
+	PhaseShutdown  = "shutdown"
+	PhaseTerminate = "terminate"
+	PhaseKill      = "kill"
+	PhaseStarting  = "starting"
+	PhaseDone      = "done"
+	PhaseCancelled = "cancelled"
+	PhaseFailed    = "failed"
+)
+
+// ErrShutdownCancelled is returned when a shutdown is cancelled during its countdown
+var ErrShutdownCancelled = errors.New("shutdown cancelled")
+
+// ShutdownStatus reports the progress of a shutdown sequence
+type ShutdownStatus struct {
+	InstanceID string     `json:"instanceId"`
+	Reason     string     `json:"reason"`
+	Restart    bool       `json:"restart"`
+	Phase      string     `json:"phase"`
+	StartedAt  time.Time  `json:"startedAt"`
+	ShutdownAt time.Time  `json:"shutdownAt"` // When the shutdown command is (or was) sent
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+type shutdownTask struct {
+	status ShutdownStatus
+	cancel context.CancelFunc
+	done   chan struct{}
+	err    error
+}
+
+// Cancellable reports whether the sequence can still be aborted (nothing has been sent to the engine yet)
+func (s *ShutdownStatus) Cancellable() bool {
+	return s.Phase == PhaseCountdown || s.Phase == PhaseSaving
+}
+
+// GracefulStop runs the shutdown sequence of an instance and waits for it to finish
+func (im *InstanceManager) GracefulStop(id string, opts ShutdownOptions) error {
+	task, err := im.beginShutdown(id, opts)
+	if err != nil {
+		return err
+	}
+	<-task.done
+	return task.err
+}
+
+// Restart gracefully stops an instance and starts it again
+func (im *InstanceManager) Restart(id string, opts ShutdownOptions) error {
+	opts.Restart = true
+	return im.GracefulStop(id, opts)
+}
+
+// BeginShutdown starts the shutdown sequence in the background and returns its initial status
+func (im *InstanceManager) BeginShutdown(id string, opts ShutdownOptions) (*ShutdownStatus, error) {
+	if _, err := im.beginShutdown(id, opts); err != nil {
+		return nil, err
+	}
+	return im.GetShutdownStatus(id), nil
+}
+
+// GetShutdownStatus returns the current or last shutdown of an instance, nil if there was none
+func (im *InstanceManager) GetShutdownStatus(id string) *ShutdownStatus {
+	im.shutdownMu.Lock()
+	defer im.shutdownMu.Unlock()
+
+	task, ok := im.shutdowns[id]
+	if !ok {
+		return nil
+	}
+	status := task.status
+	return &status
+}
+
+// CancelShutdown aborts a shutdown that is still in its countdown
+func (im *InstanceManager) CancelShutdown(id string) error {
+	im.shutdownMu.Lock()
+	task, ok := im.shutdowns[id]
+	if !ok || !task.running() {
+		im.shutdownMu.Unlock()
+		return fmt.Errorf("진행 중인 종료 작업이 없습니다: %s", id)
+	}
+	if !task.status.Cancellable() {
+		im.shutdownMu.Unlock()
+		return fmt.Errorf("이미 종료 명령이 전송되어 취소할 수 없습니다")
+	}
+	task.cancel() // Under the lock, so the sequence cannot slip past its countdown meanwhile
+	im.shutdownMu.Unlock()
+
+	<-task.done
+	return nil
+}
+
+func (t *shutdownTask) running() bool {
+	select {
+	case <-t.done:
+		return false
+	default:
+		return true
+	}
+}
+
+func (im *InstanceManager) beginShutdown(id string, opts ShutdownOptions) (*shutdownTask, error) {
+	im.mu.RLock()
+	inst, exists := im.instances[id]
+	monitor := im.monitors[id]
+	var policy ShutdownPolicy
+	if exists {
+		policy = inst.Shutdown.withDefaults()
+	}
+	im.mu.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("서버를 찾을 수 없습니다: %s", id)
+	}
+	if monitor == nil {
+		return nil, fmt.Errorf("서버 모니터를 찾을 수 없습니다: %s", id)
+	}
+	if err := im.CheckMaintenance(id, opts.Trigger); err != nil {
+		return nil, err
+	}
+
+	im.shutdownMu.Lock()
+	defer im.shutdownMu.Unlock()
+	if prev, ok := im.shutdowns[id]; ok && prev.running() {
+		return nil, fmt.Errorf("이미 종료 작업이 진행 중입니다 (%s)", prev.status.Phase)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	now := time.Now()
+	task := &shutdownTask{
+		status: ShutdownStatus{
+			InstanceID: id,
+			Reason:     opts.Reason,
+			Restart:    opts.Restart,
+			Phase:      PhaseShutdown,
+			StartedAt:  now,
+			ShutdownAt: now,
+		},
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	if opts.Countdown && len(policy.Warnings) > 0 {
+		task.status.Phase = PhaseCountdown
+		task.status.ShutdownAt = now.Add(time.Duration(slices.Max(policy.Warnings)) * time.Second)
+	}
+	im.shutdowns[id] = task
+
+	eventType := EventStopRequested
+	if opts.Restart {
+		eventType = EventRestartRequested
+	}
+	im.beginOperation(inst, eventType, opts.Reason, opts.Trigger)
+
+	go func() {
+		defer cancel()
+		task.err = im.runShutdown(ctx, task, inst, monitor, policy, opts)
+
+		im.shutdownMu.Lock()
+		finished := time.Now()
+		task.status.FinishedAt = &finished
+		switch {
+		case errors.Is(task.err, ErrShutdownCancelled):
+			task.status.Phase = PhaseCancelled
+		case task.err != nil:
+			task.status.Phase = PhaseFailed
+			task.status.Error = task.err.Error()
+		default:
+			task.status.Phase = PhaseDone
+		}
+		im.shutdownMu.Unlock()
+		close(task.done)
+	}()
+
+	return task, nil
+}
+
+func (im *InstanceManager) setShutdownPhase(task *shutdownTask, phase string) {
+	im.shutdownMu.Lock()
+	task.status.Phase = phase
+	im.shutdownMu.Unlock()
+}
+
+// enterShutdownPhase leaves the cancellable part of the sequence unless it was cancelled first
+func (im *InstanceManager) enterShutdownPhase(ctx context.Context, task *shutdownTask) error {
+	im.shutdownMu.Lock()
+	defer im.shutdownMu.Unlock()
+	if ctx.Err() != nil {
+		return ErrShutdownCancelled
+	}
+	task.status.Phase = PhaseShutdown
+	task.status.ShutdownAt = time.Now()
+	return nil
+}
+
+// runShutdown warns players, lets the server save and shut itself down, then escalates to terminate and kill
+func (im *InstanceManager) runShutdown(ctx context.Context, task *shutdownTask, inst *ServerInstance, monitor agent.ProcessController, policy ShutdownPolicy, opts ShutdownOptions) error {
+	id := inst.ID
+	reason := opts.Reason
+	if reason == "" {
+		reason = "수동 중지"
+	}
+
+	if running, _, _ := monitor.IsRunning(); !running {
+		if im.currentStatus(inst) != StatusStopped {
+			im.setStatus(inst, StatusStopped, EventStopped, reason)
+		}
+		if opts.Restart {
+			im.setShutdownPhase(task, PhaseStarting)
+			return im.StartBy(id, nil, opts.Trigger)
+		}
+		return nil
+	}
+
+	logs.GlobalLogs.Info(fmt.Sprintf("[%s] 서버 종료 시작 (%s)", inst.Name, reason))
+
+	// Keep the watchdog from restarting the server while it goes down
+	watched := im.watchdog != nil && im.watchdog.IsActive(id)
+	if im.watchdog != nil {
+		im.watchdog.PauseMonitoring(id)
+	}
+
+	err := im.shutdownCountdown(ctx, task, inst, policy, reason)
+	if err == nil {
+		err = im.enterShutdownPhase(ctx, task)
+	}
+	if err != nil {
+		if watched {
+			im.watchdog.ResumeMonitoring(id)
+		}
+		logs.GlobalLogs.Info(fmt.Sprintf("[%s] 서버 종료가 취소되었습니다", inst.Name))
+		im.SendRconCommand(id, "#say 서버 종료가 취소되었습니다")
+		return err
+	}
+
+	// From here on the engine is told to go down; the sequence is no longer cancellable
+	im.cancelReadiness(id)
+	im.setStatus(inst, StatusStopping, EventStopping, reason)
+
+	var exited bool
+	if _, err := im.SendRconCommand(id, policy.Command); err != nil {
+		// The command may still have arrived if the server closed RCON before answering
+		logs.GlobalLogs.Warn(fmt.Sprintf("[%s] 종료 명령 전송 실패: %v", inst.Name, err))
+		exited = monitor.WaitExit(5 * time.Second)
+	} else {
+		exited = monitor.WaitExit(time.Duration(policy.GracefulTimeout) * time.Second)
+	}
+
+	if !exited {
+		im.setShutdownPhase(task, PhaseTerminate)
+		if err := monitor.Terminate(); err == nil {
+			exited = monitor.WaitExit(time.Duration(policy.TerminateTimeout) * time.Second)
+		}
+	}
+
+	if !exited {
+		im.setShutdownPhase(task, PhaseKill)
+		logs.GlobalLogs.Warn(fmt.Sprintf("[%s] 서버가 응답하지 않아 강제 종료합니다", inst.Name))
+		if err := monitor.Kill(); err != nil {
+			im.setStatus(inst, StatusCrashed, EventCrashed, fmt.Sprintf("강제 종료 실패: %v", err))
+			return fmt.Errorf("강제 종료 실패: %w", err)
+		}
+	}
+
+	im.setStatus(inst, StatusStopped, EventStopped, reason)
+	logs.GlobalLogs.Info(fmt.Sprintf("[%s] 서버가 중지되었습니다", inst.Name))
+
+	if opts.Restart {
+		im.setShutdownPhase(task, PhaseStarting)
+		return im.StartBy(id, nil, opts.Trigger)
+	}
+
+	if im.discord != nil {
+		im.discord.SendMessage("🛑 Server Stopped", fmt.Sprintf("Server **%s** has been stopped (%s).", inst.Name, reason), agent.ColorYellow)
+	}
+	return nil
+}
+
+// shutdownCountdown broadcasts the warnings and waits for the save, returning ErrShutdownCancelled when aborted
+func (im *InstanceManager) shutdownCountdown(ctx context.Context, task *shutdownTask, inst *ServerInstance, policy ShutdownPolicy, reason string) error {
+	im.shutdownMu.Lock()
+	shutdownAt := task.status.ShutdownAt
+	counting := task.status.Phase == PhaseCountdown
+	im.shutdownMu.Unlock()
+
+	if counting {
+		warnings := append([]int{}, policy.Warnings...)
+		sort.Sort(sort.Reverse(sort.IntSlice(warnings)))
+
+		for _, w := range warnings {
+			if err := sleepUntil(ctx, shutdownAt.Add(-time.Duration(w)*time.Second)); err != nil {
+				return err
+			}
+			msg := strings.NewReplacer("{time}", formatCountdown(w), "{reason}", reason).Replace(policy.Message)
+			if _, err := im.SendRconCommand(inst.ID, fmt.Sprintf("#say %s", strings.TrimSpace(msg))); err != nil {
+				logs.GlobalLogs.Warn(fmt.Sprintf("[%s] 종료 경고 전송 실패: %v", inst.Name, err))
+			}
+		}
+		if err := sleepUntil(ctx, shutdownAt); err != nil {
+			return err
+		}
+	}
+
+	if policy.SaveCommand != "" || policy.SaveWait > 0 {
+		im.setShutdownPhase(task, PhaseSaving)
+		if policy.SaveCommand != "" {
+			if _, err := im.SendRconCommand(inst.ID, policy.SaveCommand); err != nil {
+				logs.GlobalLogs.Warn(fmt.Sprintf("[%s] 저장 명령 전송 실패: %v", inst.Name, err))
+			}
+		}
+		if err := sleepUntil(ctx, time.Now().Add(time.Duration(policy.SaveWait)*time.Second)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func sleepUntil(ctx context.Context, t time.Time) error {
+	d := time.Until(t)
+	if d <= 0 {
+		if ctx.Err() != nil {
+			return ErrShutdownCancelled
+		}
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ErrShutdownCancelled
+	case <-timer.C:
+		return nil
+	}
+}
+
+// formatCountdown renders a warning offset for players (e.g. "10분", "30초")
+func formatCountdown(seconds int) string {
+	if seconds >= 60 && seconds%60 == 0 {
+		return fmt.Sprintf("%d분", seconds/60)
+	}
+	if seconds > 60 {
+		return fmt.Sprintf("%d분 %d초", seconds/60, seconds%60)
+	}
+	return fmt.Sprintf("%d초", seconds)
+}
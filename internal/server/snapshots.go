@@ -0,0 +1,252 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/astral/kg-server-web-gui/internal/config"
+	"github.com/astral/kg-server-web-gui/internal/logs"
+)
+
+// LastGoodSnapshot is the server.json an instance last ran healthy with
+type LastGoodSnapshot struct {
+	Time       time.Time         `json:"time"` // When the config was last confirmed healthy
+	ConfigPath string            `json:"configPath"`
+	ScenarioID string            `json:"scenarioId"`
+	Mods       []config.ModEntry `json:"mods"`
+}
+
+func (im *InstanceManager) snapshotDir(id string) string {
+	return filepath.Join(im.InstanceDataDir(id), "lastgood")
+}
+
+// watchHealthy snapshots the config once a running instance stayed up for the policy's healthy time
+func (im *InstanceManager) watchHealthy(inst *ServerInstance, status string) {
+	if status != StatusRunning {
+		im.cancelHealthy(inst.ID)
+		return
+	}
+	im.mu.RLock()
+	after := time.Duration(inst.Restart.WithDefaults().HealthyAfter) * time.Second
+	im.mu.RUnlock()
+	im.scheduleSnapshot(inst.ID, after)
+}
+
+// scheduleSnapshot (re)arms the healthy timer of an instance (no instance lock taken)
+func (im *InstanceManager) scheduleSnapshot(id string, after time.Duration) {
+	im.snapMu.Lock()
+	defer im.snapMu.Unlock()
+	if t, ok := im.healthy[id]; ok {
+		t.Stop()
+	}
+	var timer *time.Timer
+	timer = time.AfterFunc(after, func() {
+		im.snapMu.Lock()
+		current := im.healthy[id] == timer
+		if current {
+			delete(im.healthy, id)
+		}
+		im.snapMu.Unlock()
+		if current {
+			im.snapshotConfig(id)
+		}
+	})
+	im.healthy[id] = timer
+}
+
+func (im *InstanceManager) cancelHealthy(id string) {
+	im.snapMu.Lock()
+	defer im.snapMu.Unlock()
+	if t, ok := im.healthy[id]; ok {
+		t.Stop()
+		delete(im.healthy, id)
+	}
+}
+
+// activeConfigPath returns the server.json the running process of an instance was started with
+func (im *InstanceManager) activeConfigPath(id string) string {
+	if im.isRemote(id) {
+		return im.ConfigPath(id) // Sent to the node on every start
+	}
+	if monitor := im.GetMonitor(id); monitor != nil {
+		if rec := monitor.GetRecord(); rec != nil {
+			if path := argValue(rec.Args, "-config"); path != "" {
+				return path
+			}
+		}
+	}
+	return im.ConfigPath(id)
+}
+
+// snapshotConfig keeps the config of a healthy instance as its last-known-good state
+func (im *InstanceManager) snapshotConfig(id string) {
+	im.mu.RLock()
+	inst, ok := im.instances[id]
+	im.mu.RUnlock()
+	if !ok || im.currentStatus(inst) != StatusRunning {
+		return
+	}
+
+	path := im.activeConfigPath(id)
+	if path == "" || path != im.ConfigPath(id) {
+		return // Running on a fallback config
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		logs.GlobalLogs.Warn(fmt.Sprintf("[%s] 정상 설정 스냅샷 실패: %v", id, err))
+		return
+	}
+	var cfg config.ServerConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return
+	}
+
+	dir := im.snapshotDir(id)
+	os.MkdirAll(dir, 0755)
+	previous, _ := os.ReadFile(filepath.Join(dir, "server.json"))
+	changed := !bytes.Equal(previous, data)
+	if changed {
+		if err := os.WriteFile(filepath.Join(dir, "server.json"), data, 0644); err != nil {
+			logs.GlobalLogs.Warn(fmt.Sprintf("[%s] 정상 설정 스냅샷 실패: %v", id, err))
+			return
+		}
+	}
+
+	snap := LastGoodSnapshot{
+		Time:       time.Now(),
+		ConfigPath: path,
+		ScenarioID: cfg.Game.ScenarioID,
+		Mods:       cfg.Game.Mods,
+	}
+	meta, _ := json.MarshalIndent(snap, "", "  ")
+	os.WriteFile(filepath.Join(dir, "snapshot.json"), meta, 0644)
+
+	if changed {
+		im.RecordEvent(id, EventConfigSnapshot, fmt.Sprintf("시나리오 %s, 모드 %d개", snap.ScenarioID, len(snap.Mods)), Trigger{Source: SourceSystem})
+		logs.GlobalLogs.Info(fmt.Sprintf("[%s] 정상 동작 설정을 저장했습니다", id))
+	}
+}
+
+// LastGood returns the last-known-good snapshot of an instance (nil if none was taken yet)
+func (im *InstanceManager) LastGood(id string) (*LastGoodSnapshot, error) {
+	if im.Get(id) == nil {
+		return nil, fmt.Errorf("서버를 찾을 수 없습니다: %s", id)
+	}
+	data, err := os.ReadFile(filepath.Join(im.snapshotDir(id), "snapshot.json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var snap LastGoodSnapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, err
+	}
+	return &snap, nil
+}
+
+// rollbackConfig is the watchdog's rollback final action: restore the last-known-good server.json.
+// It returns what changed, one line per difference.
+func (im *InstanceManager) rollbackConfig(id string) (string, error) {
+	if err := im.maintenanceHold(id); err != nil {
+		return "", err
+	}
+	snap, err := im.LastGood(id)
+	if err != nil {
+		return "", err
+	}
+	if snap == nil {
+		return "", fmt.Errorf("저장된 마지막 정상 설정이 없습니다")
+	}
+	good, err := os.ReadFile(filepath.Join(im.snapshotDir(id), "server.json"))
+	if err != nil {
+		return "", err
+	}
+	current, err := os.ReadFile(snap.ConfigPath)
+	if err != nil {
+		return "", err
+	}
+	if bytes.Equal(current, good) {
+		return "", fmt.Errorf("마지막 정상 동작 이후 설정이 바뀌지 않았습니다")
+	}
+
+	var from, to config.ServerConfig
+	json.Unmarshal(current, &from)
+	json.Unmarshal(good, &to)
+	summary := describeRollback(&from, &to)
+
+	backupsDir := im.Workspace(id).BackupsDir
+	os.MkdirAll(backupsDir, 0755)
+	backup := filepath.Join(backupsDir, fmt.Sprintf("%s.backup.%d", filepath.Base(snap.ConfigPath), time.Now().Unix()))
+	if err := os.WriteFile(backup, current, 0644); err != nil {
+		return "", fmt.Errorf("현재 설정 백업 실패: %w", err)
+	}
+	if err := os.WriteFile(snap.ConfigPath, good, 0644); err != nil {
+		return "", err
+	}
+
+	summary = append(summary, fmt.Sprintf("%s 스냅샷 적용, 이전 설정은 %s로 백업", snap.Time.Format("2006-01-02 15:04"), filepath.Base(backup)))
+	im.RecordEvent(id, EventRolledBack, "마지막 정상 설정으로 복원: "+strings.Join(summary, "; "), Trigger{Source: SourceWatchdog})
+	logs.GlobalLogs.Warn(fmt.Sprintf("[%s] 마지막 정상 설정으로 복원했습니다: %s", id, strings.Join(summary, "; ")))
+	return "- " + strings.Join(summary, "\n- "), nil
+}
+
+// describeRollback lists the scenario and mod differences between the crashing and the restored config
+func describeRollback(from, to *config.ServerConfig) []string {
+	var lines []string
+	if from.Game.ScenarioID != to.Game.ScenarioID {
+		lines = append(lines, fmt.Sprintf("시나리오 %s → %s", from.Game.ScenarioID, to.Game.ScenarioID))
+	}
+
+	before := make(map[string]config.ModEntry, len(from.Game.Mods))
+	for _, m := range from.Game.Mods {
+		before[m.ModID] = m
+	}
+	after := make(map[string]bool, len(to.Game.Mods))
+	for _, m := range to.Game.Mods {
+		after[m.ModID] = true
+		old, ok := before[m.ModID]
+		switch {
+		case !ok:
+			lines = append(lines, "모드 복원: "+modLabel(m))
+		case old.Version != m.Version:
+			lines = append(lines, fmt.Sprintf("모드 %s 버전 %s → %s", modLabel(m), versionLabel(old.Version), versionLabel(m.Version)))
+		}
+	}
+	for _, m := range from.Game.Mods {
+		if !after[m.ModID] {
+			lines = append(lines, "모드 제거: "+modLabel(m))
+		}
+	}
+
+	// Anything besides the scenario and mods
+	a, b := *from, *to
+	a.Game.ScenarioID, b.Game.ScenarioID = "", ""
+	a.Game.Mods, b.Game.Mods = nil, nil
+	ja, _ := json.Marshal(a)
+	jb, _ := json.Marshal(b)
+	if !bytes.Equal(ja, jb) || len(lines) == 0 {
+		lines = append(lines, "그 밖의 server.json 설정")
+	}
+	return lines
+}
+
+func modLabel(m config.ModEntry) string {
+	if m.Name == "" {
+		return m.ModID
+	}
+	return fmt.Sprintf("%s (%s)", m.Name, m.ModID)
+}
+
+func versionLabel(v string) string {
+	if v == "" {
+		return "최신"
+	}
+	return v
+}
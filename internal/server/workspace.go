@@ -0,0 +1,223 @@
+package server
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/astral/kg-server-web-gui/internal/logs"
+)
+
+// Workspace is the directory an instance owns; every per-instance path is resolved from it
+type Workspace struct {
+	Dir        string `json:"dir"`        // <root>/<id>: PID file, events, snapshots
+	ConfigPath string `json:"configPath"` // server.json (the instance's ConfigPath when set explicitly)
+	ProfileDir string `json:"profileDir"` // -profile of the server
+	SavesDir   string `json:"savesDir"`
+	LogsDir    string `json:"logsDir"`    // Console logs
+	BackupsDir string `json:"backupsDir"` // Config backups
+	// Backups of save files made from the saves page
+	SaveBackupsDir string `json:"saveBackupsDir"`
+}
+
+// defaultWorkspaceRoot is where workspaces live unless the settings name another root
+func (im *InstanceManager) defaultWorkspaceRoot() string {
+	return filepath.Join(im.dataPath, "instances")
+}
+
+// resolveWorkspaceRoot reads the configured root once; changing it takes effect on the next panel start
+func (im *InstanceManager) resolveWorkspaceRoot() string {
+	root := im.defaultWorkspaceRoot()
+	if im.settingsMgr != nil {
+		if r := im.settingsMgr.Get().WorkspaceRoot; r != "" {
+			root = r
+		}
+	}
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return root
+	}
+	return abs
+}
+
+// WorkspaceRoot returns the directory holding all instance workspaces
+func (im *InstanceManager) WorkspaceRoot() string {
+	return im.workspaceRoot
+}
+
+// InstanceDataDir returns the workspace directory of an instance
+func (im *InstanceManager) InstanceDataDir(id string) string {
+	return filepath.Join(im.workspaceRoot, id)
+}
+
+// workspaceFor resolves the workspace of an instance; the caller holds im.mu (or owns inst)
+func (im *InstanceManager) workspaceFor(id, configPath string) Workspace {
+	dir := im.InstanceDataDir(id)
+	ws := Workspace{
+		Dir:        dir,
+		ConfigPath: filepath.Join(dir, "server.json"),
+		ProfileDir: filepath.Join(dir, "profile"),
+		LogsDir:    filepath.Join(dir, "logs"),
+		BackupsDir: filepath.Join(dir, "backups"),
+	}
+	ws.SavesDir = filepath.Join(ws.ProfileDir, "Saves")
+	ws.SaveBackupsDir = filepath.Join(ws.BackupsDir, "saves")
+	if configPath != "" {
+		if abs, err := filepath.Abs(configPath); err == nil {
+			ws.ConfigPath = abs
+		}
+	}
+	return ws
+}
+
+// Workspace returns the paths of an instance
+func (im *InstanceManager) Workspace(id string) Workspace {
+	im.mu.RLock()
+	configPath := ""
+	if inst, ok := im.instances[id]; ok {
+		configPath = inst.ConfigPath
+	}
+	im.mu.RUnlock()
+	return im.workspaceFor(id, configPath)
+}
+
+// ConfigPath returns the server.json an instance is configured with
+func (im *InstanceManager) ConfigPath(id string) string {
+	return im.Workspace(id).ConfigPath
+}
+
+// ensureWorkspace creates the directories of a workspace
+func ensureWorkspace(ws Workspace) error {
+	for _, dir := range []string{ws.Dir, ws.SavesDir, ws.LogsDir, ws.BackupsDir, ws.SaveBackupsDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// archiveWorkspace moves the workspace of a deleted instance to data/archive and returns where it went
+func (im *InstanceManager) archiveWorkspace(id string) (string, error) {
+	dir := im.InstanceDataDir(id)
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return "", nil
+	}
+	archiveDir := filepath.Join(im.dataPath, "archive")
+	if err := os.MkdirAll(archiveDir, 0755); err != nil {
+		return "", err
+	}
+	target := filepath.Join(archiveDir, fmt.Sprintf("%s-%s", id, time.Now().Format("20060102-150405")))
+	if err := os.Rename(dir, target); err != nil {
+		return "", err
+	}
+	return target, nil
+}
+
+// migrateWorkspaces moves the scattered pre-workspace layout into the workspaces:
+// data/instances/<id> under another root, cwd/server.json or an instance's ConfigPath,
+// cwd/profile/<id> (cwd/profile itself for the default instance) and the save backups in cwd/backups.
+// The caller holds im.mu; it returns whether instances changed and must be saved.
+func (im *InstanceManager) migrateWorkspaces() bool {
+	workDir, _ := os.Getwd()
+	legacyProfiles := filepath.Join(workDir, "profile")
+	legacyBackups := filepath.Join(workDir, "backups")
+	changed := false
+	var movedConfigs []string
+
+	for id, inst := range im.instances {
+		ws := im.workspaceFor(id, "")
+
+		// Runtime data of a root that was moved in the settings
+		if legacy := filepath.Join(im.defaultWorkspaceRoot(), id); legacy != ws.Dir && exists(legacy) && !exists(ws.Dir) {
+			if err := os.MkdirAll(filepath.Dir(ws.Dir), 0755); err == nil {
+				im.migrateLog(id, os.Rename(legacy, ws.Dir), legacy, ws.Dir)
+			}
+		}
+		if err := ensureWorkspace(ws); err != nil {
+			logs.GlobalLogs.Error(fmt.Sprintf("[%s] 작업 폴더 생성 실패: %v", id, err))
+			continue
+		}
+
+		// server.json: copied now, originals renamed once every instance had its copy
+		legacyConfig := inst.ConfigPath
+		if legacyConfig == "" && id == "default" {
+			legacyConfig = filepath.Join(workDir, "server.json")
+		}
+		if legacyConfig != "" {
+			if abs, err := filepath.Abs(legacyConfig); err == nil && abs != ws.ConfigPath && exists(abs) && !exists(ws.ConfigPath) {
+				err := copyFile(abs, ws.ConfigPath)
+				im.migrateLog(id, err, abs, ws.ConfigPath)
+				if err == nil {
+					movedConfigs = append(movedConfigs, abs)
+					inst.ConfigPath = ""
+					changed = true
+				}
+			}
+		}
+
+		if id != "default" {
+			legacy := filepath.Join(legacyProfiles, id)
+			im.migrateProfile(id, legacy, ws.ProfileDir, nil)
+			os.Remove(legacy) // Only succeeds once everything moved
+		}
+	}
+
+	// Whatever else is in cwd/profile belonged to the default instance
+	if _, ok := im.instances["default"]; ok {
+		im.migrateProfile("default", legacyProfiles, im.workspaceFor("default", "").ProfileDir, func(name string) bool {
+			_, isInstance := im.instances[name]
+			return isInstance
+		})
+		os.Remove(legacyProfiles) // Only succeeds once everything moved
+		im.migrateProfile("default", legacyBackups, im.workspaceFor("default", "").SaveBackupsDir, nil)
+		os.Remove(legacyBackups)
+	}
+
+	for _, path := range movedConfigs {
+		if exists(path) {
+			os.Rename(path, path+".migrated")
+		}
+	}
+	return changed
+}
+
+// migrateProfile moves the entries of a legacy profile directory into a workspace profile.
+// Entries skip matches and entries the workspace already has are left in place.
+func (im *InstanceManager) migrateProfile(id, from, to string, skip func(name string) bool) {
+	entries, err := os.ReadDir(from)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		if skip != nil && skip(e.Name()) {
+			continue
+		}
+		src, dst := filepath.Join(from, e.Name()), filepath.Join(to, e.Name())
+		if exists(dst) {
+			if !isEmptyDir(dst) {
+				continue
+			}
+			os.Remove(dst) // Created empty by ensureWorkspace
+		}
+		im.migrateLog(id, os.Rename(src, dst), src, dst)
+	}
+}
+
+func (im *InstanceManager) migrateLog(id string, err error, from, to string) {
+	if err != nil {
+		logs.GlobalLogs.Warn(fmt.Sprintf("[%s] 작업 폴더로 옮기지 못했습니다: %s → %s: %v", id, from, to, err))
+		return
+	}
+	logs.GlobalLogs.Info(fmt.Sprintf("[%s] 작업 폴더로 옮김: %s → %s", id, from, to))
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func isEmptyDir(path string) bool {
+	entries, err := os.ReadDir(path)
+	return err == nil && len(entries) == 0
+}
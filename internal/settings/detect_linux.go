@@ -0,0 +1,29 @@
+//go:build linux
+
+package settings
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// detectServerPath looks for a dedicated server install in common SteamCMD/Steam locations
+func detectServerPath() string {
+	home, _ := os.UserHomeDir()
+
+	commonPaths := []string{
+		filepath.Join(home, ".steam", "steam", "steamapps", "common", "Arma Reforger Server"),
+		filepath.Join(home, ".local", "share", "Steam", "steamapps", "common", "Arma Reforger Server"),
+		filepath.Join(home, "Steam", "steamapps", "common", "Arma Reforger Server"),
+		"/home/steam/arma-reforger",
+		"/opt/arma-reforger",
+	}
+
+	for _, p := range commonPaths {
+		if _, err := os.Stat(filepath.Join(p, "ArmaReforgerServer")); err == nil {
+			return p
+		}
+	}
+
+	return ""
+}
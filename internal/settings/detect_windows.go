@@ -0,0 +1,38 @@
+//go:build windows
+
+package settings
+
+import (
+	"os"
+	"path/filepath"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+// detectServerPath looks for a dedicated server install in common Steam locations and the registry
+func detectServerPath() string {
+	// 1. Try common paths
+	commonPaths := []string{
+		`C:\Program Files (x86)\Steam\steamapps\common\Arma Reforger Server`,
+		`C:\Program Files\Steam\steamapps\common\Arma Reforger Server`,
+		`D:\SteamLibrary\steamapps\common\Arma Reforger Server`,
+	}
+
+	for _, p := range commonPaths {
+		if _, err := os.Stat(filepath.Join(p, "ArmaReforgerServer.exe")); err == nil {
+			return p
+		}
+	}
+
+	// 2. Try Registry (InstallLocation)
+	k, err := registry.OpenKey(registry.LOCAL_MACHINE, `SOFTWARE\Microsoft\Windows\CurrentVersion\Uninstall\Steam App 1874900`, registry.QUERY_VALUE)
+	if err == nil {
+		defer k.Close()
+		path, _, err := k.GetStringValue("InstallLocation")
+		if err == nil && path != "" {
+			return path
+		}
+	}
+
+	return ""
+}
@@ -5,16 +5,16 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
-
-	"golang.org/x/sys/windows/registry"
 )
 
 // AppSettings stores global application settings
 type AppSettings struct {
 	// Server paths
-	ServerPath   string `json:"serverPath"`   // Path to ArmaReforgerServer.exe
-	AddonsPath   string `json:"addonsPath"`   // Path to addons directory
-	ProfilesPath string `json:"profilesPath"` // Path to profiles directory
+	ServerPath string `json:"serverPath"` // Directory containing the dedicated server binary
+	AddonsPath string `json:"addonsPath"` // Path to addons directory
+
+	// Per-instance workspaces (config, profile, logs, backups) live in <root>/<id> (empty = data/instances)
+	WorkspaceRoot string `json:"workspaceRoot"`
 
 	// SteamCMD
 	SteamCMDPath string `json:"steamcmdPath"` // Path to steamcmd directory
@@ -33,6 +33,11 @@ type AppSettings struct {
 
 	// RCON Chat Monitor
 	EnableRconMonitor bool `json:"enableRconMonitor"` // Enable in-game chat command monitoring
+
+	// Per-instance console logs (0 = default)
+	ConsoleLogMaxSizeMB  int `json:"consoleLogMaxSizeMb"`  // Rotate console.log past this size
+	ConsoleLogMaxAgeDays int `json:"consoleLogMaxAgeDays"` // Delete rotated logs older than this
+	ConsoleLogMaxFiles   int `json:"consoleLogMaxFiles"`   // Rotated logs kept per instance
 }
 
 // SettingsManager handles saving/loading settings
@@ -62,7 +67,6 @@ func (sm *SettingsManager) Load() error {
 			sm.settings = &AppSettings{
 				ServerPath:        "",
 				AddonsPath:        "",
-				ProfilesPath:      "",
 				SteamCMDPath:      "",
 				DefaultServerName: "default",
 			}
@@ -77,37 +81,10 @@ func (sm *SettingsManager) Load() error {
 
 	// Auto-detect if server path is empty
 	if sm.settings.ServerPath == "" {
-		// 1. Try common paths
-		commonPaths := []string{
-			`C:\Program Files (x86)\Steam\steamapps\common\Arma Reforger Server`,
-			`C:\Program Files\Steam\steamapps\common\Arma Reforger Server`,
-			`D:\SteamLibrary\steamapps\common\Arma Reforger Server`,
-		}
-
-		found := false
-		for _, p := range commonPaths {
-			if _, err := os.Stat(filepath.Join(p, "ArmaReforgerServer.exe")); err == nil {
-				sm.settings.ServerPath = p
-				found = true
-				break
-			}
-		}
-
-		// 2. Try Registry (InstallLocation)
-		if !found {
-			k, err := registry.OpenKey(registry.LOCAL_MACHINE, `SOFTWARE\Microsoft\Windows\CurrentVersion\Uninstall\Steam App 1874900`, registry.QUERY_VALUE)
-			if err == nil {
-				defer k.Close()
-				path, _, err := k.GetStringValue("InstallLocation")
-				if err == nil && path != "" {
-					sm.settings.ServerPath = path
-					found = true
-				}
-			}
-		}
-
-		if found {
-			sm.Save() // Save the detected path
+		// Platform specific detection (common Steam library paths, registry, ...)
+		if path := detectServerPath(); path != "" {
+			sm.settings.ServerPath = path
+			sm.saveLocked() // Save the detected path
 		}
 	}
 
@@ -118,18 +95,8 @@ func (sm *SettingsManager) Load() error {
 			sm.settings.AddonsPath = filepath.Join(sm.settings.ServerPath, "addons")
 			changed = true
 		}
-		if sm.settings.ProfilesPath == "" {
-			// Profile default is usually usually in Documents/My Games/ArmaReforger...
-			// BUT for dedicated server, -profile defaults to the folder where command is run + "profile" usually,
-			// OR we can default it to ServerPath/profile for portability.
-			// Let's stick to CWD logic for profile if not set, or ServerPath/profile.
-			// Given router.go sets it to CWD/profile, we might leave it or set it to ServerPath/profile.
-			// Let's set it to ServerPath/profile to keep it consistent with the server.
-			sm.settings.ProfilesPath = filepath.Join(sm.settings.ServerPath, "profile")
-			changed = true
-		}
 		if changed {
-			sm.Save()
+			sm.saveLocked()
 		}
 	}
 
@@ -139,7 +106,11 @@ func (sm *SettingsManager) Load() error {
 func (sm *SettingsManager) Save() error {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
+	return sm.saveLocked()
+}
 
+// saveLocked saves without acquiring lock - caller must hold lock
+func (sm *SettingsManager) saveLocked() error {
 	data, err := json.MarshalIndent(sm.settings, "", "  ")
 	if err != nil {
 		return err
@@ -12,6 +12,7 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/astral/kg-server-web-gui/internal/agent"
 	"github.com/astral/kg-server-web-gui/internal/logs"
 )
 
@@ -165,7 +166,7 @@ func (m *Manager) IsRunning() bool {
 
 // CheckInstalled checks if server is installed
 func (m *Manager) CheckInstalled() bool {
-	serverExe := filepath.Join(m.installDir, "ArmaReforgerServer.exe")
+	serverExe := filepath.Join(m.installDir, agent.ServerBinaryName)
 	_, err := os.Stat(serverExe)
 	return err == nil
 }